@@ -24,7 +24,9 @@ import (
 	"github.com/openstack-k8s-operators/lib-common/modules/common/service"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/tls"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/util"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
@@ -37,10 +39,17 @@ const (
 
 	// GlanceAPIContainerImage is the fall-back container image for GlanceAPI
 	GlanceAPIContainerImage = "quay.io/podified-antelope-centos9/openstack-glance-api:current-podified"
+	// GlanceHTTPDExporterContainerImage is the fall-back container image for
+	// the GlanceAPI mod_status metrics sidecar
+	GlanceHTTPDExporterContainerImage = "quay.io/prometheuscommunity/apache-exporter:v1.0.10"
 	//DBPurgeDefaultAge indicates the number of days of purging DB records
 	DBPurgeDefaultAge = 30
 	//DBPurgeDefaultSchedule is in crontab format, and the default runs the job once every day
 	DBPurgeDefaultSchedule = "1 0 * * *"
+	// ImageCleanupDefaultSchedule is in crontab format, and the default runs the job once every day
+	ImageCleanupDefaultSchedule = "30 0 * * *"
+	// ConsistencyCheckDefaultSchedule is in crontab format, and the default runs the job once every day
+	ConsistencyCheckDefaultSchedule = "0 2 * * *"
 	//CleanerDefaultSchedule is in crontab format, and the default runs the job once every 30 minutes
 	CleanerDefaultSchedule = "*/30 * * * *"
 	//PrunerDefaultSchedule is in crontab format, and the default runs the job once every day
@@ -72,6 +81,9 @@ type GlanceAPITemplate struct {
 	// CustomServiceConfig - customize the service config using this parameter to change service defaults,
 	// or overwrite rendered information using raw OpenStack config format. The content gets added to
 	// to /etc/<service>/<service>.conf.d directory as custom.conf file.
+	// A backend change rolls out a new StatefulSet revision, except when the images are stored in the
+	// local file store (file backend without External storage): the StatefulSet is then deleted and
+	// recreated, and the API is unavailable until the new Pods are Ready.
 	CustomServiceConfig string `json:"customServiceConfig,omitempty"`
 
 	// +kubebuilder:validation:Optional
@@ -85,6 +97,30 @@ type GlanceAPITemplate struct {
 	// https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
 
+	// +kubebuilder:validation:Optional
+	// ContainerResources - per-container Compute Resources. The httpd and api
+	// containers fall back to Resources when not specified, while the log
+	// sidecar gets a small default
+	ContainerResources *ContainerResources `json:"containerResources,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// Workers - number of glance-api worker processes, used when the API is
+	// not deployed in wsgi mode. When omitted, it's derived from Resources
+	Workers *int32 `json:"workers,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// Processes - number of WSGIDaemonProcess processes. When omitted, it's
+	// derived from Resources
+	Processes *int32 `json:"processes,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// Threads - number of threads of each WSGIDaemonProcess process. When
+	// omitted, it's derived from Resources
+	Threads *int32 `json:"threads,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// NetworkAttachments is a list of NetworkAttachment resource names to expose the services to the given network
 	NetworkAttachments []string `json:"networkAttachments,omitempty"`
@@ -115,10 +151,196 @@ type GlanceAPITemplate struct {
 	// +kubebuilder:validation:Optional
 	ImageCache ImageCache `json:"imageCache,omitempty"`
 
+	// +kubebuilder:validation:Optional
+	// ImageConversion - work dir of the image_conversion import plugin. It is
+	// only mounted when an rbd backend enables the conversion of the imported
+	// images to raw
+	ImageConversion *ImageConversion `json:"imageConversion,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Staging - dedicated volume for the os_glance_staging_store used by the
+	// glance-direct and interoperable image import. When omitted, the staging
+	// area is a subdirectory of the glance PVC of each replica
+	Staging *Staging `json:"staging,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:validation:Minimum=1
 	// APITimeout for HAProxy and Apache defaults to GlanceSpecCore APITimeout
 	APITimeout int `json:"apiTimeout,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ImportFiltering - allow/deny lists applied to the URIs consumed by the
+	// web-download import method. When omitted, the GlanceAPI serving the
+	// public endpoint gets a default deny list of cluster-internal hosts.
+	// Setting it to an empty struct disables any filtering.
+	ImportFiltering *ImportFiltering `json:"importFiltering,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// PropertyProtections - ConfigMap holding the property protection rules
+	// enforced by the GlanceAPI
+	PropertyProtections *PropertyProtections `json:"propertyProtections,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Logging - logging options of the GlanceAPI
+	Logging *LoggingSpec `json:"logging,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Audit - when set, the keystonemiddleware audit filter is added to the
+	// GlanceAPI pipeline and CADF events are emitted for each API request
+	Audit *AuditSpec `json:"audit,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Metrics - when set, sidecars exporting the httpd and request level
+	// metrics on a Prometheus port are added to the GlanceAPI Pods
+	Metrics *MetricsSpec `json:"metrics,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ConsistencyCheck - when set, a CronJob per replica compares the files of
+	// the glance PVC with the image locations recorded in the database. It
+	// only applies to a file backend on per-replica PVCs
+	ConsistencyCheck *ConsistencyCheck `json:"consistencyCheck,omitempty"`
+}
+
+// ConsistencyCheck - parameters of the CronJobs checking the file store
+type ConsistencyCheck struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="0 2 * * *"
+	// Schedule defines the crontab format string to schedule the
+	// ConsistencyCheck cronJobs
+	Schedule string `json:"schedule"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	// Repair - remove the image files that no image location refers to. The
+	// locations whose file is missing are only reported
+	Repair bool `json:"repair"`
+}
+
+// MetricsSpec - GlanceAPI metrics sidecars options
+type MetricsSpec struct {
+	// +kubebuilder:validation:Optional
+	// HTTPDExporterImage - image of the sidecar exporting the httpd mod_status
+	// metrics. Defaults to the RELATED_IMAGE_GLANCE_HTTPD_EXPORTER_IMAGE_URL_DEFAULT
+	// environment variable of the operator
+	HTTPDExporterImage string `json:"httpdExporterImage,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// LogExporterImage - mtail image of the sidecar exporting request rate,
+	// latency histograms and transferred bytes per route, derived from the
+	// httpd access log. When not set, only the mod_status metrics are exposed
+	LogExporterImage string `json:"logExporterImage,omitempty"`
+}
+
+// GetHTTPDExporterImage - returns the image of the mod_status exporter
+func (m *MetricsSpec) GetHTTPDExporterImage() string {
+	if m.HTTPDExporterImage == "" {
+		return GlanceHTTPDExporterContainerImage
+	}
+	return m.HTTPDExporterImage
+}
+
+// AuditSpec - CADF audit middleware options
+type AuditSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=log
+	// +kubebuilder:validation:Enum=log;notifications
+	// Driver - "log" emits the audit events in the GlanceAPI logs, while
+	// "notifications" sends them to the notifications bus
+	Driver string `json:"driver"`
+}
+
+// LoggingSpec - logging options rendered in the [DEFAULT] section
+type LoggingSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	// Debug - enable the debug log level
+	Debug bool `json:"debug"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	// JSON - format the log records with the oslo.log JSON formatter
+	JSON bool `json:"json"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=file
+	// +kubebuilder:validation:Enum=file;stdout
+	// Destination - "file" writes the logs to a file streamed by the
+	// glance-log sidecar container, while "stdout" logs directly to the
+	// container output and the sidecar is not deployed
+	Destination string `json:"destination"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// MaxLogfileCount - number of rotated log files to keep (file only)
+	MaxLogfileCount int32 `json:"maxLogfileCount"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=20
+	// +kubebuilder:validation:Minimum=1
+	// MaxLogfileSizeMB - size of the log file, in MB, that triggers a
+	// rotation (file only)
+	MaxLogfileSizeMB int32 `json:"maxLogfileSizeMB"`
+}
+
+// ImportFiltering - web-download filtering options rendered in the
+// [import_filtering_opts] section. For each of schemes, hosts and ports,
+// an allow list takes precedence over the corresponding deny list
+type ImportFiltering struct {
+	// +kubebuilder:validation:Optional
+	// AllowedSchemes - URI schemes web-download is allowed to fetch from
+	AllowedSchemes []string `json:"allowedSchemes,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// DisallowedSchemes - URI schemes web-download is not allowed to fetch from
+	DisallowedSchemes []string `json:"disallowedSchemes,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// AllowedHosts - hostnames or IP addresses web-download is allowed to
+	// fetch from
+	AllowedHosts []string `json:"allowedHosts,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// DisallowedHosts - hostnames or IP addresses web-download is not allowed
+	// to fetch from
+	DisallowedHosts []string `json:"disallowedHosts,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// AllowedPorts - ports web-download is allowed to fetch from
+	AllowedPorts []int32 `json:"allowedPorts,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// DisallowedPorts - ports web-download is not allowed to fetch from
+	DisallowedPorts []int32 `json:"disallowedPorts,omitempty"`
+}
+
+// ContainerResources - Compute Resources of the GlanceAPI containers
+type ContainerResources struct {
+	// +kubebuilder:validation:Optional
+	// Log - Compute Resources of the log sidecar container
+	Log *corev1.ResourceRequirements `json:"log,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// HTTPD - Compute Resources of the httpd container
+	HTTPD *corev1.ResourceRequirements `json:"httpd,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// API - Compute Resources of the glance-api container (proxypass mode only)
+	API *corev1.ResourceRequirements `json:"api,omitempty"`
+}
+
+// PropertyProtections - reference to the property protection rules file
+type PropertyProtections struct {
+	// +kubebuilder:validation:Required
+	// ConfigMap - name of the ConfigMap holding the rules in the
+	// property-protections.conf key
+	ConfigMap string `json:"configMap"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=roles
+	// +kubebuilder:validation:Enum=roles;policies
+	// RuleFormat - whether the rules are expressed in terms of roles or of
+	// policy names
+	RuleFormat string `json:"ruleFormat"`
 }
 
 // Storage -
@@ -133,6 +355,58 @@ type Storage struct {
 	// +kubebuilder:validation:Optional
 	// External -
 	External bool `json:"external,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// RetentionPolicy - what happens to the glance PVCs when the GlanceAPI is
+	// scaled down or deleted. Image data are retained by default
+	RetentionPolicy *PVCRetentionPolicy `json:"retentionPolicy,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Monitoring - when set, the usage of the image and cache volumes of each
+	// replica is collected from the kubelet and reported in the GlanceAPI
+	// status, and the StorageCapacity condition warns about the volumes
+	// filling up
+	Monitoring *StorageMonitoring `json:"monitoring,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Autogrow - when set, the glance PVC of a replica is expanded when its
+	// usage crosses the threshold, up to the maximum size. It requires a
+	// StorageClass that allows volume expansion
+	Autogrow *StorageAutogrow `json:"autogrow,omitempty"`
+}
+
+// StorageAutogrow - policy applied to expand the glance PVCs
+type StorageAutogrow struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=85
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// ThresholdPercent - a glance PVC used above this percentage is expanded
+	ThresholdPercent int `json:"thresholdPercent"`
+	// +kubebuilder:validation:Required
+	// Increment - size added to the PVC at each expansion
+	Increment string `json:"increment"`
+	// +kubebuilder:validation:Required
+	// MaximumSize - the PVC is never expanded beyond this size
+	MaximumSize string `json:"maximumSize"`
+}
+
+// StorageMonitoring - usage thresholds of the StorageCapacity condition
+type StorageMonitoring struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=80
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// WarningPercent - a volume used above this percentage sets the
+	// StorageCapacity condition to False with a Warning severity
+	WarningPercent int `json:"warningPercent"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=95
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// CriticalPercent - a volume used above this percentage sets the
+	// StorageCapacity condition to False with an Error severity
+	CriticalPercent int `json:"criticalPercent"`
 }
 
 // ImageCache - struct where the exposed imageCache params are defined
@@ -142,12 +416,151 @@ type ImageCache struct {
 	Size string `json:"size"`
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default="*/30 * * * *"
-	// Schedule defines the crontab format string to schedule the Cleaner cronJob
+	// Schedule defines the crontab format string to schedule the Cleaner cronJob.
+	// It is ignored when the VolumeType is not pvc
 	CleanerScheduler string `json:"cleanerScheduler"`
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default="1 0 * * *"
-	//Schedule defines the crontab format string to schedule the Pruner cronJob
+	//Schedule defines the crontab format string to schedule the Pruner cronJob.
+	// It is ignored when the VolumeType is not pvc
 	PrunerScheduler string `json:"prunerScheduler"`
+	// +kubebuilder:validation:Optional
+	// RetentionPolicy - what happens to the glance-cache PVCs when the
+	// GlanceAPI is scaled down or deleted. By default the cache PVCs are
+	// deleted on scale down and retained when the GlanceAPI is deleted
+	RetentionPolicy *PVCRetentionPolicy `json:"retentionPolicy,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=pvc;emptyDir;ephemeral
+	// VolumeType - how the image cache volume is realized. A pvc is shared
+	// with the cleaner and pruner CronJobs, while with emptyDir and ephemeral
+	// the cache lives and dies with the Pod and it is cleaned in-pod
+	VolumeType VolumeType `json:"volumeType,omitempty"`
+	// +kubebuilder:validation:Optional
+	// StorageClass - used by the pvc and ephemeral volume types, defaults to
+	// the Storage StorageClass
+	StorageClass string `json:"storageClass,omitempty"`
+}
+
+// VolumeType - the kind of volume used for a per-replica glance volume
+type VolumeType string
+
+const (
+	// VolumeTypePVC - a per-replica PVC created through the StatefulSet
+	// VolumeClaimTemplates
+	VolumeTypePVC VolumeType = "pvc"
+	// VolumeTypeEmptyDir - an emptyDir limited to the requested size
+	VolumeTypeEmptyDir VolumeType = "emptyDir"
+	// VolumeTypeEphemeral - a generic ephemeral volume, created and deleted
+	// with each Pod
+	VolumeTypeEphemeral VolumeType = "ephemeral"
+)
+
+// IsPVC - returns true if the volume is a per-replica PVC, which is the
+// default
+func (v VolumeType) IsPVC() bool {
+	return v == "" || v == VolumeTypePVC
+}
+
+// ImageConversion - volume used as work dir by the image conversion
+type ImageConversion struct {
+	// +kubebuilder:validation:Required
+	// Size - storage request of the conversion volume, that should fit the
+	// largest converted image
+	Size string `json:"size"`
+	// +kubebuilder:validation:Optional
+	// StorageClass - defaults to the Storage StorageClass
+	StorageClass string `json:"storageClass,omitempty"`
+	// +kubebuilder:validation:Optional
+	// Ephemeral - use a generic ephemeral volume, created and deleted with
+	// each Pod, instead of a per-replica PVC
+	Ephemeral bool `json:"ephemeral,omitempty"`
+}
+
+// GetRetentionPolicy - the image conversion PVCs only hold temporary data,
+// so they are never retained. It can be called on a nil ImageConversion
+func (c *ImageConversion) GetRetentionPolicy() PVCRetentionPolicy {
+	return PVCRetentionPolicy{
+		WhenScaled:  appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+		WhenDeleted: appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+	}
+}
+
+// Staging - staging area of the image import
+type Staging struct {
+	// +kubebuilder:validation:Required
+	// Size - storage request of the staging volume
+	Size string `json:"size"`
+	// +kubebuilder:validation:Optional
+	// StorageClass - defaults to the Storage StorageClass. A shared staging
+	// area requires a StorageClass that supports ReadWriteMany
+	StorageClass string `json:"storageClass,omitempty"`
+	// +kubebuilder:validation:Optional
+	// Shared - mount a single ReadWriteMany PVC in all the replicas instead
+	// of a per-replica PVC: any replica can import an image staged by another
+	// one, so worker_self_reference_url is not required anymore
+	Shared bool `json:"shared,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=pvc;emptyDir;ephemeral
+	// VolumeType - how a per-replica staging area is realized. A shared
+	// staging area is always a PVC
+	VolumeType VolumeType `json:"volumeType,omitempty"`
+}
+
+// GetRetentionPolicy - the staging area only holds the data of in-flight
+// imports, so it is never retained. It can be called on a nil Staging
+func (s *Staging) GetRetentionPolicy() PVCRetentionPolicy {
+	return PVCRetentionPolicy{
+		WhenScaled:  appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+		WhenDeleted: appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+	}
+}
+
+// PVCRetentionPolicy - mirrors the StatefulSet
+// persistentVolumeClaimRetentionPolicy for a given kind of PVC
+type PVCRetentionPolicy struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// WhenScaled - policy applied to the PVCs of the replicas removed by a
+	// scale down
+	WhenScaled appsv1.PersistentVolumeClaimRetentionPolicyType `json:"whenScaled,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// WhenDeleted - policy applied to the PVCs when the GlanceAPI is deleted
+	WhenDeleted appsv1.PersistentVolumeClaimRetentionPolicyType `json:"whenDeleted,omitempty"`
+}
+
+// GetRetentionPolicy - returns the retention policy of the glance PVCs,
+// falling back to Retain for the unset fields
+func (s Storage) GetRetentionPolicy() PVCRetentionPolicy {
+	return s.RetentionPolicy.withDefaults(
+		appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+		appsv1.RetainPersistentVolumeClaimRetentionPolicyType)
+}
+
+// GetRetentionPolicy - returns the retention policy of the glance-cache
+// PVCs: the cache is disposable, so it is deleted on scale down unless
+// requested otherwise
+func (c ImageCache) GetRetentionPolicy() PVCRetentionPolicy {
+	return c.RetentionPolicy.withDefaults(
+		appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+		appsv1.RetainPersistentVolumeClaimRetentionPolicyType)
+}
+
+func (p *PVCRetentionPolicy) withDefaults(
+	whenScaled appsv1.PersistentVolumeClaimRetentionPolicyType,
+	whenDeleted appsv1.PersistentVolumeClaimRetentionPolicyType,
+) PVCRetentionPolicy {
+	policy := PVCRetentionPolicy{WhenScaled: whenScaled, WhenDeleted: whenDeleted}
+	if p == nil {
+		return policy
+	}
+	if p.WhenScaled != "" {
+		policy.WhenScaled = p.WhenScaled
+	}
+	if p.WhenDeleted != "" {
+		policy.WhenDeleted = p.WhenDeleted
+	}
+	return policy
 }
 
 // APIOverrideSpec to override the generated manifest of several child resources.
@@ -167,16 +580,155 @@ type AuthSpec struct {
 	ApplicationCredentialSecret string `json:"applicationCredentialSecret,omitempty"`
 }
 
+// IsEmpty - returns true when no filtering rule is defined
+func (f ImportFiltering) IsEmpty() bool {
+	return len(f.AllowedSchemes) == 0 && len(f.DisallowedSchemes) == 0 &&
+		len(f.AllowedHosts) == 0 && len(f.DisallowedHosts) == 0 &&
+		len(f.AllowedPorts) == 0 && len(f.DisallowedPorts) == 0
+}
+
+// GetDefaultImportFiltering - returns the filtering applied to a GlanceAPI
+// serving the public endpoint when no ImportFiltering is specified: only
+// http(s) on the standard ports is allowed, and well known cluster-internal
+// hosts are denied. Glance matches hosts literally (no CIDR support), hence
+// the list covers loopback, link-local metadata and in-cluster API names
+func GetDefaultImportFiltering() *ImportFiltering {
+	return &ImportFiltering{
+		AllowedSchemes: []string{"http", "https"},
+		AllowedPorts:   []int32{80, 443},
+		DisallowedHosts: []string{
+			"localhost",
+			"127.0.0.1",
+			"::1",
+			"0.0.0.0",
+			"169.254.169.254",
+			"kubernetes",
+			"kubernetes.default",
+			"kubernetes.default.svc",
+			"kubernetes.default.svc.cluster.local",
+		},
+	}
+}
+
+// GetDefaultLogResources - returns the Compute Resources of the log sidecar,
+// which only tails the GlanceAPI log file
+func GetDefaultLogResources() corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("10m"),
+			corev1.ResourceMemory: resource.MustParse("32Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		},
+	}
+}
+
+// GetLogResources - returns the Compute Resources of the log sidecar
+func (instance *GlanceAPITemplate) GetLogResources() corev1.ResourceRequirements {
+	if instance.ContainerResources != nil && instance.ContainerResources.Log != nil {
+		return *instance.ContainerResources.Log
+	}
+	return GetDefaultLogResources()
+}
+
+// GetHTTPDResources - returns the Compute Resources of the httpd container
+func (instance *GlanceAPITemplate) GetHTTPDResources() corev1.ResourceRequirements {
+	if instance.ContainerResources != nil && instance.ContainerResources.HTTPD != nil {
+		return *instance.ContainerResources.HTTPD
+	}
+	return instance.Resources
+}
+
+// GetAPIResources - returns the Compute Resources of the glance-api container
+func (instance *GlanceAPITemplate) GetAPIResources() corev1.ResourceRequirements {
+	if instance.ContainerResources != nil && instance.ContainerResources.API != nil {
+		return *instance.ContainerResources.API
+	}
+	return instance.Resources
+}
+
+// GetLogging - returns the logging options of the GlanceAPI
+func (instance *GlanceAPITemplate) GetLogging() LoggingSpec {
+	if instance.Logging != nil {
+		return *instance.Logging
+	}
+	return LoggingSpec{
+		Destination:      LogDestinationFile,
+		MaxLogfileCount:  1,
+		MaxLogfileSizeMB: 20,
+	}
+}
+
+// LogToFile - returns true when the GlanceAPI logs are written to a file
+// streamed by the glance-log sidecar
+func (instance *GlanceAPITemplate) LogToFile() bool {
+	return instance.GetLogging().Destination != LogDestinationStdout
+}
+
+// ServesPublicEndpoint - returns true when the layout deploys a GlanceAPI
+// serving the public endpoint: the single one, or the external one of a
+// split layout
+func (instance *GlanceAPITemplate) ServesPublicEndpoint() bool {
+	return instance.Type == APISingle || instance.Type == "split"
+}
+
+// IsPublicAPI - returns true if the given apiType serves the public endpoint
+func IsPublicAPI(apiType string) bool {
+	return apiType == APIExternal || apiType == APISingle
+}
+
+// GetImportFiltering - returns the web-download filtering that applies to a
+// GlanceAPI of the given apiType, or nil when no filtering is required
+func (instance *GlanceAPITemplate) GetImportFiltering(apiType string) *ImportFiltering {
+	if instance.ImportFiltering != nil {
+		if instance.ImportFiltering.IsEmpty() {
+			return nil
+		}
+		return instance.ImportFiltering
+	}
+	if IsPublicAPI(apiType) {
+		return GetDefaultImportFiltering()
+	}
+	return nil
+}
+
+// IsWebDownloadEnabled - Given a instance.Spec.CustomServiceConfig object,
+// return false only if enabled_import_methods is overridden in the [DEFAULT]
+// section without the web-download method. The option is only read from
+// [DEFAULT], and the last occurrence wins
+func IsWebDownloadEnabled(customServiceConfig string) bool {
+	enabled := true
+	section := ""
+	for _, line := range strings.Split(customServiceConfig, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if section != "DEFAULT" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tokenLine := strings.SplitN(line, "=", 2)
+		token := strings.ReplaceAll(tokenLine[0], " ", "")
+		if token == "enabled_import_methods" && len(tokenLine) == 2 {
+			enabled = strings.Contains(tokenLine[1], "web-download")
+		}
+	}
+	return enabled
+}
+
 // SetupDefaults - initializes any CRD field defaults based on environment variables (the defaulting mechanism itself is implemented via webhooks)
 func SetupDefaults() {
 	// Acquire environmental defaults and initialize Glance defaults with them
 	glanceDefaults := GlanceDefaults{
-		ContainerImageURL: util.GetEnvVar("RELATED_IMAGE_GLANCE_API_IMAGE_URL_DEFAULT", GlanceAPIContainerImage),
-		DBPurgeAge:        DBPurgeDefaultAge,
-		DBPurgeSchedule:   DBPurgeDefaultSchedule,
-		CleanerSchedule:   CleanerDefaultSchedule,
-		PrunerSchedule:    PrunerDefaultSchedule,
-		APITimeout:        APIDefaultTimeout,
+		ContainerImageURL:     util.GetEnvVar("RELATED_IMAGE_GLANCE_API_IMAGE_URL_DEFAULT", GlanceAPIContainerImage),
+		HTTPDExporterImageURL: util.GetEnvVar("RELATED_IMAGE_GLANCE_HTTPD_EXPORTER_IMAGE_URL_DEFAULT", GlanceHTTPDExporterContainerImage),
+		DBPurgeAge:            DBPurgeDefaultAge,
+		DBPurgeSchedule:       DBPurgeDefaultSchedule,
+		CleanerSchedule:       CleanerDefaultSchedule,
+		PrunerSchedule:        PrunerDefaultSchedule,
+		APITimeout:            APIDefaultTimeout,
 	}
 
 	SetupGlanceDefaults(glanceDefaults)
@@ -186,7 +738,8 @@ func SetupDefaults() {
 func SetupAPIDefaults() {
 	// Acquire environmental defaults and initialize GlanceAPI defaults with them
 	glanceAPIDefaults := GlanceAPIDefaults{
-		ContainerImageURL: util.GetEnvVar("RELATED_IMAGE_GLANCE_API_IMAGE_URL_DEFAULT", GlanceAPIContainerImage),
+		ContainerImageURL:     util.GetEnvVar("RELATED_IMAGE_GLANCE_API_IMAGE_URL_DEFAULT", GlanceAPIContainerImage),
+		HTTPDExporterImageURL: util.GetEnvVar("RELATED_IMAGE_GLANCE_HTTPD_EXPORTER_IMAGE_URL_DEFAULT", GlanceHTTPDExporterContainerImage),
 	}
 
 	SetupGlanceAPIDefaults(glanceAPIDefaults)
@@ -31,6 +31,8 @@ const (
 	CinderReadyMessage = "Cinder resources exist"
 	// CinderReadyErrorMessage
 	CinderReadyErrorMessage = "Cinder resource error %s"
+	// GlanceAPIRecreatedMessage
+	GlanceAPIRecreatedMessage = "StatefulSet %s deleted and recreated to apply a backend change: the API is unavailable until the new Pods are Ready"
 	// GlanceAPIReadyCondition Status=True condition which indicates if the GlanceAPI is configured and operational
 	GlanceAPIReadyCondition condition.Type = "GlanceAPIReady"
 	// CinderCondition
@@ -39,6 +41,14 @@ const (
 	GlanceLayoutUpdateErrorMessage = "The GlanceAPI layout (type) cannot be modified. To proceed, please add a new API with the desired layout and then decommission the previous API"
 	//GlanceWarnSplitDeprecateMsg
 	GlanceWarnSplitDeprecateMsg = "The GlanceAPI split layout is deprecated. It is recommended to remove this parameter and rely on the default single layout"
+	// GlanceStorageShrinkErrorMessage
+	GlanceStorageShrinkErrorMessage = "PVCs can only be expanded: the requested size %s is smaller than the current %s"
+	// GlanceStagingSharedErrorMessage
+	GlanceStagingSharedErrorMessage = "A shared staging area is a ReadWriteMany PVC: volumeType must be pvc"
+	// GlanceAutogrowIncrementErrorMessage
+	GlanceAutogrowIncrementErrorMessage = "increment must be a positive size"
+	// GlanceAutogrowMaximumErrorMessage
+	GlanceAutogrowMaximumErrorMessage = "maximumSize %s is smaller than the storageRequest %s"
 	// KeystoneEndpointErrorMessage
 	KeystoneEndpointErrorMessage = "KeystoneEndpoint is assigned to an invalid GlanceAPI instance"
 	// InvalidBackendErrorMessageGeneric
@@ -47,4 +57,155 @@ const (
 	InvalidBackendErrorMessageSplit = "The GlanceAPI layout type: split cannot be used in combination with File and NFS backend"
 	// InvalidBackendErrorMessageSingle
 	InvalidBackendErrorMessageSingle = "glanceAPI layout type: single can only be used in combination with File and NFS backend"
+	// KeyManagerReadyCondition Status=True condition which indicates if the key manager is configured and available
+	KeyManagerReadyCondition condition.Type = "KeyManagerReady"
+	// KeyManagerReadyInitMessage
+	KeyManagerReadyInitMessage = "KeyManager not started"
+	// KeyManagerReadyMessage
+	KeyManagerReadyMessage = "KeyManager configured"
+	// KeyManagerReadyErrorMessage
+	KeyManagerReadyErrorMessage = "KeyManager error occured %s"
+	// KeyManagerBarbicanWaitingMessage
+	KeyManagerBarbicanWaitingMessage = "Barbican endpoint not found in keystone"
+	// KeyManagerLocalKeyWaitingMessage
+	KeyManagerLocalKeyWaitingMessage = "KeyManager local key Secret %s not found"
+	// KeyManagerLocalKeyErrorMessage
+	KeyManagerLocalKeyErrorMessage = "localKeySecret is required when the local KeyManager backend is used"
+	// KeyManagerSignatureErrorMessage
+	KeyManagerSignatureErrorMessage = "requireSignatureVerification cannot be used with a disabled KeyManager"
+	// PropertyProtectionsWaitingMessage
+	PropertyProtectionsWaitingMessage = "PropertyProtections ConfigMap %s not found"
+	// PropertyProtectionsKeyErrorMessage
+	PropertyProtectionsKeyErrorMessage = "PropertyProtections ConfigMap %s has no %s key"
+	// MetadefsReadyCondition Status=True condition which indicates if the custom metadefs have been loaded
+	MetadefsReadyCondition condition.Type = "MetadefsReady"
+	// MetadefsReadyInitMessage
+	MetadefsReadyInitMessage = "Metadefs not loaded"
+	// MetadefsReadyMessage
+	MetadefsReadyMessage = "Metadefs loaded"
+	// MetadefsReadyRunningMessage
+	MetadefsReadyRunningMessage = "Metadefs job still running"
+	// MetadefsReadyErrorMessage
+	MetadefsReadyErrorMessage = "Metadefs error occured %s"
+	// MetadefsWaitingMessage
+	MetadefsWaitingMessage = "Metadefs ConfigMap %s not found"
+	// StorageResizeReadyCondition Status=True condition which indicates if the
+	// GlanceAPI PVCs match the requested sizes
+	StorageResizeReadyCondition condition.Type = "StorageResizeReady"
+	// StorageResizeReadyInitMessage
+	StorageResizeReadyInitMessage = "PVCs size not checked"
+	// StorageResizeReadyMessage
+	StorageResizeReadyMessage = "PVCs match the requested size"
+	// StorageResizeReadyRunningMessage
+	StorageResizeReadyRunningMessage = "PVCs resize in progress: %s"
+	// StorageResizeReadyErrorMessage
+	StorageResizeReadyErrorMessage = "PVCs resize error occured %s"
+	// StorageResizeNotSupportedMessage
+	StorageResizeNotSupportedMessage = "StorageClass %s does not allow volume expansion"
+	// StorageCapacityCondition Status=True condition which indicates if the
+	// image and cache volumes are used below the monitoring thresholds. It
+	// does not affect the Ready condition
+	StorageCapacityCondition condition.Type = "StorageCapacity"
+	// StorageCapacityMessage
+	StorageCapacityMessage = "Volumes usage below the thresholds"
+	// StorageCapacityLowMessage
+	StorageCapacityLowMessage = "Volumes running out of space: %s"
+	// StorageCapacityErrorMessage
+	StorageCapacityErrorMessage = "Volumes usage not available: %s"
+	// GlanceWarnWebDownloadUnfilteredMsg
+	GlanceWarnWebDownloadUnfilteredMsg = "%s: web-download is enabled without any importFiltering on a layout serving the public endpoint, images can be fetched from any host reachable by the GlanceAPI Pods"
+	// SnapshotsReadyCondition Status=True condition which indicates if the
+	// VolumeSnapshots of the image PVCs are ready to use
+	SnapshotsReadyCondition condition.Type = "SnapshotsReady"
+	// SnapshotsReadyInitMessage
+	SnapshotsReadyInitMessage = "VolumeSnapshots not started"
+	// SnapshotsReadyMessage
+	SnapshotsReadyMessage = "VolumeSnapshots ready to use"
+	// SnapshotsReadyRunningMessage
+	SnapshotsReadyRunningMessage = "VolumeSnapshots not ready: %s"
+	// SnapshotsReadyErrorMessage
+	SnapshotsReadyErrorMessage = "VolumeSnapshots error occured %s"
+	// SnapshotsNotSupportedMessage
+	SnapshotsNotSupportedMessage = "The snapshot.storage.k8s.io API is not available"
+	// DatabaseBackupReadyCondition Status=True condition which indicates if
+	// the Glance database has been dumped
+	DatabaseBackupReadyCondition condition.Type = "DatabaseBackupReady"
+	// DatabaseBackupReadyInitMessage
+	DatabaseBackupReadyInitMessage = "Database backup not started"
+	// DatabaseBackupReadyMessage
+	DatabaseBackupReadyMessage = "Database backup completed"
+	// DatabaseBackupReadyRunningMessage
+	DatabaseBackupReadyRunningMessage = "Database backup job still running"
+	// DatabaseBackupReadyErrorMessage
+	DatabaseBackupReadyErrorMessage = "Database backup error occured %s"
+	// GlanceBackupWaitingMessage
+	GlanceBackupWaitingMessage = "Glance %s not found"
+	// RestorePVCReadyCondition Status=True condition which indicates if the
+	// image PVCs have been recreated from the VolumeSnapshots
+	RestorePVCReadyCondition condition.Type = "RestorePVCReady"
+	// RestorePVCReadyInitMessage
+	RestorePVCReadyInitMessage = "PVCs not restored"
+	// RestorePVCReadyMessage
+	RestorePVCReadyMessage = "PVCs restored"
+	// RestorePVCReadyErrorMessage
+	RestorePVCReadyErrorMessage = "PVCs restore error occured %s"
+	// RestorePVCExistsErrorMessage
+	RestorePVCExistsErrorMessage = "PVC %s already exists and was not restored from %s"
+	// DatabaseRestoreReadyCondition Status=True condition which indicates if
+	// the Glance database dump has been loaded
+	DatabaseRestoreReadyCondition condition.Type = "DatabaseRestoreReady"
+	// DatabaseRestoreReadyInitMessage
+	DatabaseRestoreReadyInitMessage = "Database restore not started"
+	// DatabaseRestoreReadyMessage
+	DatabaseRestoreReadyMessage = "Database restore completed"
+	// DatabaseRestoreReadyRunningMessage
+	DatabaseRestoreReadyRunningMessage = "Database restore job still running"
+	// DatabaseRestoreReadyErrorMessage
+	DatabaseRestoreReadyErrorMessage = "Database restore error occured %s"
+	// DatabaseRestoreWaitingMessage
+	DatabaseRestoreWaitingMessage = "Waiting for Glance %s to complete the db sync"
+	// GlanceRestoreWaitingMessage
+	GlanceRestoreWaitingMessage = "Waiting for GlanceBackup %s to complete"
+	// GlanceAPIRestoreWaitingMessage
+	GlanceAPIRestoreWaitingMessage = "Waiting for GlanceRestore %s to restore the PVCs and the database"
+	// ImageMigrationReadyCondition Status=True condition which indicates if
+	// the images have been migrated to the target store
+	ImageMigrationReadyCondition condition.Type = "ImageMigrationReady"
+	// ImageMigrationReadyInitMessage
+	ImageMigrationReadyInitMessage = "Image migration not started"
+	// ImageMigrationReadyMessage
+	ImageMigrationReadyMessage = "Image migration completed"
+	// ImageMigrationReadyRunningMessage
+	ImageMigrationReadyRunningMessage = "Image migration job still running"
+	// ImageMigrationReadyErrorMessage
+	ImageMigrationReadyErrorMessage = "Image migration error occured %s"
+	// ImageMigrationFailedMessage
+	ImageMigrationFailedMessage = "Image migration completed: %d of %d images could not be migrated"
+	// ImageMigrationWaitingMessage
+	ImageMigrationWaitingMessage = "Waiting for Glance %s to be ready"
+	// ImageMigrationStoreErrorMessage
+	ImageMigrationStoreErrorMessage = "Store %s is not configured in GlanceAPI %s"
+	// ImageMigrationSameStoreErrorMessage
+	ImageMigrationSameStoreErrorMessage = "sourceStore and targetStore must be different"
+	// ImageReadyCondition Status=True condition which indicates if the
+	// image of a GlanceImage is active
+	ImageReadyCondition condition.Type = "ImageReady"
+	// ImageReadyInitMessage
+	ImageReadyInitMessage = "Image not created"
+	// ImageReadyMessage
+	ImageReadyMessage = "Image active"
+	// ImageReadyRunningMessage
+	ImageReadyRunningMessage = "Image %s is %s"
+	// ImageReadyErrorMessage
+	ImageReadyErrorMessage = "Image error occured %s"
+	// ImageImportFailedMessage
+	ImageImportFailedMessage = "Image %s import failed for stores %s"
+	// ImageUploadRunningMessage
+	ImageUploadRunningMessage = "Image upload job still running"
+	// ImageWaitingMessage
+	ImageWaitingMessage = "Waiting for the GlanceAPI of Glance %s to be ready"
+	// ImageStoreErrorMessage
+	ImageStoreErrorMessage = "Store %s is not configured in GlanceAPI %s"
+	// ImageSourceErrorMessage
+	ImageSourceErrorMessage = "Exactly one of source.url and source.pvc must be set"
 )
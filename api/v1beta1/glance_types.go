@@ -28,6 +28,8 @@ import (
 const (
 	// DbSyncHash hash
 	DbSyncHash = "dbsync"
+	// MetadefsHash hash
+	MetadefsHash = "metadefs"
 	// APIInternal -
 	APIInternal = "internal"
 	// APIExternal -
@@ -40,6 +42,20 @@ const (
 	GlanceWSGILabel = "glance.openstack.org/wsgi"
 	// GlanceLocationAPILabel -
 	GlanceLocationAPILabel = "glance.openstack.org/location-api"
+	// KeyManagerBarbican -
+	KeyManagerBarbican = "barbican"
+	// KeyManagerLocal -
+	KeyManagerLocal = "local"
+	// KeyManagerDisabled -
+	KeyManagerDisabled = "disabled"
+	// AuditDriverLog -
+	AuditDriverLog = "log"
+	// AuditDriverNotifications -
+	AuditDriverNotifications = "notifications"
+	// LogDestinationFile -
+	LogDestinationFile = "file"
+	// LogDestinationStdout -
+	LogDestinationStdout = "stdout"
 )
 
 // GlanceSpecCore defines the desired state of Glance
@@ -132,6 +148,13 @@ type GlanceSpecCore struct {
 	// DBPurge parameters -
 	DBPurge DBPurge `json:"dbPurge,omitempty"`
 
+	// +kubebuilder:validation:Optional
+	// ImageCleanup - periodically removes the images stuck in a non-active
+	// state and the orphaned files of the staging areas. A per-replica staging
+	// area is cleaned up by a CronJob running on the node of the replica, an
+	// ephemeral one is not cleaned up. Disabled when omitted
+	ImageCleanup *ImageCleanup `json:"imageCleanup,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default=60
 	// +kubebuilder:validation:Minimum=1
@@ -148,6 +171,39 @@ type GlanceSpecCore struct {
 	// +kubebuilder:validation:Optional
 	// NotificationsBus configuration (username, vhost, and cluster) for notifications
 	NotificationsBus *rabbitmqv1.RabbitMqConfig `json:"notificationsBus,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Proxy - outbound HTTP(S) proxy used by the GlanceAPI Pods to reach
+	// external resources (e.g. web-download sources, S3 or Swift endpoints)
+	Proxy *ProxySpec `json:"proxy,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// KeyManager - key manager used to retrieve the certificates required to
+	// verify the image signatures. When omitted, Barbican is configured
+	KeyManager *KeyManagerSpec `json:"keyManager,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Metadefs - list of ConfigMaps holding metadata definitions (metadefs)
+	// JSON files. They are loaded in the Glance database once the db sync is
+	// completed, and loaded again when their content changes
+	Metadefs []string `json:"metadefs,omitempty"`
+}
+
+// ProxySpec defines the outbound proxy injected in the GlanceAPI containers
+type ProxySpec struct {
+	// +kubebuilder:validation:Optional
+	// HTTPProxy - proxy URL used for http requests
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// HTTPSProxy - proxy URL used for https requests
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// NoProxy - additional hosts or domains that should not go through the
+	// proxy. The Keystone, database, memcached and RabbitMQ hostnames are
+	// always added by the operator
+	NoProxy []string `json:"noProxy,omitempty"`
 }
 
 // GlanceSpec defines the desired state of Glance
@@ -160,6 +216,43 @@ type GlanceSpec struct {
 	GlanceSpecCore `json:",inline"`
 }
 
+// KeyManagerSpec defines the key manager used by Glance
+type KeyManagerSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=barbican;local;disabled
+	// +kubebuilder:default=barbican
+	// Backend - barbican requires a Barbican endpoint registered in keystone,
+	// local serves the certificates of LocalKeySecret and it is meant for lab
+	// environments only
+	Backend string `json:"backend"`
+
+	// +kubebuilder:validation:Optional
+	// LocalKeySecret - name of the Secret holding the PEM certificates used
+	// by the local backend to verify the image signatures, each key being
+	// the img_signature_certificate_uuid referenced by the images
+	LocalKeySecret string `json:"localKeySecret,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	// RequireSignatureVerification - when true Glance rejects the upload,
+	// stage and import of the images that don't carry the img_signature
+	// properties, so that every new image is verified, and the GlanceAPI is
+	// not rolled out until the key manager is available
+	RequireSignatureVerification bool `json:"requireSignatureVerification"`
+}
+
+// IsLocal - returns true if the static local key backend is used. It can be
+// called on a nil KeyManagerSpec
+func (km *KeyManagerSpec) IsLocal() bool {
+	return km != nil && km.Backend == KeyManagerLocal
+}
+
+// SignatureRequired - returns true if the unsigned images are rejected. It
+// can be called on a nil KeyManagerSpec
+func (km *KeyManagerSpec) SignatureRequired() bool {
+	return km != nil && km.RequireSignatureVerification
+}
+
 // PasswordSelector to identify the DB and AdminUser password from the Secret
 type PasswordSelector struct {
 	// +kubebuilder:validation:Optional
@@ -181,6 +274,69 @@ type DBPurge struct {
 	Schedule string `json:"schedule"`
 }
 
+// ImageCleanup - parameters of the CronJob removing the stale images
+type ImageCleanup struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="30 0 * * *"
+	// Schedule defines the crontab format string to schedule the ImageCleanup
+	// cronJob
+	Schedule string `json:"schedule"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default={}
+	// MaxAge - number of hours an image can stay in each non-active state
+	// before being deleted
+	MaxAge ImageCleanupMaxAge `json:"maxAge"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	// DryRun - only report the stale images and the orphaned staging files,
+	// nothing is deleted
+	DryRun bool `json:"dryRun"`
+}
+
+// ImageCleanupMaxAge - number of hours an image can stay in each non-active
+// state, 0 never deletes the images in that state
+type ImageCleanupMaxAge struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=24
+	// +kubebuilder:validation:Minimum=0
+	// Queued - image created but no data uploaded or staged
+	Queued int `json:"queued"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=24
+	// +kubebuilder:validation:Minimum=0
+	// Saving - upload of the image data in progress
+	Saving int `json:"saving"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=24
+	// +kubebuilder:validation:Minimum=0
+	// Uploading - image data staged, waiting for the import
+	Uploading int `json:"uploading"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=24
+	// +kubebuilder:validation:Minimum=0
+	// Importing - import of the image data in progress
+	Importing int `json:"importing"`
+}
+
+// ImageCleanupStatus - summary of the last ImageCleanup run
+type ImageCleanupStatus struct {
+	// LastRunTime - when the last run terminated
+	LastRunTime *metav1.Time `json:"lastRunTime,omitempty"`
+	// DryRun - true if the last run did not delete anything
+	DryRun bool `json:"dryRun,omitempty"`
+	// StaleImages - number of images found in a non-active state for longer
+	// than their MaxAge
+	StaleImages int `json:"staleImages,omitempty"`
+	// DeletedImages - number of stale images deleted
+	DeletedImages int `json:"deletedImages,omitempty"`
+	// StaleImageIDs - IDs of the stale images (at most 50)
+	StaleImageIDs []string `json:"staleImageIDs,omitempty"`
+	// OrphanStagingFiles - number of staging files without a pending import
+	OrphanStagingFiles int `json:"orphanStagingFiles,omitempty"`
+	// RemovedStagingFiles - number of orphan staging files removed
+	RemovedStagingFiles int `json:"removedStagingFiles,omitempty"`
+}
+
 // GlanceStatus defines the observed state of Glance
 type GlanceStatus struct {
 	// Map of hashes to track e.g. job status
@@ -210,6 +366,9 @@ type GlanceStatus struct {
 	// NotificationsBusSecret - Secret containing RabbitMQ transportURL used
 	// for notification purposes
 	NotificationBusSecret string `json:"notificationBusSecret,omitempty"`
+
+	// ImageCleanup - summary of the last ImageCleanup run
+	ImageCleanup *ImageCleanupStatus `json:"imageCleanup,omitempty"`
 }
 
 //+kubebuilder:object:root=true
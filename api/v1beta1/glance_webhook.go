@@ -25,6 +25,7 @@ import (
 	"github.com/openstack-k8s-operators/lib-common/modules/common/service"
 	common_webhook "github.com/openstack-k8s-operators/lib-common/modules/common/webhook"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -34,12 +35,13 @@ import (
 
 // GlanceDefaults -
 type GlanceDefaults struct {
-	ContainerImageURL string
-	DBPurgeAge        int
-	DBPurgeSchedule   string
-	CleanerSchedule   string
-	PrunerSchedule    string
-	APITimeout        int
+	ContainerImageURL     string
+	HTTPDExporterImageURL string
+	DBPurgeAge            int
+	DBPurgeSchedule       string
+	CleanerSchedule       string
+	PrunerSchedule        string
+	APITimeout            int
 }
 
 var glanceDefaults GlanceDefaults
@@ -111,6 +113,10 @@ func (r *GlanceSpecCore) Default() {
 		r.DBPurge.Schedule = glanceDefaults.DBPurgeSchedule
 	}
 
+	if r.ImageCleanup != nil && r.ImageCleanup.Schedule == "" {
+		r.ImageCleanup.Schedule = ImageCleanupDefaultSchedule
+	}
+
 	// NotificationsBus.Cluster is not defaulted - it must be explicitly set if NotificationsBus is configured
 	// Migration from deprecated fields is handled by openstack-operator
 	// This ensures users make a conscious choice about which cluster to use for notifications
@@ -145,6 +151,12 @@ func (r *GlanceSpecCore) Default() {
 			glanceAPI.APITimeout = r.APITimeout
 			r.GlanceAPIs[key] = glanceAPI
 		}
+		if glanceAPI.ConsistencyCheck != nil && glanceAPI.ConsistencyCheck.Schedule == "" {
+			glanceAPI.ConsistencyCheck.Schedule = ConsistencyCheckDefaultSchedule
+		}
+		if glanceAPI.Metrics != nil && glanceAPI.Metrics.HTTPDExporterImage == "" {
+			glanceAPI.Metrics.HTTPDExporterImage = glanceDefaults.HTTPDExporterImageURL
+		}
 	}
 	// In the special case where the GlanceAPI list is composed by a single
 	// element, we can omit the "KeystoneEndpoint" spec parameter and default
@@ -197,6 +209,86 @@ func (r *GlanceSpecCore) isInvalidBackend(glanceAPI GlanceAPITemplate, topLevel
 	return false, ""
 }
 
+// isWebDownloadUnfiltered - returns true when the web-download import method
+// is enabled on a layout serving the public endpoint, and the filtering of
+// the URIs has been explicitly disabled
+func (r *GlanceSpecCore) isWebDownloadUnfiltered(glanceAPI GlanceAPITemplate) bool {
+	// Edge GlanceAPIs do not serve any public endpoint
+	if !glanceAPI.ServesPublicEndpoint() {
+		return false
+	}
+	if glanceAPI.ImportFiltering == nil || !glanceAPI.ImportFiltering.IsEmpty() {
+		return false
+	}
+	// customServiceConfig is inherited from the top-level CR when it's not
+	// specified in the GlanceAPITemplate
+	customServiceConfig := glanceAPI.CustomServiceConfig
+	if customServiceConfig == "" {
+		customServiceConfig = r.CustomServiceConfig
+	}
+	return IsWebDownloadEnabled(customServiceConfig)
+}
+
+// ValidateKeyManager - validates the KeyManager parameters
+func (km *KeyManagerSpec) ValidateKeyManager(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if km == nil {
+		return allErrs
+	}
+	if km.Backend == KeyManagerLocal && km.LocalKeySecret == "" {
+		allErrs = append(allErrs, field.Required(
+			basePath.Child("localKeySecret"), KeyManagerLocalKeyErrorMessage))
+	}
+	if km.Backend == KeyManagerDisabled && km.RequireSignatureVerification {
+		allErrs = append(allErrs, field.Invalid(
+			basePath.Child("requireSignatureVerification"),
+			km.RequireSignatureVerification, KeyManagerSignatureErrorMessage))
+	}
+	return allErrs
+}
+
+// ValidateStaging - validates the Staging parameters
+func (s *Staging) ValidateStaging(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if s == nil {
+		return allErrs
+	}
+	if s.Shared && !s.VolumeType.IsPVC() {
+		allErrs = append(allErrs, field.Invalid(
+			basePath.Child("volumeType"), s.VolumeType, GlanceStagingSharedErrorMessage))
+	}
+	return allErrs
+}
+
+// ValidateAutogrow - validates the Autogrow parameters against the
+// storageRequest the PVCs are created with
+func (a *StorageAutogrow) ValidateAutogrow(basePath *field.Path, storageRequest string) field.ErrorList {
+	var allErrs field.ErrorList
+	if a == nil {
+		return allErrs
+	}
+	increment, err := resource.ParseQuantity(a.Increment)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(
+			basePath.Child("increment"), a.Increment, err.Error()))
+	} else if increment.Sign() <= 0 {
+		allErrs = append(allErrs, field.Invalid(
+			basePath.Child("increment"), a.Increment, GlanceAutogrowIncrementErrorMessage))
+	}
+	maximum, err := resource.ParseQuantity(a.MaximumSize)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(
+			basePath.Child("maximumSize"), a.MaximumSize, err.Error()))
+		return allErrs
+	}
+	if request, err := resource.ParseQuantity(storageRequest); err == nil && maximum.Cmp(request) < 0 {
+		allErrs = append(allErrs, field.Invalid(
+			basePath.Child("maximumSize"), a.MaximumSize,
+			fmt.Sprintf(GlanceAutogrowMaximumErrorMessage, a.MaximumSize, storageRequest)))
+	}
+	return allErrs
+}
+
 // getDeprecatedFields returns the centralized list of deprecated fields for GlanceSpecCore
 func (spec *GlanceSpecCore) getDeprecatedFields(old *GlanceSpecCore) []common_webhook.DeprecatedFieldUpdate {
 	// Get new field value (handle nil NotificationsBus)
@@ -318,6 +410,13 @@ func (r *GlanceSpecCore) ValidateCreate(basePath *field.Path, namespace string)
 	allErrs = append(allErrs, topologyv1.ValidateTopologyRef(
 		r.TopologyRef, *basePath.Child("topologyRef"), namespace)...)
 
+	// fail if an invalid KeyManager configuration is provided
+	allErrs = append(allErrs, r.KeyManager.ValidateKeyManager(basePath.Child("keyManager"))...)
+
+	// fail if an invalid autogrow policy is provided
+	allErrs = append(allErrs, r.Storage.Autogrow.ValidateAutogrow(
+		basePath.Child("storage").Child("autogrow"), r.Storage.StorageRequest)...)
+
 	// For each Glance backend
 	for key, glanceAPI := range r.GlanceAPIs {
 		path := basePath.Child("glanceAPIs").Key(key)
@@ -325,11 +424,22 @@ func (r *GlanceSpecCore) ValidateCreate(basePath *field.Path, namespace string)
 		// fail if a wrong topology is referenced
 		allErrs = append(allErrs, glanceAPI.ValidateTopology(path, namespace)...)
 
+		// fail if an invalid staging area is requested
+		allErrs = append(allErrs, glanceAPI.Staging.ValidateStaging(path.Child("staging"))...)
+		allErrs = append(allErrs, glanceAPI.Storage.Autogrow.ValidateAutogrow(
+			path.Child("storage").Child("autogrow"),
+			inheritSize(glanceAPI.Storage.StorageRequest, r.Storage.StorageRequest))...)
+
 		// fail if an invalid configuration/layout is detected
 		if ok, err := r.isInvalidBackend(glanceAPI, topLevelFileBackend); ok {
 			allErrs = append(allErrs, field.Invalid(path, key, err))
 		}
 
+		// warn if web-download can be used to fetch images from any host
+		if r.isWebDownloadUnfiltered(glanceAPI) {
+			allWarns = append(allWarns, fmt.Sprintf(GlanceWarnWebDownloadUnfilteredMsg, path.String()))
+		}
+
 		// validate the service override key is valid
 		allErrs = append(allErrs, service.ValidateRoutedOverrides(
 			path.Child("override").Child("service"),
@@ -415,6 +525,13 @@ func (r *GlanceSpecCore) ValidateUpdate(old GlanceSpecCore, basePath *field.Path
 	allErrs = append(allErrs, topologyv1.ValidateTopologyRef(
 		r.TopologyRef, *basePath.Child("topologyRef"), namespace)...)
 
+	// fail if an invalid KeyManager configuration is provided
+	allErrs = append(allErrs, r.KeyManager.ValidateKeyManager(basePath.Child("keyManager"))...)
+
+	// fail if an invalid autogrow policy is provided
+	allErrs = append(allErrs, r.Storage.Autogrow.ValidateAutogrow(
+		basePath.Child("storage").Child("autogrow"), r.Storage.StorageRequest)...)
+
 	// Type can either be "split" or "single": we do not support changing layout
 	// because there's no logic in the operator to scale down the existing statefulset
 	// and scale up the new one, hence updating the Spec.GlanceAPI.Type is not supported
@@ -428,6 +545,17 @@ func (r *GlanceSpecCore) ValidateUpdate(old GlanceSpecCore, basePath *field.Path
 		// fail if a wrong topology is referenced
 		allErrs = append(allErrs, glanceAPI.ValidateTopology(path, namespace)...)
 
+		// fail if an invalid staging area is requested
+		allErrs = append(allErrs, glanceAPI.Staging.ValidateStaging(path.Child("staging"))...)
+		allErrs = append(allErrs, glanceAPI.Storage.Autogrow.ValidateAutogrow(
+			path.Child("storage").Child("autogrow"),
+			inheritSize(glanceAPI.Storage.StorageRequest, r.Storage.StorageRequest))...)
+
+		// warn if web-download can be used to fetch images from any host
+		if r.isWebDownloadUnfiltered(glanceAPI) {
+			allWarns = append(allWarns, fmt.Sprintf(GlanceWarnWebDownloadUnfilteredMsg, path.String()))
+		}
+
 		// When a new entry (new glanceAPI instance) is added in the main CR, it's
 		// possible that the old CR used to compare the new map had no entry with
 		// the same name. This represent a valid use case and we shouldn't prevent
@@ -454,6 +582,28 @@ func (r *GlanceSpecCore) ValidateUpdate(old GlanceSpecCore, basePath *field.Path
 			path.Child("override").Child("service"),
 			glanceAPI.Override.Service)...)
 
+		// Existing PVCs can be expanded but not shrunk: compare the sizes
+		// inherited from the top-level CR when not set at the API level
+		oldAPI := old.GlanceAPIs[key]
+		allErrs = append(allErrs, ValidateStorageResize(
+			path.Child("storage").Child("storageRequest"),
+			inheritSize(glanceAPI.Storage.StorageRequest, r.Storage.StorageRequest),
+			inheritSize(oldAPI.Storage.StorageRequest, old.Storage.StorageRequest))...)
+		allErrs = append(allErrs, ValidateStorageResize(
+			path.Child("imageCache").Child("size"),
+			inheritSize(glanceAPI.ImageCache.Size, r.ImageCache.Size),
+			inheritSize(oldAPI.ImageCache.Size, old.ImageCache.Size))...)
+		if glanceAPI.ImageConversion != nil && oldAPI.ImageConversion != nil {
+			allErrs = append(allErrs, ValidateStorageResize(
+				path.Child("imageConversion").Child("size"),
+				glanceAPI.ImageConversion.Size, oldAPI.ImageConversion.Size)...)
+		}
+		if glanceAPI.Staging != nil && oldAPI.Staging != nil {
+			allErrs = append(allErrs, ValidateStorageResize(
+				path.Child("staging").Child("size"),
+				glanceAPI.Staging.Size, oldAPI.Staging.Size)...)
+		}
+
 		// Probes validation
 		probeErrs := r.ValidateProbes(path)
 		allErrs = append(allErrs, probeErrs...)
@@ -471,6 +621,38 @@ func (r *GlanceSpecCore) ValidateUpdate(old GlanceSpecCore, basePath *field.Path
 	return allWarns, allErrs
 }
 
+// ValidateStorageResize - returns an error if a PVC size is decreased, as
+// the existing PVCs can only be expanded. Enabling or disabling a PVC (empty
+// size) is not a resize and it is always allowed
+func ValidateStorageResize(path *field.Path, newSize string, oldSize string) field.ErrorList {
+	if newSize == "" || oldSize == "" {
+		return nil
+	}
+	newQuantity, err := resource.ParseQuantity(newSize)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, newSize, err.Error())}
+	}
+	oldQuantity, err := resource.ParseQuantity(oldSize)
+	if err != nil {
+		// nothing to compare with
+		return nil
+	}
+	if newQuantity.Cmp(oldQuantity) < 0 {
+		return field.ErrorList{field.Forbidden(path,
+			fmt.Sprintf(GlanceStorageShrinkErrorMessage, newSize, oldSize))}
+	}
+	return nil
+}
+
+// inheritSize - returns the size set at the API level, or the top-level one
+// the GlanceAPI inherits when it is not set
+func inheritSize(apiSize string, topLevelSize string) string {
+	if apiSize == "" {
+		return topLevelSize
+	}
+	return apiSize
+}
+
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
 func (r *Glance) ValidateDelete() (admission.Warnings, error) {
 	glancelog.Info("validate delete", "name", r.Name)
@@ -22,6 +22,7 @@ import (
 	topologyv1 "github.com/openstack-k8s-operators/infra-operator/apis/topology/v1beta1"
 	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/endpoint"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -92,6 +93,14 @@ type GlanceAPISpec struct {
 	// +kubebuilder:validation:Optional
 	// Secret containing RabbitMq transport URL
 	NotificationBusSecret string `json:"notificationBusSecret"`
+
+	// +kubebuilder:validation:Optional
+	// Proxy - outbound HTTP(S) proxy inherited from the top-level CR
+	Proxy *ProxySpec `json:"proxy,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// KeyManager - key manager inherited from the top-level CR
+	KeyManager *KeyManagerSpec `json:"keyManager,omitempty"`
 }
 
 // GlanceAPIStatus defines the observed state of GlanceAPI
@@ -134,6 +143,107 @@ type GlanceAPIStatus struct {
 
 	// ApplicationCredentialSecret - Secret that GlanceAPI is actively consuming (AC consumer finalizer present)
 	ApplicationCredentialSecret string `json:"applicationCredentialSecret,omitempty"`
+
+	// Workers - number of glance-api worker processes rendered in the config
+	Workers int32 `json:"workers,omitempty"`
+
+	// Processes - number of WSGIDaemonProcess processes rendered in the config
+	Processes int32 `json:"processes,omitempty"`
+
+	// Threads - number of threads of each WSGIDaemonProcess process rendered
+	// in the config
+	Threads int32 `json:"threads,omitempty"`
+
+	// StatefulSetRevision - revision of the StatefulSet being deployed. It is
+	// bumped when the backend configuration changes, and a new StatefulSet is
+	// rolled out next to the one currently serving the API
+	StatefulSetRevision int32 `json:"statefulSetRevision,omitempty"`
+
+	// ServingRevision - revision of the StatefulSet selected by the API
+	// Services. It is aligned to StatefulSetRevision once the new Pods are
+	// Ready
+	ServingRevision int32 `json:"servingRevision,omitempty"`
+
+	// ImageCacheSize - size of the image cache rendered as image_cache_max_size.
+	// It is aligned to ImageCache.Size once the cache PVCs have been resized
+	ImageCacheSize string `json:"imageCacheSize,omitempty"`
+
+	// ConsistencyCheck - result of the last consistency check of each
+	// replica, indexed by Pod name. The IDs are listed in the
+	// <glanceapi>-consistency-report ConfigMap
+	ConsistencyCheck map[string]ConsistencyCheckStatus `json:"consistencyCheck,omitempty"`
+
+	// MissingImageFiles - number of file locations whose file is on none of
+	// the replicas, computed once the consistency check of every replica
+	// has run
+	MissingImageFiles *int `json:"missingImageFiles,omitempty"`
+
+	// StorageCapacity - capacity and usage of the image and cache volumes of
+	// each replica, indexed by Pod name. Only reported when the storage
+	// monitoring is enabled
+	StorageCapacity map[string]ReplicaStorageStatus `json:"storageCapacity,omitempty"`
+
+	// StorageAutogrow - expansions performed by the autogrow policy, indexed
+	// by PVC name
+	StorageAutogrow map[string]PVCAutogrowStatus `json:"storageAutogrow,omitempty"`
+}
+
+// PVCAutogrowStatus - last expansion of a PVC performed by the autogrow
+// policy
+type PVCAutogrowStatus struct {
+	// Size - size requested by the last expansion
+	Size string `json:"size"`
+	// Expansions - number of expansions performed
+	Expansions int `json:"expansions"`
+	// LastExpansionTime - when the last expansion has been requested
+	LastExpansionTime metav1.Time `json:"lastExpansionTime,omitempty"`
+	// LimitReached - the PVC has reached the maximum size of the policy
+	LimitReached bool `json:"limitReached,omitempty"`
+	// FilesystemCapacity - size of the file system reported by the kubelet
+	// when the last expansion has been requested. The PVC is not expanded
+	// again until the kubelet reports the resized file system
+	FilesystemCapacity resource.Quantity `json:"filesystemCapacity,omitempty"`
+}
+
+// ReplicaStorageStatus - capacity and usage of the volumes of a replica, as
+// reported by the kubelet
+type ReplicaStorageStatus struct {
+	// Image - the glance volume holding the images
+	Image *VolumeUsage `json:"image,omitempty"`
+	// Cache - the image cache volume
+	Cache *VolumeUsage `json:"cache,omitempty"`
+	// LastUpdateTime - when the usage has been collected
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// VolumeUsage - capacity and usage of a volume
+type VolumeUsage struct {
+	// Capacity - total size of the filesystem
+	Capacity resource.Quantity `json:"capacity"`
+	// Used - space used on the filesystem
+	Used resource.Quantity `json:"used"`
+	// Available - space available on the filesystem
+	Available resource.Quantity `json:"available"`
+	// UsedPercent - Used over Capacity, in percent
+	UsedPercent int `json:"usedPercent"`
+}
+
+// ConsistencyCheckStatus - summary of the last consistency check of a replica
+type ConsistencyCheckStatus struct {
+	// LastRunTime - when the last run terminated
+	LastRunTime *metav1.Time `json:"lastRunTime,omitempty"`
+	// Repair - true if the last run removed the orphan files
+	Repair bool `json:"repair,omitempty"`
+	// Files - number of image files found in the file stores
+	Files int `json:"files,omitempty"`
+	// Locations - number of file locations recorded in the database
+	Locations int `json:"locations,omitempty"`
+	// OrphanFiles - number of image files that no location refers to
+	OrphanFiles int `json:"orphanFiles,omitempty"`
+	// HeldFiles - number of locations whose file is on this replica
+	HeldFiles int `json:"heldFiles,omitempty"`
+	// RemovedFiles - number of orphan files removed
+	RemovedFiles int `json:"removedFiles,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -22,13 +22,16 @@ import (
 	"github.com/google/go-cmp/cmp"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 // GlanceAPIDefaults -
 type GlanceAPIDefaults struct {
-	ContainerImageURL string
+	ContainerImageURL     string
+	HTTPDExporterImageURL string
 }
 
 var glanceAPIDefaults GlanceAPIDefaults
@@ -54,13 +57,24 @@ func (spec *GlanceAPISpec) Default() {
 	if spec.ContainerImage == "" {
 		spec.ContainerImage = glanceAPIDefaults.ContainerImageURL
 	}
+	if spec.Metrics != nil && spec.Metrics.HTTPDExporterImage == "" {
+		spec.Metrics.HTTPDExporterImage = glanceAPIDefaults.HTTPDExporterImageURL
+	}
 }
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (r *GlanceAPI) ValidateCreate() (admission.Warnings, error) {
 	glanceapilog.Info("validate create", "name", r.Name)
 
-	// TODO(user): fill in your validation logic upon object creation.
+	basePath := field.NewPath("spec")
+	allErrs := r.Spec.Staging.ValidateStaging(basePath.Child("staging"))
+	allErrs = append(allErrs, r.Spec.Storage.Autogrow.ValidateAutogrow(
+		basePath.Child("storage").Child("autogrow"), r.Spec.Storage.StorageRequest)...)
+	if len(allErrs) != 0 {
+		return nil, apierrors.NewInvalid(
+			schema.GroupKind{Group: "glance.openstack.org", Kind: "GlanceAPI"},
+			r.Name, allErrs)
+	}
 	return nil, nil
 }
 
@@ -75,6 +89,36 @@ func (r *GlanceAPI) ValidateUpdate(old runtime.Object) (admission.Warnings, erro
 
 	glanceapilog.Info("validate update", "diff", cmp.Diff(o, r))
 
+	var allErrs field.ErrorList
+	basePath := field.NewPath("spec")
+	allErrs = append(allErrs, r.Spec.Staging.ValidateStaging(basePath.Child("staging"))...)
+	allErrs = append(allErrs, r.Spec.Storage.Autogrow.ValidateAutogrow(
+		basePath.Child("storage").Child("autogrow"), r.Spec.Storage.StorageRequest)...)
+
+	// Existing PVCs can be expanded but not shrunk
+	allErrs = append(allErrs, ValidateStorageResize(
+		basePath.Child("storage").Child("storageRequest"),
+		r.Spec.Storage.StorageRequest, o.Spec.Storage.StorageRequest)...)
+	allErrs = append(allErrs, ValidateStorageResize(
+		basePath.Child("imageCache").Child("size"),
+		r.Spec.ImageCache.Size, o.Spec.ImageCache.Size)...)
+	if r.Spec.ImageConversion != nil && o.Spec.ImageConversion != nil {
+		allErrs = append(allErrs, ValidateStorageResize(
+			basePath.Child("imageConversion").Child("size"),
+			r.Spec.ImageConversion.Size, o.Spec.ImageConversion.Size)...)
+	}
+	if r.Spec.Staging != nil && o.Spec.Staging != nil {
+		allErrs = append(allErrs, ValidateStorageResize(
+			basePath.Child("staging").Child("size"),
+			r.Spec.Staging.Size, o.Spec.Staging.Size)...)
+	}
+
+	if len(allErrs) != 0 {
+		return nil, apierrors.NewInvalid(
+			schema.GroupKind{Group: "glance.openstack.org", Kind: "GlanceAPI"},
+			r.Name, allErrs)
+	}
+
 	return nil, nil
 }
 
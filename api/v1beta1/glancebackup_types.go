@@ -0,0 +1,203 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DatabaseBackupHash - hash of the database backup Job
+	DatabaseBackupHash = "dbbackup"
+	// DatabaseRestoreHash - hash of the database restore Job
+	DatabaseRestoreHash = "dbrestore"
+	// RestoredFromAnnotation - set on the PVCs recreated by a GlanceRestore,
+	// it references the GlanceBackup they come from
+	RestoredFromAnnotation = "glance.openstack.org/restored-from"
+)
+
+// GlanceBackupSpec defines the desired state of GlanceBackup
+type GlanceBackupSpec struct {
+	// +kubebuilder:validation:Required
+	// GlanceName - name of the Glance whose image PVCs and database are
+	// backed up
+	GlanceName string `json:"glanceName"`
+
+	// +kubebuilder:validation:Optional
+	// VolumeSnapshotClassName - VolumeSnapshotClass used to snapshot the
+	// image PVCs. The default class of the CSI driver is used when omitted
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="10G"
+	// DatabaseStorageRequest - size of the PVC holding the database dump
+	DatabaseStorageRequest string `json:"databaseStorageRequest"`
+
+	// +kubebuilder:validation:Optional
+	// DatabaseStorageClass - StorageClass of the PVC holding the database
+	// dump, defaults to the Glance Storage StorageClass
+	DatabaseStorageClass string `json:"databaseStorageClass,omitempty"`
+}
+
+// GlanceBackupVolume - an image PVC captured by a GlanceBackup
+type GlanceBackupVolume struct {
+	// SourcePVCName - name of the snapshotted PVC
+	SourcePVCName string `json:"sourcePVCName"`
+	// PVCName - name a GlanceRestore recreates the PVC with: the PVC of the
+	// same replica of a freshly deployed StatefulSet, so that it is adopted
+	PVCName string `json:"pvcName"`
+	// SnapshotName - name of the VolumeSnapshot of the PVC
+	SnapshotName string `json:"snapshotName"`
+	// StorageClassName - StorageClass of the PVC
+	StorageClassName string `json:"storageClassName,omitempty"`
+	// StorageRequest - size of the PVC
+	StorageRequest string `json:"storageRequest"`
+	// Labels - labels of the PVC, restored with it
+	Labels map[string]string `json:"labels,omitempty"`
+	// ReadyToUse - true when the VolumeSnapshot can be used to restore the PVC
+	ReadyToUse bool `json:"readyToUse,omitempty"`
+}
+
+// GlanceBackupStatus defines the observed state of GlanceBackup
+type GlanceBackupStatus struct {
+	// Conditions
+	Conditions condition.Conditions `json:"conditions,omitempty" optional:"true"`
+
+	// Map of hashes to track e.g. job status
+	Hash map[string]string `json:"hash,omitempty"`
+
+	// ObservedGeneration - the most recent generation observed for this
+	// object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Volumes - the image PVCs snapshotted by the backup
+	Volumes []GlanceBackupVolume `json:"volumes,omitempty"`
+
+	// DatabaseBackupPVC - PVC holding the database dump
+	DatabaseBackupPVC string `json:"databaseBackupPVC,omitempty"`
+
+	// CompletionTime - when the backup completed. A completed backup is
+	// never taken again
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Glance",type="string",JSONPath=".spec.glanceName",description="Glance"
+//+kubebuilder:printcolumn:name="Completed",type="string",JSONPath=".status.completionTime",description="Completed"
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[0].status",description="Status"
+//+kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.conditions[0].message",description="Message"
+
+// GlanceBackup is the Schema for the glancebackups API
+type GlanceBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GlanceBackupSpec   `json:"spec,omitempty"`
+	Status GlanceBackupStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GlanceBackupList contains a list of GlanceBackup
+type GlanceBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GlanceBackup `json:"items"`
+}
+
+// GlanceRestoreSpec defines the desired state of GlanceRestore
+type GlanceRestoreSpec struct {
+	// +kubebuilder:validation:Required
+	// BackupName - name of the GlanceBackup to restore
+	BackupName string `json:"backupName"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=true
+	// RestoreDatabase - load the database dump of the GlanceBackup once the
+	// Glance db sync has run. The GlanceAPIs are held until it is loaded
+	RestoreDatabase bool `json:"restoreDatabase"`
+}
+
+// GlanceRestoreStatus defines the observed state of GlanceRestore
+type GlanceRestoreStatus struct {
+	// Conditions
+	Conditions condition.Conditions `json:"conditions,omitempty" optional:"true"`
+
+	// Map of hashes to track e.g. job status
+	Hash map[string]string `json:"hash,omitempty"`
+
+	// ObservedGeneration - the most recent generation observed for this
+	// object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// GlanceName - name of the Glance the GlanceBackup has been taken from.
+	// Its GlanceAPIs wait for the PVCs to be restored before deploying the
+	// StatefulSets
+	GlanceName string `json:"glanceName,omitempty"`
+
+	// RestoredPVCs - the PVCs recreated from the GlanceBackup snapshots
+	RestoredPVCs []string `json:"restoredPVCs,omitempty"`
+
+	// CompletionTime - when the restore completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Backup",type="string",JSONPath=".spec.backupName",description="Backup"
+//+kubebuilder:printcolumn:name="Completed",type="string",JSONPath=".status.completionTime",description="Completed"
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[0].status",description="Status"
+//+kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.conditions[0].message",description="Message"
+
+// GlanceRestore is the Schema for the glancerestores API
+type GlanceRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GlanceRestoreSpec   `json:"spec,omitempty"`
+	Status GlanceRestoreStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GlanceRestoreList contains a list of GlanceRestore
+type GlanceRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GlanceRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GlanceBackup{}, &GlanceBackupList{}, &GlanceRestore{}, &GlanceRestoreList{})
+}
+
+// IsReady - returns true if the GlanceBackup is completed
+func (instance GlanceBackup) IsReady() bool {
+	return instance.Status.Conditions.IsTrue(condition.ReadyCondition)
+}
+
+// IsReady - returns true if the GlanceRestore is completed
+func (instance GlanceRestore) IsReady() bool {
+	return instance.Status.Conditions.IsTrue(condition.ReadyCondition)
+}
+
+// DatabaseBackupPVCName - name of the PVC holding the database dump
+func (instance GlanceBackup) DatabaseBackupPVCName() string {
+	return instance.Name + "-db"
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ImageUploadHash - hash of the Job uploading the image data of a
+	// GlanceImage with a PVC source
+	ImageUploadHash = "imageupload"
+)
+
+// GlanceImageSpec defines the desired state of GlanceImage
+type GlanceImageSpec struct {
+	// +kubebuilder:validation:Required
+	// GlanceName - name of the Glance the image is created in. The image is
+	// created through the GlanceAPI registered in the keystone catalog
+	GlanceName string `json:"glanceName"`
+
+	// +kubebuilder:validation:Optional
+	// ImageName - name of the image in Glance, defaults to the name of the
+	// GlanceImage
+	ImageName string `json:"imageName,omitempty"`
+
+	// +kubebuilder:validation:Required
+	// Source - where the image data is imported from. Exactly one of url and
+	// pvc must be set
+	Source GlanceImageSource `json:"source"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=qcow2
+	// +kubebuilder:validation:Enum=ami;ari;aki;vhd;vhdx;vmdk;raw;qcow2;vdi;ploop;iso
+	// DiskFormat - disk format of the image
+	DiskFormat string `json:"diskFormat"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=bare
+	// +kubebuilder:validation:Enum=ami;ari;aki;bare;ovf;ova;docker;compressed
+	// ContainerFormat - container format of the image
+	ContainerFormat string `json:"containerFormat"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=public
+	// +kubebuilder:validation:Enum=public;private;shared;community
+	// Visibility - visibility of the image
+	Visibility string `json:"visibility"`
+
+	// +kubebuilder:validation:Optional
+	// Properties - additional properties of the image (e.g. hw_disk_bus,
+	// os_distro)
+	Properties map[string]string `json:"properties,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Stores - store_id of the backends the image is imported to. The
+	// default backend is used when omitted
+	Stores []string `json:"stores,omitempty"`
+}
+
+// GlanceImageSource - where the data of a GlanceImage is imported from
+type GlanceImageSource struct {
+	// +kubebuilder:validation:Optional
+	// URL - imported with the web-download import method: it must be
+	// reachable from the GlanceAPI Pods
+	URL string `json:"url,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// PVC - imported with the glance-direct import method: the data is
+	// staged by a Job that mounts the PVC
+	PVC *GlanceImagePVCSource `json:"pvc,omitempty"`
+}
+
+// GlanceImagePVCSource - image file stored in a PVC
+type GlanceImagePVCSource struct {
+	// +kubebuilder:validation:Required
+	// ClaimName - name of the PVC holding the image file
+	ClaimName string `json:"claimName"`
+
+	// +kubebuilder:validation:Required
+	// Path - path of the image file, relative to the root of the PVC
+	Path string `json:"path"`
+}
+
+// GlanceImageStatus defines the observed state of GlanceImage
+type GlanceImageStatus struct {
+	// Conditions
+	Conditions condition.Conditions `json:"conditions,omitempty" optional:"true"`
+
+	// Map of hashes to track e.g. job status
+	Hash map[string]string `json:"hash,omitempty"`
+
+	// ObservedGeneration - the most recent generation observed for this
+	// object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ImageID - ID of the image in Glance
+	ImageID string `json:"imageID,omitempty"`
+
+	// ImageStatus - status of the image in Glance
+	ImageStatus string `json:"imageStatus,omitempty"`
+
+	// Stores - stores the image is available in
+	Stores []string `json:"stores,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="ImageID",type="string",JSONPath=".status.imageID",description="ImageID"
+//+kubebuilder:printcolumn:name="ImageStatus",type="string",JSONPath=".status.imageStatus",description="ImageStatus"
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[0].status",description="Status"
+//+kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.conditions[0].message",description="Message"
+
+// GlanceImage is the Schema for the glanceimages API
+type GlanceImage struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GlanceImageSpec   `json:"spec,omitempty"`
+	Status GlanceImageStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GlanceImageList contains a list of GlanceImage
+type GlanceImageList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GlanceImage `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GlanceImage{}, &GlanceImageList{})
+}
+
+// IsReady - returns true if the image is active in Glance
+func (instance GlanceImage) IsReady() bool {
+	return instance.Status.Conditions.IsTrue(condition.ReadyCondition)
+}
+
+// GetImageName - returns the name of the image in Glance
+func (instance GlanceImage) GetImageName() string {
+	if instance.Spec.ImageName != "" {
+		return instance.Spec.ImageName
+	}
+	return instance.Name
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ImageMigrationHash - hash of the image migration Job
+	ImageMigrationHash = "imagemigration"
+)
+
+// GlanceImageMigrationSpec defines the desired state of GlanceImageMigration
+type GlanceImageMigrationSpec struct {
+	// +kubebuilder:validation:Required
+	// GlanceName - name of the Glance whose images are migrated. The Job
+	// talks to the GlanceAPI registered in the keystone catalog
+	GlanceName string `json:"glanceName"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// SourceStore - store_id of the backend the images are moved from
+	SourceStore string `json:"sourceStore"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// TargetStore - store_id of the backend the images are copied to
+	TargetStore string `json:"targetStore"`
+
+	// +kubebuilder:validation:Optional
+	// ImageFilter - filters passed to the image list API to select the images
+	// to migrate (e.g. visibility, tag, name or any image property). All the
+	// images stored in SourceStore are migrated when omitted
+	ImageFilter map[string]string `json:"imageFilter,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	// RemoveSourceLocation - remove the image from SourceStore once it has
+	// been copied to TargetStore
+	RemoveSourceLocation bool `json:"removeSourceLocation"`
+}
+
+// GlanceImageMigrationStatus defines the observed state of GlanceImageMigration
+type GlanceImageMigrationStatus struct {
+	// Conditions
+	Conditions condition.Conditions `json:"conditions,omitempty" optional:"true"`
+
+	// Map of hashes to track e.g. job status
+	Hash map[string]string `json:"hash,omitempty"`
+
+	// ObservedGeneration - the most recent generation observed for this
+	// object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Total - number of images stored in SourceStore matching the filter
+	Total int `json:"total,omitempty"`
+
+	// Copied - number of images available in TargetStore
+	Copied int `json:"copied,omitempty"`
+
+	// Failed - number of images that could not be migrated
+	Failed int `json:"failed,omitempty"`
+
+	// Remaining - number of images not processed yet
+	Remaining int `json:"remaining,omitempty"`
+
+	// FailedImages - IDs of the images that could not be migrated
+	FailedImages []string `json:"failedImages,omitempty"`
+
+	// CompletionTime - when the migration completed. A completed migration
+	// is never run again
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Source",type="string",JSONPath=".spec.sourceStore",description="Source"
+//+kubebuilder:printcolumn:name="Target",type="string",JSONPath=".spec.targetStore",description="Target"
+//+kubebuilder:printcolumn:name="Copied",type="integer",JSONPath=".status.copied",description="Copied"
+//+kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.failed",description="Failed"
+//+kubebuilder:printcolumn:name="Remaining",type="integer",JSONPath=".status.remaining",description="Remaining"
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[0].status",description="Status"
+//+kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.conditions[0].message",description="Message"
+
+// GlanceImageMigration is the Schema for the glanceimagemigrations API
+type GlanceImageMigration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GlanceImageMigrationSpec   `json:"spec,omitempty"`
+	Status GlanceImageMigrationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GlanceImageMigrationList contains a list of GlanceImageMigration
+type GlanceImageMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GlanceImageMigration `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GlanceImageMigration{}, &GlanceImageMigrationList{})
+}
+
+// IsReady - returns true if the GlanceImageMigration is completed
+func (instance GlanceImageMigration) IsReady() bool {
+	return instance.Status.Conditions.IsTrue(condition.ReadyCondition)
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/service"
 	"github.com/openstack-k8s-operators/lib-common/modules/storage"
+	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -52,6 +53,21 @@ func (in *APIOverrideSpec) DeepCopy() *APIOverrideSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditSpec) DeepCopyInto(out *AuditSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditSpec.
+func (in *AuditSpec) DeepCopy() *AuditSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthSpec) DeepCopyInto(out *AuthSpec) {
 	*out = *in
@@ -67,6 +83,70 @@ func (in *AuthSpec) DeepCopy() *AuthSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsistencyCheck) DeepCopyInto(out *ConsistencyCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsistencyCheck.
+func (in *ConsistencyCheck) DeepCopy() *ConsistencyCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsistencyCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsistencyCheckStatus) DeepCopyInto(out *ConsistencyCheckStatus) {
+	*out = *in
+	if in.LastRunTime != nil {
+		in, out := &in.LastRunTime, &out.LastRunTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsistencyCheckStatus.
+func (in *ConsistencyCheckStatus) DeepCopy() *ConsistencyCheckStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsistencyCheckStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerResources) DeepCopyInto(out *ContainerResources) {
+	*out = *in
+	if in.Log != nil {
+		in, out := &in.Log, &out.Log
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTPD != nil {
+		in, out := &in.HTTPD, &out.HTTPD
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.API != nil {
+		in, out := &in.API, &out.API
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerResources.
+func (in *ContainerResources) DeepCopy() *ContainerResources {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DBPurge) DeepCopyInto(out *DBPurge) {
 	*out = *in
@@ -195,6 +275,16 @@ func (in *GlanceAPISpec) DeepCopyInto(out *GlanceAPISpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ProxySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KeyManager != nil {
+		in, out := &in.KeyManager, &out.KeyManager
+		*out = new(KeyManagerSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceAPISpec.
@@ -252,6 +342,32 @@ func (in *GlanceAPIStatus) DeepCopyInto(out *GlanceAPIStatus) {
 		*out = new(topologyv1beta1.TopoRef)
 		**out = **in
 	}
+	if in.ConsistencyCheck != nil {
+		in, out := &in.ConsistencyCheck, &out.ConsistencyCheck
+		*out = make(map[string]ConsistencyCheckStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.MissingImageFiles != nil {
+		in, out := &in.MissingImageFiles, &out.MissingImageFiles
+		*out = new(int)
+		**out = **in
+	}
+	if in.StorageCapacity != nil {
+		in, out := &in.StorageCapacity, &out.StorageCapacity
+		*out = make(map[string]ReplicaStorageStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.StorageAutogrow != nil {
+		in, out := &in.StorageAutogrow, &out.StorageAutogrow
+		*out = make(map[string]PVCAutogrowStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceAPIStatus.
@@ -294,16 +410,76 @@ func (in *GlanceAPITemplate) DeepCopyInto(out *GlanceAPITemplate) {
 		copy(*out, *in)
 	}
 	in.Resources.DeepCopyInto(&out.Resources)
+	if in.ContainerResources != nil {
+		in, out := &in.ContainerResources, &out.ContainerResources
+		*out = new(ContainerResources)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Workers != nil {
+		in, out := &in.Workers, &out.Workers
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Processes != nil {
+		in, out := &in.Processes, &out.Processes
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Threads != nil {
+		in, out := &in.Threads, &out.Threads
+		*out = new(int32)
+		**out = **in
+	}
 	if in.NetworkAttachments != nil {
 		in, out := &in.NetworkAttachments, &out.NetworkAttachments
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 	in.Override.DeepCopyInto(&out.Override)
-	out.Storage = in.Storage
+	in.Storage.DeepCopyInto(&out.Storage)
 	in.TLS.DeepCopyInto(&out.TLS)
 	out.Auth = in.Auth
-	out.ImageCache = in.ImageCache
+	in.ImageCache.DeepCopyInto(&out.ImageCache)
+	if in.ImageConversion != nil {
+		in, out := &in.ImageConversion, &out.ImageConversion
+		*out = new(ImageConversion)
+		**out = **in
+	}
+	if in.Staging != nil {
+		in, out := &in.Staging, &out.Staging
+		*out = new(Staging)
+		**out = **in
+	}
+	if in.ImportFiltering != nil {
+		in, out := &in.ImportFiltering, &out.ImportFiltering
+		*out = new(ImportFiltering)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PropertyProtections != nil {
+		in, out := &in.PropertyProtections, &out.PropertyProtections
+		*out = new(PropertyProtections)
+		**out = **in
+	}
+	if in.Logging != nil {
+		in, out := &in.Logging, &out.Logging
+		*out = new(LoggingSpec)
+		**out = **in
+	}
+	if in.Audit != nil {
+		in, out := &in.Audit, &out.Audit
+		*out = new(AuditSpec)
+		**out = **in
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(MetricsSpec)
+		**out = **in
+	}
+	if in.ConsistencyCheck != nil {
+		in, out := &in.ConsistencyCheck, &out.ConsistencyCheck
+		*out = new(ConsistencyCheck)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceAPITemplate.
@@ -317,68 +493,58 @@ func (in *GlanceAPITemplate) DeepCopy() *GlanceAPITemplate {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GlanceDefaults) DeepCopyInto(out *GlanceDefaults) {
+func (in *GlanceBackup) DeepCopyInto(out *GlanceBackup) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceDefaults.
-func (in *GlanceDefaults) DeepCopy() *GlanceDefaults {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceBackup.
+func (in *GlanceBackup) DeepCopy() *GlanceBackup {
 	if in == nil {
 		return nil
 	}
-	out := new(GlanceDefaults)
+	out := new(GlanceBackup)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GlanceExtraVolMounts) DeepCopyInto(out *GlanceExtraVolMounts) {
-	*out = *in
-	if in.VolMounts != nil {
-		in, out := &in.VolMounts, &out.VolMounts
-		*out = make([]storage.VolMounts, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceExtraVolMounts.
-func (in *GlanceExtraVolMounts) DeepCopy() *GlanceExtraVolMounts {
-	if in == nil {
-		return nil
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GlanceBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
-	out := new(GlanceExtraVolMounts)
-	in.DeepCopyInto(out)
-	return out
+	return nil
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GlanceList) DeepCopyInto(out *GlanceList) {
+func (in *GlanceBackupList) DeepCopyInto(out *GlanceBackupList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Glance, len(*in))
+		*out = make([]GlanceBackup, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceList.
-func (in *GlanceList) DeepCopy() *GlanceList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceBackupList.
+func (in *GlanceBackupList) DeepCopy() *GlanceBackupList {
 	if in == nil {
 		return nil
 	}
-	out := new(GlanceList)
+	out := new(GlanceBackupList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GlanceList) DeepCopyObject() runtime.Object {
+func (in *GlanceBackupList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -386,177 +552,1023 @@ func (in *GlanceList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GlanceSpec) DeepCopyInto(out *GlanceSpec) {
+func (in *GlanceBackupSpec) DeepCopyInto(out *GlanceBackupSpec) {
 	*out = *in
-	in.GlanceSpecCore.DeepCopyInto(&out.GlanceSpecCore)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceSpec.
-func (in *GlanceSpec) DeepCopy() *GlanceSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceBackupSpec.
+func (in *GlanceBackupSpec) DeepCopy() *GlanceBackupSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GlanceSpec)
+	out := new(GlanceBackupSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GlanceSpecCore) DeepCopyInto(out *GlanceSpecCore) {
+func (in *GlanceBackupStatus) DeepCopyInto(out *GlanceBackupStatus) {
 	*out = *in
-	out.PasswordSelectors = in.PasswordSelectors
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = new(map[string]string)
-		if **in != nil {
-			in, out := *in, *out
-			*out = make(map[string]string, len(*in))
-			for key, val := range *in {
-				(*out)[key] = val
-			}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(condition.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.TopologyRef != nil {
-		in, out := &in.TopologyRef, &out.TopologyRef
-		*out = new(topologyv1beta1.TopoRef)
-		**out = **in
-	}
-	if in.CustomServiceConfigSecrets != nil {
-		in, out := &in.CustomServiceConfigSecrets, &out.CustomServiceConfigSecrets
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	out.Storage = in.Storage
-	if in.GlanceAPIs != nil {
-		in, out := &in.GlanceAPIs, &out.GlanceAPIs
-		*out = make(map[string]GlanceAPITemplate, len(*in))
+	if in.Hash != nil {
+		in, out := &in.Hash, &out.Hash
+		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
+			(*out)[key] = val
 		}
 	}
-	if in.ExtraMounts != nil {
-		in, out := &in.ExtraMounts, &out.ExtraMounts
-		*out = make([]GlanceExtraVolMounts, len(*in))
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]GlanceBackupVolume, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.Quotas = in.Quotas
-	out.ImageCache = in.ImageCache
-	out.DBPurge = in.DBPurge
-	if in.NotificationBusInstance != nil {
-		in, out := &in.NotificationBusInstance, &out.NotificationBusInstance
-		*out = new(string)
-		**out = **in
-	}
-	if in.NotificationsBus != nil {
-		in, out := &in.NotificationsBus, &out.NotificationsBus
-		*out = new(rabbitmqv1beta1.RabbitMqConfig)
-		**out = **in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceSpecCore.
-func (in *GlanceSpecCore) DeepCopy() *GlanceSpecCore {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceBackupStatus.
+func (in *GlanceBackupStatus) DeepCopy() *GlanceBackupStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(GlanceSpecCore)
+	out := new(GlanceBackupStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GlanceStatus) DeepCopyInto(out *GlanceStatus) {
+func (in *GlanceBackupVolume) DeepCopyInto(out *GlanceBackupVolume) {
 	*out = *in
-	if in.Hash != nil {
-		in, out := &in.Hash, &out.Hash
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.APIEndpoints != nil {
-		in, out := &in.APIEndpoints, &out.APIEndpoints
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make(condition.Conditions, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.GlanceAPIReadyCounts != nil {
-		in, out := &in.GlanceAPIReadyCounts, &out.GlanceAPIReadyCounts
-		*out = make(map[string]int32, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceStatus.
-func (in *GlanceStatus) DeepCopy() *GlanceStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceBackupVolume.
+func (in *GlanceBackupVolume) DeepCopy() *GlanceBackupVolume {
 	if in == nil {
 		return nil
 	}
-	out := new(GlanceStatus)
+	out := new(GlanceBackupVolume)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ImageCache) DeepCopyInto(out *ImageCache) {
+func (in *GlanceDefaults) DeepCopyInto(out *GlanceDefaults) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageCache.
-func (in *ImageCache) DeepCopy() *ImageCache {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceDefaults.
+func (in *GlanceDefaults) DeepCopy() *GlanceDefaults {
 	if in == nil {
 		return nil
 	}
-	out := new(ImageCache)
+	out := new(GlanceDefaults)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PasswordSelector) DeepCopyInto(out *PasswordSelector) {
+func (in *GlanceExtraVolMounts) DeepCopyInto(out *GlanceExtraVolMounts) {
 	*out = *in
+	if in.VolMounts != nil {
+		in, out := &in.VolMounts, &out.VolMounts
+		*out = make([]storage.VolMounts, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordSelector.
-func (in *PasswordSelector) DeepCopy() *PasswordSelector {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceExtraVolMounts.
+func (in *GlanceExtraVolMounts) DeepCopy() *GlanceExtraVolMounts {
 	if in == nil {
 		return nil
 	}
-	out := new(PasswordSelector)
+	out := new(GlanceExtraVolMounts)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QuotaLimits) DeepCopyInto(out *QuotaLimits) {
+func (in *GlanceImage) DeepCopyInto(out *GlanceImage) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaLimits.
-func (in *QuotaLimits) DeepCopy() *QuotaLimits {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceImage.
+func (in *GlanceImage) DeepCopy() *GlanceImage {
 	if in == nil {
 		return nil
 	}
-	out := new(QuotaLimits)
+	out := new(GlanceImage)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GlanceImage) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Storage) DeepCopyInto(out *Storage) {
+func (in *GlanceImageList) DeepCopyInto(out *GlanceImageList) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GlanceImage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceImageList.
+func (in *GlanceImageList) DeepCopy() *GlanceImageList {
+	if in == nil {
+		return nil
+	}
+	out := new(GlanceImageList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GlanceImageList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlanceImageMigration) DeepCopyInto(out *GlanceImageMigration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceImageMigration.
+func (in *GlanceImageMigration) DeepCopy() *GlanceImageMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(GlanceImageMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GlanceImageMigration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlanceImageMigrationList) DeepCopyInto(out *GlanceImageMigrationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GlanceImageMigration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceImageMigrationList.
+func (in *GlanceImageMigrationList) DeepCopy() *GlanceImageMigrationList {
+	if in == nil {
+		return nil
+	}
+	out := new(GlanceImageMigrationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GlanceImageMigrationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlanceImageMigrationSpec) DeepCopyInto(out *GlanceImageMigrationSpec) {
+	*out = *in
+	if in.ImageFilter != nil {
+		in, out := &in.ImageFilter, &out.ImageFilter
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceImageMigrationSpec.
+func (in *GlanceImageMigrationSpec) DeepCopy() *GlanceImageMigrationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GlanceImageMigrationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlanceImageMigrationStatus) DeepCopyInto(out *GlanceImageMigrationStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(condition.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hash != nil {
+		in, out := &in.Hash, &out.Hash
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.FailedImages != nil {
+		in, out := &in.FailedImages, &out.FailedImages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceImageMigrationStatus.
+func (in *GlanceImageMigrationStatus) DeepCopy() *GlanceImageMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GlanceImageMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlanceImagePVCSource) DeepCopyInto(out *GlanceImagePVCSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceImagePVCSource.
+func (in *GlanceImagePVCSource) DeepCopy() *GlanceImagePVCSource {
+	if in == nil {
+		return nil
+	}
+	out := new(GlanceImagePVCSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlanceImageSource) DeepCopyInto(out *GlanceImageSource) {
+	*out = *in
+	if in.PVC != nil {
+		in, out := &in.PVC, &out.PVC
+		*out = new(GlanceImagePVCSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceImageSource.
+func (in *GlanceImageSource) DeepCopy() *GlanceImageSource {
+	if in == nil {
+		return nil
+	}
+	out := new(GlanceImageSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlanceImageSpec) DeepCopyInto(out *GlanceImageSpec) {
+	*out = *in
+	in.Source.DeepCopyInto(&out.Source)
+	if in.Properties != nil {
+		in, out := &in.Properties, &out.Properties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Stores != nil {
+		in, out := &in.Stores, &out.Stores
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceImageSpec.
+func (in *GlanceImageSpec) DeepCopy() *GlanceImageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GlanceImageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlanceImageStatus) DeepCopyInto(out *GlanceImageStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(condition.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hash != nil {
+		in, out := &in.Hash, &out.Hash
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Stores != nil {
+		in, out := &in.Stores, &out.Stores
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceImageStatus.
+func (in *GlanceImageStatus) DeepCopy() *GlanceImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GlanceImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlanceList) DeepCopyInto(out *GlanceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Glance, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceList.
+func (in *GlanceList) DeepCopy() *GlanceList {
+	if in == nil {
+		return nil
+	}
+	out := new(GlanceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GlanceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlanceRestore) DeepCopyInto(out *GlanceRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceRestore.
+func (in *GlanceRestore) DeepCopy() *GlanceRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(GlanceRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GlanceRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlanceRestoreList) DeepCopyInto(out *GlanceRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GlanceRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceRestoreList.
+func (in *GlanceRestoreList) DeepCopy() *GlanceRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(GlanceRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GlanceRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlanceRestoreSpec) DeepCopyInto(out *GlanceRestoreSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceRestoreSpec.
+func (in *GlanceRestoreSpec) DeepCopy() *GlanceRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GlanceRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlanceRestoreStatus) DeepCopyInto(out *GlanceRestoreStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(condition.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hash != nil {
+		in, out := &in.Hash, &out.Hash
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RestoredPVCs != nil {
+		in, out := &in.RestoredPVCs, &out.RestoredPVCs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceRestoreStatus.
+func (in *GlanceRestoreStatus) DeepCopy() *GlanceRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GlanceRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlanceSpec) DeepCopyInto(out *GlanceSpec) {
+	*out = *in
+	in.GlanceSpecCore.DeepCopyInto(&out.GlanceSpecCore)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceSpec.
+func (in *GlanceSpec) DeepCopy() *GlanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GlanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlanceSpecCore) DeepCopyInto(out *GlanceSpecCore) {
+	*out = *in
+	out.PasswordSelectors = in.PasswordSelectors
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(map[string]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make(map[string]string, len(*in))
+			for key, val := range *in {
+				(*out)[key] = val
+			}
+		}
+	}
+	if in.TopologyRef != nil {
+		in, out := &in.TopologyRef, &out.TopologyRef
+		*out = new(topologyv1beta1.TopoRef)
+		**out = **in
+	}
+	if in.CustomServiceConfigSecrets != nil {
+		in, out := &in.CustomServiceConfigSecrets, &out.CustomServiceConfigSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Storage.DeepCopyInto(&out.Storage)
+	if in.GlanceAPIs != nil {
+		in, out := &in.GlanceAPIs, &out.GlanceAPIs
+		*out = make(map[string]GlanceAPITemplate, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.ExtraMounts != nil {
+		in, out := &in.ExtraMounts, &out.ExtraMounts
+		*out = make([]GlanceExtraVolMounts, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.Quotas = in.Quotas
+	in.ImageCache.DeepCopyInto(&out.ImageCache)
+	out.DBPurge = in.DBPurge
+	if in.ImageCleanup != nil {
+		in, out := &in.ImageCleanup, &out.ImageCleanup
+		*out = new(ImageCleanup)
+		**out = **in
+	}
+	if in.NotificationBusInstance != nil {
+		in, out := &in.NotificationBusInstance, &out.NotificationBusInstance
+		*out = new(string)
+		**out = **in
+	}
+	if in.NotificationsBus != nil {
+		in, out := &in.NotificationsBus, &out.NotificationsBus
+		*out = new(rabbitmqv1beta1.RabbitMqConfig)
+		**out = **in
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ProxySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KeyManager != nil {
+		in, out := &in.KeyManager, &out.KeyManager
+		*out = new(KeyManagerSpec)
+		**out = **in
+	}
+	if in.Metadefs != nil {
+		in, out := &in.Metadefs, &out.Metadefs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceSpecCore.
+func (in *GlanceSpecCore) DeepCopy() *GlanceSpecCore {
+	if in == nil {
+		return nil
+	}
+	out := new(GlanceSpecCore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlanceStatus) DeepCopyInto(out *GlanceStatus) {
+	*out = *in
+	if in.Hash != nil {
+		in, out := &in.Hash, &out.Hash
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.APIEndpoints != nil {
+		in, out := &in.APIEndpoints, &out.APIEndpoints
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(condition.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GlanceAPIReadyCounts != nil {
+		in, out := &in.GlanceAPIReadyCounts, &out.GlanceAPIReadyCounts
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ImageCleanup != nil {
+		in, out := &in.ImageCleanup, &out.ImageCleanup
+		*out = new(ImageCleanupStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlanceStatus.
+func (in *GlanceStatus) DeepCopy() *GlanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GlanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageCache) DeepCopyInto(out *ImageCache) {
+	*out = *in
+	if in.RetentionPolicy != nil {
+		in, out := &in.RetentionPolicy, &out.RetentionPolicy
+		*out = new(PVCRetentionPolicy)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageCache.
+func (in *ImageCache) DeepCopy() *ImageCache {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageCache)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageCleanup) DeepCopyInto(out *ImageCleanup) {
+	*out = *in
+	out.MaxAge = in.MaxAge
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageCleanup.
+func (in *ImageCleanup) DeepCopy() *ImageCleanup {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageCleanup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageCleanupMaxAge) DeepCopyInto(out *ImageCleanupMaxAge) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageCleanupMaxAge.
+func (in *ImageCleanupMaxAge) DeepCopy() *ImageCleanupMaxAge {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageCleanupMaxAge)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageCleanupStatus) DeepCopyInto(out *ImageCleanupStatus) {
+	*out = *in
+	if in.LastRunTime != nil {
+		in, out := &in.LastRunTime, &out.LastRunTime
+		*out = (*in).DeepCopy()
+	}
+	if in.StaleImageIDs != nil {
+		in, out := &in.StaleImageIDs, &out.StaleImageIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageCleanupStatus.
+func (in *ImageCleanupStatus) DeepCopy() *ImageCleanupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageCleanupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageConversion) DeepCopyInto(out *ImageConversion) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageConversion.
+func (in *ImageConversion) DeepCopy() *ImageConversion {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageConversion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImportFiltering) DeepCopyInto(out *ImportFiltering) {
+	*out = *in
+	if in.AllowedSchemes != nil {
+		in, out := &in.AllowedSchemes, &out.AllowedSchemes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DisallowedSchemes != nil {
+		in, out := &in.DisallowedSchemes, &out.DisallowedSchemes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedHosts != nil {
+		in, out := &in.AllowedHosts, &out.AllowedHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DisallowedHosts != nil {
+		in, out := &in.DisallowedHosts, &out.DisallowedHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedPorts != nil {
+		in, out := &in.AllowedPorts, &out.AllowedPorts
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.DisallowedPorts != nil {
+		in, out := &in.DisallowedPorts, &out.DisallowedPorts
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportFiltering.
+func (in *ImportFiltering) DeepCopy() *ImportFiltering {
+	if in == nil {
+		return nil
+	}
+	out := new(ImportFiltering)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyManagerSpec) DeepCopyInto(out *KeyManagerSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyManagerSpec.
+func (in *KeyManagerSpec) DeepCopy() *KeyManagerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyManagerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingSpec) DeepCopyInto(out *LoggingSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingSpec.
+func (in *LoggingSpec) DeepCopy() *LoggingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsSpec) DeepCopyInto(out *MetricsSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsSpec.
+func (in *MetricsSpec) DeepCopy() *MetricsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCAutogrowStatus) DeepCopyInto(out *PVCAutogrowStatus) {
+	*out = *in
+	in.LastExpansionTime.DeepCopyInto(&out.LastExpansionTime)
+	out.FilesystemCapacity = in.FilesystemCapacity.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCAutogrowStatus.
+func (in *PVCAutogrowStatus) DeepCopy() *PVCAutogrowStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCAutogrowStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCRetentionPolicy) DeepCopyInto(out *PVCRetentionPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCRetentionPolicy.
+func (in *PVCRetentionPolicy) DeepCopy() *PVCRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PasswordSelector) DeepCopyInto(out *PasswordSelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordSelector.
+func (in *PasswordSelector) DeepCopy() *PasswordSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(PasswordSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PropertyProtections) DeepCopyInto(out *PropertyProtections) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PropertyProtections.
+func (in *PropertyProtections) DeepCopy() *PropertyProtections {
+	if in == nil {
+		return nil
+	}
+	out := new(PropertyProtections)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxySpec) DeepCopyInto(out *ProxySpec) {
+	*out = *in
+	if in.NoProxy != nil {
+		in, out := &in.NoProxy, &out.NoProxy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxySpec.
+func (in *ProxySpec) DeepCopy() *ProxySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaLimits) DeepCopyInto(out *QuotaLimits) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaLimits.
+func (in *QuotaLimits) DeepCopy() *QuotaLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaStorageStatus) DeepCopyInto(out *ReplicaStorageStatus) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(VolumeUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cache != nil {
+		in, out := &in.Cache, &out.Cache
+		*out = new(VolumeUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaStorageStatus.
+func (in *ReplicaStorageStatus) DeepCopy() *ReplicaStorageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaStorageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Staging) DeepCopyInto(out *Staging) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Staging.
+func (in *Staging) DeepCopy() *Staging {
+	if in == nil {
+		return nil
+	}
+	out := new(Staging)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Storage) DeepCopyInto(out *Storage) {
+	*out = *in
+	if in.RetentionPolicy != nil {
+		in, out := &in.RetentionPolicy, &out.RetentionPolicy
+		*out = new(PVCRetentionPolicy)
+		**out = **in
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(StorageMonitoring)
+		**out = **in
+	}
+	if in.Autogrow != nil {
+		in, out := &in.Autogrow, &out.Autogrow
+		*out = new(StorageAutogrow)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Storage.
@@ -568,3 +1580,51 @@ func (in *Storage) DeepCopy() *Storage {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageAutogrow) DeepCopyInto(out *StorageAutogrow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageAutogrow.
+func (in *StorageAutogrow) DeepCopy() *StorageAutogrow {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageAutogrow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageMonitoring) DeepCopyInto(out *StorageMonitoring) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageMonitoring.
+func (in *StorageMonitoring) DeepCopy() *StorageMonitoring {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageMonitoring)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeUsage) DeepCopyInto(out *VolumeUsage) {
+	*out = *in
+	out.Capacity = in.Capacity.DeepCopy()
+	out.Used = in.Used.DeepCopy()
+	out.Available = in.Available.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeUsage.
+func (in *VolumeUsage) DeepCopy() *VolumeUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeUsage)
+	in.DeepCopyInto(out)
+	return out
+}
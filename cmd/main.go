@@ -257,21 +257,61 @@ func main() {
 	}
 
 	if err := (&controller.GlanceAPIReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		Kclient: kclient,
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Kclient:  kclient,
+		Recorder: mgr.GetEventRecorderFor("glanceapi-controller"),
 	}).SetupWithManager(context.Background(), mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GlanceAPI")
 		os.Exit(1)
 	}
 	if err := (&controller.GlanceReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		Kclient: kclient,
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Kclient:  kclient,
+		Recorder: mgr.GetEventRecorderFor("glance-controller"),
 	}).SetupWithManager(context.Background(), mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Glance")
 		os.Exit(1)
 	}
+	if err := (&controller.GlanceBackupReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Kclient:  kclient,
+		Recorder: mgr.GetEventRecorderFor("glancebackup-controller"),
+	}).SetupWithManager(context.Background(), mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GlanceBackup")
+		os.Exit(1)
+	}
+	if err := (&controller.GlanceRestoreReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Kclient:  kclient,
+		Recorder: mgr.GetEventRecorderFor("glancerestore-controller"),
+	}).SetupWithManager(context.Background(), mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GlanceRestore")
+		os.Exit(1)
+	}
+	if err := (&controller.GlanceImageMigrationReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Kclient:  kclient,
+		Recorder: mgr.GetEventRecorderFor("glanceimagemigration-controller"),
+	}).SetupWithManager(context.Background(), mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GlanceImageMigration")
+		os.Exit(1)
+	}
+	if err := (&controller.GlanceImageReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Kclient:  kclient,
+		Recorder: mgr.GetEventRecorderFor("glanceimage-controller"),
+	}).SetupWithManager(context.Background(), mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GlanceImage")
+		os.Exit(1)
+	}
+
+	controller.RegisterMetrics(mgr.GetClient())
 
 	glancev1.SetupDefaults()
 	// nolint:goconst
@@ -21,6 +21,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	networkv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
@@ -40,6 +41,7 @@ import (
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -51,8 +53,41 @@ var (
 	ErrACSecretNotFound        = errors.New("ApplicationCredential secret not found")
 	ErrACSecretMissingKeys     = errors.New("ApplicationCredential secret missing required keys")
 	ErrInvalidBackend          = errors.New(glancev1.InvalidBackendErrorMessageSingle)
+	ErrVolumeSnapshot          = errors.New("VolumeSnapshot failed")
+	ErrRestorePVCExists        = errors.New("PVC already exists")
 )
 
+// Reasons of the Events emitted on disruptive operations
+const (
+	// eventReasonStatefulSetRecreated - a GlanceAPI StatefulSet has been
+	// deleted to be recreated with a new backend configuration
+	eventReasonStatefulSetRecreated = "StatefulSetRecreated"
+	// eventReasonGlanceAPIDeleted - a GlanceAPI removed from the Glance spec
+	// has been deleted
+	eventReasonGlanceAPIDeleted = "GlanceAPIDeleted"
+	// eventReasonKeystoneEndpointMoved - the keystoneEndpoint selector now
+	// points to a different GlanceAPI
+	eventReasonKeystoneEndpointMoved = "KeystoneEndpointMoved"
+	// eventReasonACSecretReleased - the consumer finalizer has been removed
+	// from a previous ApplicationCredential Secret
+	eventReasonACSecretReleased = "ApplicationCredentialReleased"
+	// eventReasonPVCDeleted - a GlanceAPI PVC has been deleted according to
+	// its retention policy
+	eventReasonPVCDeleted = "PVCDeleted"
+	// eventReasonPVCRestored - a GlanceAPI PVC has been recreated from a
+	// GlanceBackup VolumeSnapshot
+	eventReasonPVCRestored = "PVCRestored"
+	// eventReasonPVCExpanded - a glance PVC has been expanded by the
+	// autogrow policy
+	eventReasonPVCExpanded = "PVCExpanded"
+	// eventReasonPVCAutogrowLimitReached - a glance PVC crossed the autogrow
+	// threshold but it already has the maximum size
+	eventReasonPVCAutogrowLimitReached = "PVCAutogrowLimitReached"
+)
+
+// monitoringGroupVersion - API group providing the ServiceMonitor kind
+const monitoringGroupVersion = "monitoring.coreos.com/v1"
+
 // fields to index to reconcile when change
 const (
 	passwordSecretField        = ".spec.secret"
@@ -62,6 +97,9 @@ const (
 	topologyField              = ".spec.topologyRef.Name"
 	notificationBusSecretField = ".spec.notificationBusSecret"
 	authAppCredSecretField     = ".spec.auth.applicationCredentialSecret" // #nosec G101
+	keyManagerLocalKeyField    = ".spec.keyManager.localKeySecret"
+	propertyProtectionsField   = ".spec.propertyProtections.configMap"
+	metadefsField              = ".spec.metadefs"
 )
 
 var (
@@ -76,6 +114,7 @@ var (
 		topologyField,
 		notificationBusSecretField,
 		authAppCredSecretField,
+		keyManagerLocalKeyField,
 	}
 )
 
@@ -329,3 +368,69 @@ func GetServiceLabels(
 		common.OwnerSelector:     instance.Name,
 	}
 }
+
+// GetKeystoneGlanceAPI - returns the GlanceAPI of a Glance registered in the
+// keystone catalog, nil when it does not exist. With the split layout the
+// internal instance is returned
+func GetKeystoneGlanceAPI(
+	ctx context.Context,
+	c client.Client,
+	glanceInstance *glancev1.Glance,
+) (*glancev1.GlanceAPI, error) {
+	apis := &glancev1.GlanceAPIList{}
+	if err := c.List(ctx, apis, client.InNamespace(glanceInstance.Namespace)); err != nil {
+		return nil, fmt.Errorf("listing GlanceAPIs: %w", err)
+	}
+	for i := range apis.Items {
+		api := &apis.Items[i]
+		if glance.GetOwningGlanceName(api) != glanceInstance.Name ||
+			api.APIName() != glanceInstance.Spec.KeystoneEndpoint ||
+			api.Spec.APIType == glancev1.APIExternal {
+			continue
+		}
+		return api, nil
+	}
+	return nil, nil
+}
+
+// getEnabledStores - returns the store_id of the backends enabled in a
+// GlanceAPI
+func getEnabledStores(api *glancev1.GlanceAPI) []string {
+	stores := []string{}
+	for _, backend := range glancev1.GetEnabledBackends(api.Spec.CustomServiceConfig) {
+		stores = append(stores, strings.TrimSpace(strings.SplitN(backend, ":", 2)[0]))
+	}
+	return stores
+}
+
+// latestTerminationMessage - returns the terminated state of the most recent
+// Pod matching selector that left a termination message, nil if none did.
+// The Jobs reporting a progress write a JSON summary in their termination
+// message
+func latestTerminationMessage(
+	ctx context.Context,
+	kclient kubernetes.Interface,
+	namespace string,
+	selector string,
+) (*corev1.ContainerStateTerminated, error) {
+	pods, err := kclient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing Pods %s: %w", selector, err)
+	}
+	var latest *corev1.ContainerStateTerminated
+	var latestCreation metav1.Time
+	for _, p := range pods.Items {
+		for _, cs := range p.Status.ContainerStatuses {
+			if cs.State.Terminated == nil || cs.State.Terminated.Message == "" {
+				continue
+			}
+			if latest == nil || latestCreation.Before(&p.CreationTimestamp) {
+				latest = cs.State.Terminated
+				latestCreation = p.CreationTimestamp
+			}
+		}
+	}
+	return latest, nil
+}
@@ -18,10 +18,12 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"maps"
 	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/gophercloud/gophercloud/v2"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/tls"
@@ -31,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -43,12 +46,14 @@ import (
 	"github.com/go-logr/logr"
 	glancev1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
 	"github.com/openstack-k8s-operators/glance-operator/internal/glance"
+	"github.com/openstack-k8s-operators/glance-operator/internal/glanceapi"
 	memcachedv1 "github.com/openstack-k8s-operators/infra-operator/apis/memcached/v1beta1"
 	rabbitmqv1 "github.com/openstack-k8s-operators/infra-operator/apis/rabbitmq/v1beta1"
 	keystonev1 "github.com/openstack-k8s-operators/keystone-operator/api/v1beta1"
 	"github.com/openstack-k8s-operators/lib-common/modules/common"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/annotations"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/configmap"
 	cronjob "github.com/openstack-k8s-operators/lib-common/modules/common/cronjob"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/endpoint"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
@@ -69,8 +74,9 @@ import (
 // GlanceReconciler reconciles a Glance object
 type GlanceReconciler struct {
 	client.Client
-	Kclient kubernetes.Interface
-	Scheme  *runtime.Scheme
+	Kclient  kubernetes.Interface
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 // GetLogger returns a logger object with a prefix of "controller.name" and additional controller context fields
@@ -86,6 +92,9 @@ func (r *GlanceReconciler) GetLogger(ctx context.Context) logr.Logger {
 // +kubebuilder:rbac:groups=glance.openstack.org,resources=glanceapis/finalizers,verbs=update;patch
 // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;create;update;delete;watch;patch
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete;
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=mariadb.openstack.org,resources=mariadbdatabases,verbs=get;list;watch;create;update;patch;delete;
 // +kubebuilder:rbac:groups=mariadb.openstack.org,resources=mariadbaccounts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=mariadb.openstack.org,resources=mariadbaccounts/finalizers,verbs=update;patch
@@ -196,6 +205,15 @@ func (r *GlanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res
 		cl.Set(c)
 	}
 
+	// Add Metadefs condition if custom metadefs are provided
+	if len(instance.Spec.Metadefs) > 0 {
+		c := condition.UnknownCondition(
+			glancev1.MetadefsReadyCondition,
+			condition.InitReason,
+			glancev1.MetadefsReadyInitMessage)
+		cl.Set(c)
+	}
+
 	instance.Status.Conditions.Init(&cl)
 	instance.Status.ObservedGeneration = instance.Generation
 
@@ -239,6 +257,15 @@ func (r *GlanceReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manage
 		return err
 	}
 
+	// index metadefsField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &glancev1.Glance{}, metadefsField, func(rawObj client.Object) []string {
+		// Extract the metadefs ConfigMap names from the spec, if any is provided
+		cr := rawObj.(*glancev1.Glance)
+		return cr.Spec.Metadefs
+	}); err != nil {
+		return err
+	}
+
 	memcachedFn := func(_ context.Context, o client.Object) []reconcile.Request {
 		result := []reconcile.Request{}
 
@@ -324,6 +351,11 @@ func (r *GlanceReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manage
 			handler.EnqueueRequestsFromMapFunc(r.findObjectsForSrc),
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
 		).
+		Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForMetadefs),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
 		Watches(&memcachedv1.Memcached{},
 			handler.EnqueueRequestsFromMapFunc(memcachedFn)).
 		Complete(r)
@@ -363,6 +395,40 @@ func (r *GlanceReconciler) findObjectsForSrc(ctx context.Context, src client.Obj
 	return requests
 }
 
+// findObjectsForMetadefs - returns the Glances referencing the ConfigMap as
+// Metadefs
+func (r *GlanceReconciler) findObjectsForMetadefs(ctx context.Context, src client.Object) []reconcile.Request {
+	requests := []reconcile.Request{}
+
+	Log := r.GetLogger(ctx)
+
+	crList := &glancev1.GlanceList{}
+	listOps := &client.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector(metadefsField, src.GetName()),
+		Namespace:     src.GetNamespace(),
+	}
+	err := r.List(ctx, crList, listOps)
+	if err != nil {
+		Log.Error(err, fmt.Sprintf("listing %s for field: %s - %s", crList.GroupVersionKind().Kind, metadefsField, src.GetNamespace()))
+		return requests
+	}
+
+	for _, item := range crList.Items {
+		Log.Info(fmt.Sprintf("input source %s changed, reconcile: %s - %s", src.GetName(), item.GetName(), item.GetNamespace()))
+
+		requests = append(requests,
+			reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      item.GetName(),
+					Namespace: item.GetNamespace(),
+				},
+			},
+		)
+	}
+
+	return requests
+}
+
 func (r *GlanceReconciler) reconcileDelete(ctx context.Context, instance *glancev1.Glance, helper *helper.Helper) (ctrl.Result, error) {
 	Log := r.GetLogger(ctx)
 	Log.Info(fmt.Sprintf("Reconciling Service '%s' delete", instance.Name))
@@ -419,6 +485,8 @@ func (r *GlanceReconciler) reconcileDelete(ctx context.Context, instance *glance
 		}
 	}
 
+	registeredLimitsSyncErrorsTotal.DeleteLabelValues(instance.Namespace, instance.Name)
+
 	// Service is deleted so remove the finalizer.
 	controllerutil.RemoveFinalizer(instance, helper.GetFinalizer())
 	Log.Info(fmt.Sprintf("Reconciled Service '%s' delete successfully", instance.Name))
@@ -544,6 +612,14 @@ func (r *GlanceReconciler) reconcileInit(
 	instance.Status.Conditions.MarkTrue(condition.DBSyncReadyCondition, condition.DBSyncReadyMessage)
 	// run Glance db sync - end
 
+	//
+	// load the custom metadefs
+	//
+	ctrlResult, err = r.ensureMetadefs(ctx, helper, instance, serviceLabels, serviceAnnotations)
+	if (err != nil || ctrlResult != ctrl.Result{}) {
+		return ctrlResult, err
+	}
+
 	// when job passed, mark NetworkAttachmentsReadyCondition ready, because we
 	// pass NADs as serviceAnnotation to glance-dbsync job
 	instance.Status.Conditions.MarkTrue(condition.NetworkAttachmentsReadyCondition, condition.NetworkAttachmentsReadyMessage)
@@ -776,6 +852,19 @@ func (r *GlanceReconciler) reconcileNormal(ctx context.Context, instance *glance
 			err.Error()))
 		return ctrlResult, err
 	}
+
+	// ImageCleanup is optional and removes the images stuck in a non-active
+	// state, as well as the orphaned files of the shared staging areas
+	ctrlResult, err = r.ensureImageCleanupJob(ctx, helper, instance, serviceLabels, serviceAnnotations)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.CronJobReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.CronJobReadyErrorMessage,
+			err.Error()))
+		return ctrlResult, err
+	}
 	instance.Status.Conditions.MarkTrue(condition.CronJobReadyCondition, condition.CronJobReadyMessage)
 	// create CronJob - end
 
@@ -989,6 +1078,8 @@ func (r *GlanceReconciler) apiDeploymentCreateOrUpdate(
 		Quota:                 instance.IsQuotaEnabled(),
 		NotificationBusSecret: instance.Status.NotificationBusSecret,
 		MemcachedInstance:     instance.Spec.MemcachedInstance,
+		Proxy:                 instance.Spec.Proxy,
+		KeyManager:            instance.Spec.KeyManager,
 	}
 
 	if apiSpec.NodeSelector == nil {
@@ -999,6 +1090,15 @@ func (r *GlanceReconciler) apiDeploymentCreateOrUpdate(
 	if apiSpec.ImageCache.Size == "" {
 		apiSpec.ImageCache.Size = instance.Spec.ImageCache.Size
 	}
+	if apiSpec.ImageCache.RetentionPolicy == nil {
+		apiSpec.ImageCache.RetentionPolicy = instance.Spec.ImageCache.RetentionPolicy
+	}
+	if apiSpec.ImageCache.VolumeType == "" {
+		apiSpec.ImageCache.VolumeType = instance.Spec.ImageCache.VolumeType
+	}
+	if apiSpec.ImageCache.StorageClass == "" {
+		apiSpec.ImageCache.StorageClass = instance.Spec.ImageCache.StorageClass
+	}
 
 	// Inherit the values required for PVC creation from the top-level CR
 	if apiSpec.Storage.StorageRequest == "" {
@@ -1010,6 +1110,15 @@ func (r *GlanceReconciler) apiDeploymentCreateOrUpdate(
 	if !apiSpec.Storage.External {
 		apiSpec.Storage.External = instance.Spec.Storage.External
 	}
+	if apiSpec.Storage.RetentionPolicy == nil {
+		apiSpec.Storage.RetentionPolicy = instance.Spec.Storage.RetentionPolicy
+	}
+	if apiSpec.Storage.Monitoring == nil {
+		apiSpec.Storage.Monitoring = instance.Spec.Storage.Monitoring
+	}
+	if apiSpec.Storage.Autogrow == nil {
+		apiSpec.Storage.Autogrow = instance.Spec.Storage.Autogrow
+	}
 
 	// Make sure to inject the ContainerImage passed by the OpenStackVersions
 	// resource to all the underlying instances and rollout a new StatefulSet
@@ -1046,6 +1155,12 @@ func (r *GlanceReconciler) apiDeploymentCreateOrUpdate(
 	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, glanceStatefulset, func() error {
 		// Assign the created spec containing both field provided via GlanceAPITemplate
 		// and what is inherited from the top-level CR (ExtraMounts)
+		if !glanceStatefulset.CreationTimestamp.IsZero() &&
+			apiAnnotations[glance.KeystoneEndpoint] == "true" &&
+			glanceStatefulset.Annotations[glance.KeystoneEndpoint] != "true" {
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, eventReasonKeystoneEndpointMoved,
+				"KeystoneEndpoint moved to GlanceAPI %s", glanceStatefulset.Name)
+		}
 		glanceStatefulset.Annotations = apiAnnotations
 		glanceStatefulset.Spec = apiSpec
 
@@ -1067,6 +1182,7 @@ func (r *GlanceReconciler) apiDeploymentCreateOrUpdate(
 		if instance.IsQuotaEnabled() {
 			err := r.ensureRegisteredLimits(ctx, helper, instance, instance.GetQuotaLimits())
 			if err != nil {
+				registeredLimitsSyncErrorsTotal.WithLabelValues(instance.Namespace, instance.Name).Inc()
 				return err
 			}
 		}
@@ -1175,6 +1291,88 @@ func (r *GlanceReconciler) ensureRegisteredLimits(
 	return nil
 }
 
+// ensureMetadefs - runs the Job that loads the metadefs provided by the
+// ConfigMaps referenced in the spec. The Job is executed again when the
+// content of any ConfigMap changes
+func (r *GlanceReconciler) ensureMetadefs(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *glancev1.Glance,
+	serviceLabels map[string]string,
+	serviceAnnotations map[string]string,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+	if len(instance.Spec.Metadefs) == 0 {
+		// Loaded metadefs are not removed from the database: just stop
+		// tracking them
+		delete(instance.Status.Hash, glancev1.MetadefsHash)
+		instance.Status.Conditions.Remove(glancev1.MetadefsReadyCondition)
+		return ctrl.Result{}, nil
+	}
+
+	cmVars := make(map[string]env.Setter)
+	for _, cmName := range instance.Spec.Metadefs {
+		_, hash, err := configmap.GetConfigMapAndHashWithName(ctx, h, cmName, instance.Namespace)
+		if err != nil {
+			if k8s_errors.IsNotFound(err) {
+				Log.Info(fmt.Sprintf("Metadefs ConfigMap %s not found", cmName))
+				instance.Status.Conditions.Set(condition.FalseCondition(
+					glancev1.MetadefsReadyCondition,
+					condition.ErrorReason,
+					condition.SeverityWarning,
+					glancev1.MetadefsWaitingMessage,
+					cmName))
+				return glance.ResultRequeue, nil
+			}
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				glancev1.MetadefsReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				glancev1.MetadefsReadyErrorMessage,
+				err.Error()))
+			return ctrl.Result{}, err
+		}
+		cmVars[cmName] = env.SetValue(hash)
+	}
+	metadefsHash, err := util.ObjectHash(env.MergeEnvs([]corev1.EnvVar{}, cmVars))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	jobDef := glance.MetadefsJob(instance, serviceLabels, serviceAnnotations, metadefsHash)
+	metadefsJob := job.NewJob(
+		jobDef,
+		glancev1.MetadefsHash,
+		instance.Spec.PreserveJobs,
+		glance.ShortDuration,
+		instance.Status.Hash[glancev1.MetadefsHash],
+	)
+	ctrlResult, err := metadefsJob.DoJob(ctx, h)
+	if (ctrlResult != ctrl.Result{}) {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.MetadefsReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			glancev1.MetadefsReadyRunningMessage))
+		return ctrlResult, nil
+	}
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.MetadefsReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			glancev1.MetadefsReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	if metadefsJob.HasChanged() {
+		instance.Status.Hash[glancev1.MetadefsHash] = metadefsJob.GetHash()
+		Log.Info(fmt.Sprintf("Service '%s' - Job %s hash added - %s", instance.Name, jobDef.Name, instance.Status.Hash[glancev1.MetadefsHash]))
+	}
+	instance.Status.Conditions.MarkTrue(glancev1.MetadefsReadyCondition, glancev1.MetadefsReadyMessage)
+	return ctrl.Result{}, nil
+}
+
 // ensureCronJobs - Create the required CronJobs to clean DB entries and image-cache
 // if enabled
 func (r *GlanceReconciler) ensureDBPurgeJob(
@@ -1212,6 +1410,201 @@ func (r *GlanceReconciler) ensureDBPurgeJob(
 	return ctrlResult, err
 }
 
+// ensureImageCleanupJob - Create the CronJob removing the stale images when
+// the ImageCleanup policy is set, and delete it otherwise. The summary of the
+// last run is reported in the Glance status
+func (r *GlanceReconciler) ensureImageCleanupJob(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *glancev1.Glance,
+	serviceLabels map[string]string,
+	serviceAnnotations map[string]string,
+) (ctrl.Result, error) {
+	cronJobName := fmt.Sprintf("%s-%s", instance.Name, glance.ImageCleanup)
+
+	ctrlResult, stagingCronJobs, err := r.ensureStagingCleanupJobs(ctx, h, instance, serviceLabels, serviceAnnotations)
+	if err != nil {
+		return ctrlResult, err
+	}
+
+	if instance.Spec.ImageCleanup == nil {
+		instance.Status.ImageCleanup = nil
+		var cronJob batchv1.CronJob
+		err := r.Get(ctx, types.NamespacedName{Name: cronJobName, Namespace: instance.Namespace}, &cronJob)
+		if err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		err = r.Delete(ctx, &cronJob, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// The images are removed through the GlanceAPI registered in keystone:
+	// the CronJob is created once it exists
+	keystoneAPI, err := GetKeystoneGlanceAPI(ctx, r.Client, instance)
+	if err != nil || keystoneAPI == nil {
+		return ctrl.Result{}, err
+	}
+
+	// Only the shared staging areas can be mounted by the CronJob: a per
+	// replica staging PVC is bound to the GlanceAPI Pod
+	apis := &glancev1.GlanceAPIList{}
+	if err = r.List(ctx, apis, client.InNamespace(instance.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+	stagingPVCs := []string{}
+	for _, api := range apis.Items {
+		if glance.GetOwningGlanceName(&api) == instance.Name && glanceapi.SharedStaging(&api) {
+			stagingPVCs = append(stagingPVCs, glanceapi.SharedStagingPVCName(&api))
+		}
+	}
+	slices.Sort(stagingPVCs)
+
+	cronSpec := glance.CronJobSpec{
+		Name:        cronJobName,
+		PvcClaim:    nil,
+		Command:     glanceapi.ImageCleanupCommand,
+		Schedule:    instance.Spec.ImageCleanup.Schedule,
+		CjType:      glance.ImageCleanup,
+		Labels:      serviceLabels,
+		Annotations: serviceAnnotations,
+	}
+
+	cronjobDef := glanceapi.ImageCleanupJob(
+		keystoneAPI,
+		*instance.Spec.ImageCleanup,
+		stagingPVCs,
+		cronSpec,
+	)
+
+	imageCleanupCronJob := cronjob.NewCronJob(
+		cronjobDef,
+		glance.ShortDuration,
+	)
+	cronJobResult, err := imageCleanupCronJob.CreateOrPatch(ctx, h)
+	if err != nil {
+		return cronJobResult, err
+	} else if (cronJobResult != ctrl.Result{}) {
+		ctrlResult = cronJobResult
+	}
+
+	// The Glance controller owns the CronJobs: a new run updates the
+	// CronJob status and triggers a reconcile
+	latest, err := latestTerminationMessage(
+		ctx, r.Kclient, instance.Namespace, glance.CronJobNameLabel+"="+cronJobName)
+	if err != nil || latest == nil {
+		return ctrlResult, err
+	}
+	summary := &glancev1.ImageCleanupStatus{}
+	if err := json.Unmarshal([]byte(latest.Message), summary); err != nil {
+		// not a summary, e.g. a traceback of a failed run
+		return ctrlResult, nil
+	}
+	summary.LastRunTime = latest.FinishedAt.DeepCopy()
+
+	// The per-replica staging areas are cleaned up by their own CronJobs
+	for _, name := range stagingCronJobs {
+		latest, err := latestTerminationMessage(
+			ctx, r.Kclient, instance.Namespace, glance.CronJobNameLabel+"="+name)
+		if err != nil {
+			return ctrlResult, err
+		}
+		if latest == nil {
+			continue
+		}
+		staging := glancev1.ImageCleanupStatus{}
+		if err := json.Unmarshal([]byte(latest.Message), &staging); err != nil {
+			continue
+		}
+		summary.OrphanStagingFiles += staging.OrphanStagingFiles
+		summary.RemovedStagingFiles += staging.RemovedStagingFiles
+	}
+	instance.Status.ImageCleanup = summary
+
+	return ctrlResult, nil
+}
+
+// ensureStagingCleanupJobs - creates a StagingCleanup CronJob for each
+// replica of the GlanceAPIs whose staging area is not shared, and deletes the
+// CronJobs no longer required. Like the ConsistencyCheck CronJobs, they mount
+// the PVC of the replica, either the glance PVC that holds
+// os_glance_staging_store by default or the per-replica staging PVC, and an
+// ephemeral staging area is not cleaned up. The names of the CronJobs are
+// returned to report their last run
+func (r *GlanceReconciler) ensureStagingCleanupJobs(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *glancev1.Glance,
+	serviceLabels map[string]string,
+	serviceAnnotations map[string]string,
+) (ctrl.Result, []string, error) {
+	var overallCtrlResult ctrl.Result
+
+	apis := &glancev1.GlanceAPIList{}
+	if err := r.List(ctx, apis, client.InNamespace(instance.Namespace)); err != nil {
+		return ctrl.Result{}, nil, err
+	}
+	// cronJob name -> CronJob
+	required := map[string]*batchv1.CronJob{}
+	for i := range apis.Items {
+		api := &apis.Items[i]
+		if instance.Spec.ImageCleanup == nil || glance.GetOwningGlanceName(api) != instance.Name ||
+			!api.DeletionTimestamp.IsZero() || api.Spec.Replicas == nil {
+			continue
+		}
+		pvcPrefix := glance.ServiceName
+		switch {
+		case glanceapi.StagingPVC(api):
+			pvcPrefix = glance.StagingVolume
+		case api.Spec.Staging != nil || api.Spec.Storage.External:
+			continue
+		}
+		stsName := glanceapi.StatefulSetName(api, api.Status.StatefulSetRevision)
+		for j := int32(0); j < *api.Spec.Replicas; j++ {
+			podName := fmt.Sprintf("%s-%d", stsName, j)
+			pvcName := fmt.Sprintf("%s-%s", pvcPrefix, podName)
+			cronSpec := glance.CronJobSpec{
+				Name:        fmt.Sprintf("%s-%s", podName, glance.StagingCleanup),
+				PvcClaim:    &pvcName,
+				Command:     glanceapi.ImageCleanupCommand,
+				Schedule:    instance.Spec.ImageCleanup.Schedule,
+				CjType:      glance.StagingCleanup,
+				Labels:      serviceLabels,
+				Annotations: serviceAnnotations,
+			}
+			required[cronSpec.Name] = glanceapi.StagingCleanupJob(
+				api, *instance.Spec.ImageCleanup, podName, cronSpec)
+		}
+	}
+
+	names := slices.Sorted(maps.Keys(required))
+	for _, name := range names {
+		ctrlResult, err := cronjob.NewCronJob(required[name], glance.ShortDuration).CreateOrPatch(ctx, h)
+		if err != nil {
+			return ctrlResult, nil, err
+		} else if (ctrlResult != ctrl.Result{}) {
+			overallCtrlResult = ctrlResult
+		}
+	}
+
+	// Delete the CronJobs of the replicas removed by a scale down, of a
+	// previous StatefulSet revision, or when the cleanup is disabled
+	cronJobs := &batchv1.CronJobList{}
+	if err := r.List(ctx, cronJobs, client.InNamespace(instance.Namespace)); err != nil {
+		return ctrl.Result{}, nil, err
+	}
+	for _, cj := range cronJobs.Items {
+		if _, ok := required[cj.Name]; ok ||
+			!strings.HasSuffix(cj.Name, "-"+string(glance.StagingCleanup)) ||
+			!metav1.IsControlledBy(&cj, instance) {
+			continue
+		}
+		if err := r.Delete(ctx, &cj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !k8s_errors.IsNotFound(err) {
+			return ctrl.Result{}, nil, err
+		}
+	}
+	return overallCtrlResult, names, nil
+}
+
 // registeredLimitsDelete - cleanup registered limits in keystone
 func (r *GlanceReconciler) registeredLimitsDelete(
 	ctx context.Context,
@@ -1277,6 +1670,8 @@ func (r *GlanceReconciler) glanceAPICleanup(ctx context.Context, instance *glanc
 				err = fmt.Errorf("error cleaning up %s: %w", glanceAPI.Name, err)
 				return err
 			}
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, eventReasonGlanceAPIDeleted,
+				"GlanceAPI %s deleted: %s is no longer in the glanceAPIs list", glanceAPI.Name, apiName)
 			// Update the APIEndpoints in the top-level CR
 			endpoints := []endpoint.Endpoint{endpoint.EndpointPublic, endpoint.EndpointInternal}
 			for _, ep := range endpoints {
@@ -18,10 +18,13 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"maps"
 	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -30,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -54,6 +58,7 @@ import (
 	"github.com/openstack-k8s-operators/lib-common/modules/common/annotations"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/backup"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/configmap"
 	cronjob "github.com/openstack-k8s-operators/lib-common/modules/common/cronjob"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/endpoint"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
@@ -68,16 +73,29 @@ import (
 	mariadbv1 "github.com/openstack-k8s-operators/mariadb-operator/api/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 // GlanceAPIReconciler reconciles a GlanceAPI object
 type GlanceAPIReconciler struct {
 	client.Client
-	Kclient kubernetes.Interface
-	Scheme  *runtime.Scheme
+	Kclient  kubernetes.Interface
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// NodeStats - returns the kubelet stats summary of a node, read through
+	// the nodes/proxy API when nil
+	NodeStats NodeStatsFunc
+
+	// cached result of the ServiceMonitor discovery
+	discoveryMu       sync.Mutex
+	serviceMonitorCRD bool
+	discoveryTime     time.Time
 }
 
+// NodeStatsFunc - returns the kubelet stats summary (/stats/summary) of a node
+type NodeStatsFunc func(ctx context.Context, nodeName string) ([]byte, error)
+
 // GetLogger returns a logger object with a prefix of "controller.name" and additional controller context fields
 func (r *GlanceAPIReconciler) GetLogger(ctx context.Context) logr.Logger {
 	return log.FromContext(ctx).WithName("Controllers").WithName("GlanceAPI")
@@ -86,14 +104,19 @@ func (r *GlanceAPIReconciler) GetLogger(ctx context.Context) logr.Logger {
 // +kubebuilder:rbac:groups=glance.openstack.org,resources=glanceapis,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=glance.openstack.org,resources=glanceapis/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=glance.openstack.org,resources=glanceapis/finalizers,verbs=update;patch
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glancerestores,verbs=get;list;watch
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glancebackups,verbs=get;list;watch
 // +kubebuilder:rbac:groups=cinder.openstack.org,resources=cinders,verbs=get;list;watch
 // +kubebuilder:rbac:groups=horizon.openstack.org,resources=horizons,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete;
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=statefulsets/status,verbs=get;list;watch
-// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete;
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;
+// +kubebuilder:rbac:groups=core,resources=nodes/proxy,verbs=get
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete;
 // +kubebuilder:rbac:groups=keystone.openstack.org,resources=keystoneapis,verbs=get;list;watch;
 // +kubebuilder:rbac:groups=keystone.openstack.org,resources=keystoneendpoints,verbs=get;list;watch;create;update;patch;delete;
 // +kubebuilder:rbac:groups=k8s.cni.cncf.io,resources=network-attachment-definitions,verbs=get;list;watch
@@ -144,6 +167,10 @@ func (r *GlanceAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	// Save a copy of the condtions so that we can restore the LastTransitionTime
 	// when a condition's state doesn't change.
 	savedConditions := instance.Status.Conditions.DeepCopy()
+	// The StorageCapacity condition is evaluated once the GlanceAPI is Ready
+	// and must not be mirrored to the Ready condition: it is kept aside
+	// during the Reconcile and restored when it is not evaluated again
+	var capacityCondition *condition.Condition
 
 	// Always patch the instance status when exiting this function so we can persist any changes.
 	defer func() {
@@ -158,6 +185,9 @@ func (r *GlanceAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			instance.Status.Conditions.Set(
 				instance.Status.Conditions.Mirror(condition.ReadyCondition))
 		}
+		if capacityCondition != nil && !instance.Status.Conditions.Has(glancev1.StorageCapacityCondition) {
+			instance.Status.Conditions.Set(capacityCondition)
+		}
 		err := helper.PatchInstance(ctx, instance)
 		if err != nil {
 			_err = err
@@ -185,6 +215,10 @@ func (r *GlanceAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		condition.UnknownCondition(condition.CronJobReadyCondition, condition.InitReason, condition.CronJobReadyInitMessage),
 	)
 
+	if instance.Spec.Storage.Monitoring != nil {
+		capacityCondition = instance.Status.Conditions.Get(glancev1.StorageCapacityCondition)
+	}
+	instance.Status.Conditions.Remove(glancev1.StorageCapacityCondition)
 	instance.Status.Conditions.Init(&cl)
 	instance.Status.ObservedGeneration = instance.Generation
 
@@ -211,6 +245,16 @@ func (r *GlanceAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		c := condition.UnknownCondition(condition.TopologyReadyCondition, condition.InitReason, condition.TopologyReadyInitMessage)
 		cl.Set(c)
 	}
+	// Init KeyManager condition if a KeyManager is explicitly configured
+	if instance.Spec.KeyManager != nil {
+		c := condition.UnknownCondition(glancev1.KeyManagerReadyCondition, condition.InitReason, glancev1.KeyManagerReadyInitMessage)
+		cl.Set(c)
+	}
+	// Init StorageResize condition when the GlanceAPI owns PVCs
+	if !instance.Spec.Storage.External {
+		c := condition.UnknownCondition(glancev1.StorageResizeReadyCondition, condition.InitReason, glancev1.StorageResizeReadyInitMessage)
+		cl.Set(c)
+	}
 	// Handle non-deleted clusters
 	return r.reconcileNormal(ctx, instance, helper)
 }
@@ -302,6 +346,30 @@ func (r *GlanceAPIReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Man
 		return err
 	}
 
+	// index keyManagerLocalKeyField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &glancev1.GlanceAPI{}, keyManagerLocalKeyField, func(rawObj client.Object) []string {
+		// Extract the local key secret name from the spec, if one is provided
+		cr := rawObj.(*glancev1.GlanceAPI)
+		if !cr.Spec.KeyManager.IsLocal() || cr.Spec.KeyManager.LocalKeySecret == "" {
+			return nil
+		}
+		return []string{cr.Spec.KeyManager.LocalKeySecret}
+	}); err != nil {
+		return err
+	}
+
+	// index propertyProtectionsField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &glancev1.GlanceAPI{}, propertyProtectionsField, func(rawObj client.Object) []string {
+		// Extract the property protections ConfigMap name from the spec, if one is provided
+		cr := rawObj.(*glancev1.GlanceAPI)
+		if cr.Spec.PropertyProtections == nil {
+			return nil
+		}
+		return []string{cr.Spec.PropertyProtections.ConfigMap}
+	}); err != nil {
+		return err
+	}
+
 	// Watch for changes to any CustomServiceConfigSecrets. Global secrets
 	svcSecretFn := func(_ context.Context, o client.Object) []reconcile.Request {
 		var namespace = o.GetNamespace()
@@ -400,6 +468,7 @@ func (r *GlanceAPIReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Man
 		Owns(&corev1.Service{}).
 		Owns(&corev1.Secret{}).
 		Owns(&appsv1.StatefulSet{}).
+		Owns(&batchv1.CronJob{}).
 		Watches(&corev1.Secret{},
 			handler.EnqueueRequestsFromMapFunc(svcSecretFn)).
 		Watches(&networkv1.NetworkAttachmentDefinition{},
@@ -409,6 +478,11 @@ func (r *GlanceAPIReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Man
 			handler.EnqueueRequestsFromMapFunc(r.findObjectsForSrc),
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
 		).
+		Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForPropertyProtections),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
 		Watches(&memcachedv1.Memcached{},
 			handler.EnqueueRequestsFromMapFunc(memcachedFn)).
 		Watches(&topologyv1.Topology{},
@@ -420,9 +494,19 @@ func (r *GlanceAPIReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Man
 		Watches(&horizonv1.Horizon{},
 			handler.EnqueueRequestsFromMapFunc(r.findObjectForSrc),
 			builder.WithPredicates(horizonv1.HorizonEndpointChangedPredicate)).
+		Watches(&keystonev1.KeystoneEndpoint{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectForSrc),
+			builder.WithPredicates(barbicanEndpointPredicate)).
 		Complete(r)
 }
 
+// barbicanEndpointPredicate - filters the KeystoneEndpoint events that might
+// change the KeyManager configuration
+var barbicanEndpointPredicate = predicate.NewPredicateFuncs(func(o client.Object) bool {
+	ep, ok := o.(*keystonev1.KeystoneEndpoint)
+	return ok && ep.Spec.ServiceName == glance.BarbicanServiceName
+})
+
 func (r *GlanceAPIReconciler) findObjectsForSrc(ctx context.Context, src client.Object) []reconcile.Request {
 	requests := []reconcile.Request{}
 
@@ -457,6 +541,40 @@ func (r *GlanceAPIReconciler) findObjectsForSrc(ctx context.Context, src client.
 	return requests
 }
 
+// findObjectsForPropertyProtections - returns the GlanceAPIs referencing the
+// ConfigMap as PropertyProtections
+func (r *GlanceAPIReconciler) findObjectsForPropertyProtections(ctx context.Context, src client.Object) []reconcile.Request {
+	requests := []reconcile.Request{}
+
+	Log := r.GetLogger(ctx)
+
+	crList := &glancev1.GlanceAPIList{}
+	listOps := &client.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector(propertyProtectionsField, src.GetName()),
+		Namespace:     src.GetNamespace(),
+	}
+	err := r.List(ctx, crList, listOps)
+	if err != nil {
+		Log.Error(err, fmt.Sprintf("listing %s for field: %s - %s", crList.GroupVersionKind().Kind, propertyProtectionsField, src.GetNamespace()))
+		return requests
+	}
+
+	for _, item := range crList.Items {
+		Log.Info(fmt.Sprintf("input source %s changed, reconcile: %s - %s", src.GetName(), item.GetName(), item.GetNamespace()))
+
+		requests = append(requests,
+			reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      item.GetName(),
+					Namespace: item.GetNamespace(),
+				},
+			},
+		)
+	}
+
+	return requests
+}
+
 func (r *GlanceAPIReconciler) findObjectForSrc(ctx context.Context, src client.Object) []reconcile.Request {
 	requests := []reconcile.Request{}
 
@@ -519,6 +637,13 @@ func (r *GlanceAPIReconciler) reconcileDelete(ctx context.Context, instance *gla
 		return ctrlResult, err
 	}
 
+	// Remove the PVCs that are not retained when the GlanceAPI is deleted
+	if err := r.ensureDeletedPVCs(ctx, instance); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	glanceAPIRecreationsTotal.DeleteLabelValues(instance.Namespace, instance.Name)
+
 	// Endpoints are deleted so remove the finalizer.
 	controllerutil.RemoveFinalizer(instance, helper.GetFinalizer())
 	Log.Info(fmt.Sprintf("Reconciled Service '%s' delete successfully", instance.Name))
@@ -579,7 +704,9 @@ func (r *GlanceAPIReconciler) reconcileInit(
 				Name:      endpointName,
 				Namespace: instance.Namespace,
 				Labels:    exportLabels,
-				Selector:  serviceLabels,
+				// Only the Pods of the serving StatefulSet revision are
+				// selected, see glanceAPIRefresh
+				Selector: glanceapi.ServiceSelector(instance, serviceLabels),
 				Port: service.GenericServicePort{
 					Name:     endpointName,
 					Port:     data.Port,
@@ -676,6 +803,16 @@ func (r *GlanceAPIReconciler) reconcileInit(
 			return ctrl.Result{}, err
 		}
 	}
+	// Create/Delete the Service exposing the metrics sidecars
+	if err := r.ensureMetricsService(ctx, helper, instance, serviceLabels); err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.CreateServiceReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.CreateServiceReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
 	instance.Status.Conditions.MarkTrue(condition.CreateServiceReadyCondition, condition.CreateServiceReadyMessage)
 
 	//
@@ -795,7 +932,8 @@ func (r *GlanceAPIReconciler) reconcileNormal(
 	// Update the current StateFulSet (by recreating it) only when a backend is
 	// added or removed from an already existing API
 	if hashChanged {
-		if err = r.glanceAPIRefresh(ctx, helper, instance); err != nil {
+		recreated, err := r.glanceAPIRefresh(ctx, helper, instance)
+		if err != nil {
 			instance.Status.Conditions.MarkFalse(
 				condition.DeploymentReadyCondition,
 				condition.ErrorReason,
@@ -805,6 +943,18 @@ func (r *GlanceAPIReconciler) reconcileNormal(
 			)
 			return ctrl.Result{}, err
 		}
+		// The API is down until the StatefulSet is recreated by the next
+		// reconcile loop
+		if recreated {
+			instance.Status.Conditions.MarkFalse(
+				condition.DeploymentReadyCondition,
+				condition.RequestedReason,
+				condition.SeverityWarning,
+				glancev1.GlanceAPIRecreatedMessage,
+				glanceapi.StatefulSetName(instance, instance.Status.StatefulSetRevision),
+			)
+			return glance.ResultRequeue, nil
+		}
 	}
 	// iterate over availableBackends for backend specific cases
 	for i := range availableBackends {
@@ -862,6 +1012,23 @@ func (r *GlanceAPIReconciler) reconcileNormal(
 	// or, in case Cinder is a backend for the current GlanceAPI, the associated resources
 	// are present in the control plane
 	instance.Status.Conditions.MarkTrue(glancev1.CinderCondition, glancev1.CinderReadyMessage)
+
+	//
+	// check for the optional PropertyProtections ConfigMap and add its hash
+	// to the vars map
+	//
+	ctrlResult, err = r.verifyPropertyProtections(ctx, helper, instance, &configVars)
+	if (err != nil || ctrlResult != ctrl.Result{}) {
+		return ctrlResult, err
+	}
+
+	//
+	// KeyManager input validation
+	//
+	keyManagerOpts, ctrlResult, err := r.ensureKeyManager(ctx, helper, instance)
+	if (err != nil || ctrlResult != ctrl.Result{}) {
+		return ctrlResult, err
+	}
 	//
 	// TLS input validation
 	//
@@ -969,7 +1136,7 @@ func (r *GlanceAPIReconciler) reconcileNormal(
 
 	// Generate service config
 	err = r.generateServiceConfig(ctx, helper, instance, &configVars,
-		imageConv, memcached, wsgi, extConfigOptions)
+		imageConv, memcached, wsgi, extConfigOptions, keyManagerOpts)
 	if err != nil {
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.ServiceConfigReadyCondition,
@@ -980,6 +1147,23 @@ func (r *GlanceAPIReconciler) reconcileNormal(
 		return glance.ResultRequeue, err
 	}
 
+	// Resolve the internal hosts that should never go through the outbound
+	// proxy
+	proxyEnvVars := map[string]env.Setter{}
+	if instance.Spec.Proxy != nil {
+		noProxyHosts, err := r.getNoProxyHosts(ctx, helper, instance, memcached)
+		if err != nil {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				condition.ServiceConfigReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				condition.ServiceConfigReadyErrorMessage,
+				err.Error()))
+			return ctrl.Result{}, err
+		}
+		proxyEnvVars = glanceapi.GetProxyEnvVars(instance.Spec.Proxy, noProxyHosts)
+	}
+
 	configVars[glance.KeystoneEndpoint] = env.SetValue(instance.Annotations[glance.KeystoneEndpoint])
 	//
 	// normal reconcile tasks
@@ -1060,6 +1244,40 @@ func (r *GlanceAPIReconciler) reconcileNormal(
 	// handled via pod affinity (ColocateWithPod), not via HostPID/Privileged --
 	// enabling image cache alone no longer elevates this GlanceAPI's SCC.
 
+	// The PVCs restored from a GlanceBackup must exist before the
+	// StatefulSet provisions empty ones
+	restoreName, err := r.pendingGlanceRestore(ctx, instance)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.DeploymentReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.DeploymentReadyErrorMessage,
+			err.Error()))
+		return ctrlResult, err
+	}
+	if restoreName != "" {
+		Log.Info(fmt.Sprintf("Waiting for GlanceRestore %s", restoreName))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.DeploymentReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			glancev1.GlanceAPIRestoreWaitingMessage,
+			restoreName))
+		return glance.ResultRequeue, nil
+	}
+
+	// The shared staging PVC must exist before the pods that mount it
+	if err := r.ensureSharedStagingPVC(ctx, helper, instance, GetServiceLabels(instance)); err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.DeploymentReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.DeploymentReadyErrorMessage,
+			err.Error()))
+		return ctrlResult, err
+	}
+
 	// Define a new StatefuleSet object
 	deplDef, err := glanceapi.StatefulSet(instance,
 		inputHash,
@@ -1069,6 +1287,8 @@ func (r *GlanceAPIReconciler) reconcileNormal(
 		topology,
 		wsgi,
 		memcached,
+		proxyEnvVars,
+		imageConv,
 	)
 	if err != nil {
 		return ctrlResult, err
@@ -1152,8 +1372,26 @@ func (r *GlanceAPIReconciler) reconcileNormal(
 				condition.DeploymentReadyRunningMessage))
 		}
 	}
+	// Switch the Services to the new StatefulSet revision (if any) and
+	// remove the previous one
+	switched, err := r.ensureServingRevision(ctx, instance, depl.GetStatefulSet())
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if switched {
+		return glance.ResultRequeue, nil
+	}
 	// create StatefulSet - end
 
+	// Apply the PVCs retention policy to the current StatefulSet
+	if err = r.ensurePVCRetention(ctx, instance, depl.GetStatefulSet()); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	resizeRequeue := false
+	// StorageClass of the PVCs that can't be expanded, reported once the
+	// Ready condition has been evaluated
+	resizeNotSupported := ""
 	// Reconcile PVC labels for backup/restore
 	// Note: We reconcile PVC labels here in addition to setting them in VolumeClaimTemplates
 	// because VolumeClaimTemplates are immutable on existing StatefulSets. This allows
@@ -1163,15 +1401,27 @@ func (r *GlanceAPIReconciler) reconcileNormal(
 		if err != nil {
 			return ctrl.Result{}, err
 		}
+		// Expand the existing PVCs in place when a bigger size is requested
+		resizeRequeue, resizeNotSupported, err = r.ensurePVCResize(ctx, instance)
+		if err != nil {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				glancev1.StorageResizeReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				glancev1.StorageResizeReadyErrorMessage,
+				err.Error()))
+			return ctrl.Result{}, err
+		}
 	}
 
 	// create ImageCache cronJobs
 
-	if len(instance.Spec.ImageCache.Size) > 0 {
+	if glanceapi.ImageCachePVC(instance) {
 		// If image-cache has been enabled, create two additional cronJobs:
 		// - CacheCleanerJob: clean stalled images or in an invalid state
 		// - CachePrunerJob: clean the image-cache folder to stay under ImageCacheSize
 		//   limit
+		// An emptyDir or ephemeral cache is cleaned by a sidecar instead
 		for _, item := range []glance.CronJobType{glance.CacheCleaner, glance.CachePruner} {
 			ctrlResult, err = r.ensureImageCacheJob(
 				ctx,
@@ -1204,6 +1454,25 @@ func (r *GlanceAPIReconciler) reconcileNormal(
 		return ctrlResult, nil
 	}
 
+	// ConsistencyCheck cronJobs compare the glance PVC of each replica with
+	// the image locations recorded in the database
+	ctrlResult, err = r.ensureConsistencyCheckJobs(
+		ctx,
+		helper,
+		instance,
+		GetServiceLabels(instance),
+		serviceAnnotations,
+	)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.CronJobReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.CronJobReadyErrorMessage,
+			err.Error()))
+		return ctrlResult, err
+	}
+
 	// If we reach this point, we can mark the CronJobReadyCondition as True
 	instance.Status.Conditions.MarkTrue(
 		condition.CronJobReadyCondition,
@@ -1224,6 +1493,9 @@ func (r *GlanceAPIReconciler) reconcileNormal(
 				instance.Status.ApplicationCredentialSecret, instance.ACConsumerFinalizerName()); err != nil {
 				return ctrl.Result{}, err
 			}
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, eventReasonACSecretReleased,
+				"ApplicationCredential Secret %s released after rotation to %s",
+				instance.Status.ApplicationCredentialSecret, instance.Spec.Auth.ApplicationCredentialSecret)
 			instance.Status.ApplicationCredentialSecret = instance.Spec.Auth.ApplicationCredentialSecret
 		}
 	} else {
@@ -1236,7 +1508,30 @@ func (r *GlanceAPIReconciler) reconcileNormal(
 		instance.Status.Conditions.MarkTrue(
 			condition.ReadyCondition, condition.ReadyMessage)
 	}
+	// The GlanceAPI keeps serving with the PVCs at their current size: a
+	// resize that the StorageClass doesn't support is reported as a warning
+	// that doesn't affect Ready
+	if resizeNotSupported != "" {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.StorageResizeReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			glancev1.StorageResizeNotSupportedMessage,
+			resizeNotSupported))
+	}
+	// The volumes usage is collected once the GlanceAPI is Ready, and
+	// refreshed periodically
+	if err = r.ensureStorageCapacity(ctx, instance); err != nil {
+		return ctrl.Result{}, err
+	}
 	Log.Info(fmt.Sprintf("Reconciled Service '%s' successfully", instance.Name))
+	// PVCs are not watched: requeue until their resize is completed
+	if resizeRequeue {
+		return glance.ResultRequeue, nil
+	}
+	if instance.Spec.Storage.Monitoring != nil || instance.Spec.Storage.Autogrow != nil {
+		return ctrl.Result{RequeueAfter: glance.StorageCapacityInterval}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -1250,6 +1545,7 @@ func (r *GlanceAPIReconciler) generateServiceConfig(
 	memcached *memcachedv1.Memcached,
 	wsgi bool,
 	extConfigOptions []util.IniOption,
+	keyManagerOpts map[string]any,
 ) error {
 	Log := r.GetLogger(ctx)
 	labels := labels.GetLabels(instance, labels.GetGroupLabel(glance.ServiceName), GetServiceLabels(instance))
@@ -1339,6 +1635,13 @@ func (r *GlanceAPIReconciler) generateServiceConfig(
 		httpdVhostConfig[endpt.String()] = endptConfig
 	}
 
+	// workers/processes/threads are either explicit or derived from the
+	// container resources
+	sizing := glanceapi.GetProcessSizing(instance.Spec.GlanceAPITemplate)
+	instance.Status.Workers = sizing.Workers
+	instance.Status.Processes = sizing.Processes
+	instance.Status.Threads = sizing.Threads
+
 	templateParameters := map[string]any{
 		"ServiceUser":         instance.Spec.ServiceUser,
 		"ServicePassword":     string(ospSecret.Data[instance.Spec.PasswordSelectors.Service]),
@@ -1354,11 +1657,27 @@ func (r *GlanceAPIReconciler) generateServiceConfig(
 		// each GlanceAPI instance should build the config file according to
 		// https://docs.openstack.org/glance/latest/admin/quotas.html
 		"QuotaEnabled": instance.Spec.Quota,
-		"LogFile":      fmt.Sprintf("%s%s.log", glance.GlanceLogPath, instance.Name),
 		"VHosts":       httpdVhostConfig,
 		"Wsgi":         wsgi,
+		"Workers":      sizing.Workers,
+		"Processes":    sizing.Processes,
+		"Threads":      sizing.Threads,
+	}
+
+	// Logging options: the log file is only used when streamed by the
+	// glance-log sidecar
+	logging := instance.Spec.GetLogging()
+	templateParameters["Debug"] = logging.Debug
+	templateParameters["LogJSON"] = logging.JSON
+	if instance.Spec.LogToFile() {
+		templateParameters["LogFile"] = fmt.Sprintf("%s%s.log", glance.GlanceLogPath, instance.Name)
+		templateParameters["MaxLogfileCount"] = logging.MaxLogfileCount
+		templateParameters["MaxLogfileSizeMB"] = logging.MaxLogfileSizeMB
 	}
 
+	// [key_manager] parameters resolved by ensureKeyManager
+	maps.Copy(templateParameters, keyManagerOpts)
+
 	// Try to get Application Credential from the secret specified in the CR
 	if instance.Spec.Auth.ApplicationCredentialSecret != "" {
 		acSecretObj, _, err := secret.GetSecret(ctx, h, instance.Spec.Auth.ApplicationCredentialSecret, instance.Namespace)
@@ -1404,17 +1723,46 @@ func (r *GlanceAPIReconciler) generateServiceConfig(
 		templateParameters["ImageConversion"] = imageConv
 	}
 
+	// Restrict the URIs that can be consumed by the web-download import
+	// method: the public API gets a default deny list of cluster-internal
+	// hosts unless a filtering is explicitly provided
+	if importFilteringOpts := glanceapi.GetImportFilteringOpts(
+		instance.Spec.GetImportFiltering(instance.Spec.APIType)); len(importFilteringOpts) > 0 {
+		templateParameters["ImportFilteringOpts"] = importFilteringOpts
+	}
+
+	// The rules file is mounted by the StatefulSet from the referenced ConfigMap
+	if pp := instance.Spec.PropertyProtections; pp != nil {
+		templateParameters["PropertyProtectionFile"] = glance.PropertyProtectionsPath
+		templateParameters["PropertyProtectionRuleFormat"] = pp.RuleFormat
+	}
+
 	// Configure the cache bits accordingly as global options (00-config.conf)
 	if len(instance.Spec.ImageCache.Size) > 0 {
+		// image_cache_max_size follows the cache PVCs size: a new size is
+		// rendered only when the resize of the existing PVCs is completed
+		if instance.Status.ImageCacheSize == "" {
+			instance.Status.ImageCacheSize = instance.Spec.ImageCache.Size
+		}
 		// if ImageCacheSize is not a valid k8s Quantity, return an error
-		cacheSize, err := resource.ParseQuantity(instance.Spec.ImageCache.Size)
+		cacheSize, err := resource.ParseQuantity(instance.Status.ImageCacheSize)
 		if err != nil {
 			return err
 		}
 		templateParameters["CacheEnabled"] = true
 		templateParameters["CacheMaxSize"] = cacheSize.Value()
 		templateParameters["ImageCacheDir"] = glance.ImageCacheDir
+	} else {
+		instance.Status.ImageCacheSize = ""
 	}
+	// os_glance_staging_store points to the dedicated staging volume (if any)
+	if instance.Spec.Staging != nil {
+		templateParameters["StagingDir"] = glance.StagingDir
+	}
+	// os_glance_tasks_store is the work dir of a single import task, always
+	// run by the replica that received the import call: it stays per-replica,
+	// where the imageConversion volume (if any) is mounted
+	templateParameters["ImageConversionDir"] = glance.ImageConversionDir
 	templateParameters["MemcachedServersWithInet"] = memcached.GetMemcachedServerListWithInetString()
 	templateParameters["MemcachedServers"] = memcached.GetMemcachedServerListString()
 
@@ -1437,6 +1785,29 @@ func (r *GlanceAPIReconciler) generateServiceConfig(
 		templateParameters["QuorumQueues"] = string(notificationBusSecret.Data["quorumqueues"]) == "true"
 	}
 
+	// Metrics sidecars: mod_status is served on localhost only, and the
+	// access log consumed by mtail is written when its exporter is deployed
+	if m := instance.Spec.Metrics; m != nil {
+		templateParameters["MetricsStatusPort"] = glance.MetricsStatusPort
+		if m.LogExporterImage != "" {
+			templateParameters["MetricsAccessLog"] = glance.MetricsAccessLog
+		}
+	}
+
+	// Audit events are sent to the notifications bus only when a transportURL
+	// is available, otherwise they fall back to the GlanceAPI logs
+	if audit := instance.Spec.Audit; audit != nil {
+		auditDriver := glancev1.AuditDriverLog
+		if audit.Driver == glancev1.AuditDriverNotifications {
+			if _, ok := templateParameters["TransportURL"]; ok {
+				auditDriver = glancev1.AuditDriverNotifications
+			} else {
+				Log.Info("No notifications bus configured, audit events are sent to the log")
+			}
+		}
+		templateParameters["AuditDriver"] = auditDriver
+	}
+
 	// Try to get Horizon endpoint and setup CORS section if the CR is found
 	if horizonEndpoint, err := r.GetHorizonEndpoint(ctx, h, instance); err == nil {
 		templateParameters["HorizonEndpoint"] = horizonEndpoint
@@ -1448,75 +1819,340 @@ func (r *GlanceAPIReconciler) generateServiceConfig(
 	return GenerateConfigsGeneric(ctx, h, instance, envVars, templateParameters, customData, labels, false)
 }
 
-// createHashOfInputHashes - creates a hash of hashes which gets added to the resources which requires a restart
-// if any of the input resources change, like configs, passwords, ...
-//
-// returns the hash, whether the hash changed (as a bool) and any error
-func (r *GlanceAPIReconciler) createHashOfInputHashes(
+// verifyPropertyProtections - checks the ConfigMap referenced by
+// PropertyProtections exists and provides the rules file, and adds its hash
+// to envVars so that any change rolls out the GlanceAPI Pods
+func (r *GlanceAPIReconciler) verifyPropertyProtections(
 	ctx context.Context,
+	h *helper.Helper,
 	instance *glancev1.GlanceAPI,
-	envVars map[string]env.Setter,
-) (string, bool, error) {
-	var hashMap map[string]string
+	envVars *map[string]env.Setter,
+) (ctrl.Result, error) {
 	Log := r.GetLogger(ctx)
-	changed := false
-	mergedMapVars := env.MergeEnvs([]corev1.EnvVar{}, envVars)
-	hash, err := util.ObjectHash(mergedMapVars)
+	pp := instance.Spec.PropertyProtections
+	if pp == nil {
+		return ctrl.Result{}, nil
+	}
+	cm, hash, err := configmap.GetConfigMapAndHashWithName(ctx, h, pp.ConfigMap, instance.Namespace)
 	if err != nil {
-		return hash, changed, err
+		if k8s_errors.IsNotFound(err) {
+			// Since the ConfigMap should have been manually created by the
+			// user and referenced in the spec, we treat this as a warning
+			Log.Info(fmt.Sprintf("PropertyProtections ConfigMap %s not found", pp.ConfigMap))
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				condition.InputReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				glancev1.PropertyProtectionsWaitingMessage,
+				pp.ConfigMap))
+			return glance.ResultRequeue, nil
+		}
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.InputReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
 	}
-	if hashMap, changed = util.SetHash(instance.Status.Hash, common.InputHashName, hash); changed {
-		instance.Status.Hash = hashMap
-		Log.Info(fmt.Sprintf("Input maps hash %s - %s", common.InputHashName, hash))
+	if _, ok := cm.Data[glance.PropertyProtectionsFileName]; !ok {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			glancev1.PropertyProtectionsKeyErrorMessage,
+			pp.ConfigMap,
+			glance.PropertyProtectionsFileName))
+		return glance.ResultRequeue, nil
 	}
-	return hash, changed, nil
+	(*envVars)[pp.ConfigMap] = env.SetValue(hash)
+	return ctrl.Result{}, nil
 }
 
-// createHashOfBackendConfig - It creates an Hash of the current "enabledBackend"
-// string, combined with the storage interface configuration (both PVCs and imageCache).
-// The resulting hash is set in the .Status.Hash of the GlanceAPI object.
-// If a backend is added or removed, we're able to plug or unplug a PVC for an
-// existing API by recreating the StateFulSet through the glanceAPIRefresh function.
-// The hash update results in a glanceAPIRefresh trigger.
-func (r *GlanceAPIReconciler) createHashOfBackendConfig(
+// ensureKeyManager - validates the KeyManager inputs and returns the
+// parameters used to render the [key_manager] section. A GlanceAPI is rolled
+// out with a disabled KeyManager when Barbican is not available, unless the
+// signature verification is required
+func (r *GlanceAPIReconciler) ensureKeyManager(
 	ctx context.Context,
+	h *helper.Helper,
 	instance *glancev1.GlanceAPI,
-	backends []string,
-) (string, bool, error) {
-	var hashMap map[string]string
+) (map[string]any, ctrl.Result, error) {
 	Log := r.GetLogger(ctx)
-	changed := false
-	// Compute enabled_backend hash
-	backendHash, err := util.ObjectHash(backends)
-	if err != nil {
-		return backendHash, changed, err
-	}
-	// Compute storage interface settings hash
-	storageHash, err := util.ObjectHash(instance.Spec.Storage)
-	if err != nil {
-		return storageHash, changed, err
-	}
-	// Compute Image Cache settings hash (using only the Size parameter as we
-	// don't need to check the cronJobs settings)
-	cacheHash, err := util.ObjectHash(instance.Spec.ImageCache.Size)
-	if err != nil {
-		return cacheHash, changed, err
+	keyManager := instance.Spec.KeyManager
+
+	keyManagerOpts := map[string]any{
+		"KeyManagerBackend":         glancev1.KeyManagerBarbican,
+		"KeyManagerLocalBackend":    glance.KeyManagerLocalBackend,
+		"KeyManagerDisabledBackend": glance.KeyManagerDisabledBackend,
 	}
-	// The final Hash (stored in instance.Status.Hash) is the concatenation
-	// between backendHash (retrieved by customServiceConfig), storageHash
-	// (coming from instance.Spec.Storage interface), and cacheHash (based on
-	// instance.Spec.ImageCache.Size).
-	// The combination of the three represents the "Storage" configuration
-	// of the current GlanceAPI
-	hash, err := util.ObjectHash((backendHash + storageHash + cacheHash))
-	if err != nil {
-		return hash, changed, err
+	// Preserve the existing behavior when no KeyManager is specified
+	if keyManager == nil {
+		return keyManagerOpts, ctrl.Result{}, nil
 	}
-	if hashMap, changed = util.SetHash(instance.Status.Hash, "backendHash", hash); changed {
-		instance.Status.Hash = hashMap
-		Log.Info(fmt.Sprintf("Backend hash %s - %s", "backendHash", hash))
+	keyManagerOpts["KeyManagerBackend"] = keyManager.Backend
+	keyManagerOpts["SignatureRequired"] = keyManager.RequireSignatureVerification
+	// The local KeyManager and the signature filter are loaded from the
+	// scripts volume
+	if keyManager.IsLocal() || keyManager.SignatureRequired() {
+		keyManagerOpts["PythonPath"] = glance.ScriptsDir
 	}
-	return hash, changed, nil
+
+	switch keyManager.Backend {
+	case glancev1.KeyManagerBarbican:
+		found, err := r.hasBarbicanEndpoint(ctx, instance)
+		if err != nil {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				glancev1.KeyManagerReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				glancev1.KeyManagerReadyErrorMessage,
+				err.Error()))
+			return keyManagerOpts, ctrl.Result{}, err
+		}
+		if !found {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				glancev1.KeyManagerReadyCondition,
+				condition.RequestedReason,
+				condition.SeverityWarning,
+				glancev1.KeyManagerBarbicanWaitingMessage))
+			if keyManager.RequireSignatureVerification {
+				Log.Info("Barbican endpoint not found and signature verification is required: waiting")
+				return keyManagerOpts, glance.ResultRequeue, nil
+			}
+			Log.Info("Barbican endpoint not found: disable the KeyManager")
+			keyManagerOpts["KeyManagerBackend"] = glancev1.KeyManagerDisabled
+			return keyManagerOpts, ctrl.Result{}, nil
+		}
+	case glancev1.KeyManagerLocal:
+		// The certificates are mounted from the Secret and read on each
+		// verification, so its content is not tracked in the config hash
+		_, _, err := secret.GetSecret(ctx, h, keyManager.LocalKeySecret, instance.Namespace)
+		if err != nil {
+			if k8s_errors.IsNotFound(err) {
+				// Since the local key Secret should have been manually created
+				// by the user and referenced in the spec, we treat this as a
+				// warning
+				Log.Info(fmt.Sprintf("KeyManager local key Secret %s not found", keyManager.LocalKeySecret))
+				instance.Status.Conditions.Set(condition.FalseCondition(
+					glancev1.KeyManagerReadyCondition,
+					condition.ErrorReason,
+					condition.SeverityWarning,
+					glancev1.KeyManagerLocalKeyWaitingMessage,
+					keyManager.LocalKeySecret))
+				return keyManagerOpts, glance.ResultRequeue, nil
+			}
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				glancev1.KeyManagerReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				glancev1.KeyManagerReadyErrorMessage,
+				err.Error()))
+			return keyManagerOpts, ctrl.Result{}, err
+		}
+	}
+	instance.Status.Conditions.MarkTrue(glancev1.KeyManagerReadyCondition, glancev1.KeyManagerReadyMessage)
+	return keyManagerOpts, ctrl.Result{}, nil
+}
+
+// hasBarbicanEndpoint - returns true if a Barbican endpoint has been
+// registered in keystone
+func (r *GlanceAPIReconciler) hasBarbicanEndpoint(
+	ctx context.Context,
+	instance *glancev1.GlanceAPI,
+) (bool, error) {
+	epList := &keystonev1.KeystoneEndpointList{}
+	if err := r.List(ctx, epList, client.InNamespace(instance.Namespace)); err != nil {
+		return false, err
+	}
+	for _, ep := range epList.Items {
+		if ep.Spec.ServiceName == glance.BarbicanServiceName && len(ep.Status.EndpointIDs) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getNoProxyHosts - returns the Keystone, database, memcached and RabbitMQ
+// hosts consumed by the GlanceAPI, that must be excluded from the outbound
+// proxy
+func (r *GlanceAPIReconciler) getNoProxyHosts(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *glancev1.GlanceAPI,
+	memcached *memcachedv1.Memcached,
+) ([]string, error) {
+	hosts := []string{instance.Spec.DatabaseHostname}
+
+	keystoneAPI, err := keystonev1.GetKeystoneAPI(ctx, h, instance.Namespace, map[string]string{})
+	if err != nil {
+		return hosts, err
+	}
+	for _, endpt := range []endpoint.Endpoint{endpoint.EndpointInternal, endpoint.EndpointPublic} {
+		keystoneURL, err := keystoneAPI.GetEndpoint(endpt)
+		if err != nil {
+			return hosts, err
+		}
+		hosts = append(hosts, glanceapi.GetHostsFromURL(keystoneURL)...)
+	}
+
+	for _, server := range strings.Split(memcached.GetMemcachedServerListString(), ",") {
+		hosts = append(hosts, glanceapi.GetHostsFromURL(server)...)
+	}
+
+	if instance.Spec.NotificationBusSecret != "" {
+		notificationBusSecret, _, err := secret.GetSecret(ctx, h, instance.Spec.NotificationBusSecret, instance.Namespace)
+		if err != nil {
+			return hosts, err
+		}
+		hosts = append(hosts, glanceapi.GetHostsFromURL(
+			string(notificationBusSecret.Data["transport_url"]))...)
+	}
+	return hosts, nil
+}
+
+// createHashOfInputHashes - creates a hash of hashes which gets added to the resources which requires a restart
+// if any of the input resources change, like configs, passwords, ...
+//
+// returns the hash, whether the hash changed (as a bool) and any error
+func (r *GlanceAPIReconciler) createHashOfInputHashes(
+	ctx context.Context,
+	instance *glancev1.GlanceAPI,
+	envVars map[string]env.Setter,
+) (string, bool, error) {
+	var hashMap map[string]string
+	Log := r.GetLogger(ctx)
+	changed := false
+	mergedMapVars := env.MergeEnvs([]corev1.EnvVar{}, envVars)
+	hash, err := util.ObjectHash(mergedMapVars)
+	if err != nil {
+		return hash, changed, err
+	}
+	if hashMap, changed = util.SetHash(instance.Status.Hash, common.InputHashName, hash); changed {
+		instance.Status.Hash = hashMap
+		Log.Info(fmt.Sprintf("Input maps hash %s - %s", common.InputHashName, hash))
+	}
+	return hash, changed, nil
+}
+
+// createHashOfBackendConfig - It creates an Hash of the current "enabledBackend"
+// string, combined with the storage interface configuration (both PVCs and imageCache).
+// The resulting hash is set in the .Status.Hash of the GlanceAPI object.
+// If a backend is added or removed, we're able to plug or unplug a PVC for an
+// existing API by recreating the StateFulSet through the glanceAPIRefresh function.
+// The hash update results in a glanceAPIRefresh trigger.
+func (r *GlanceAPIReconciler) createHashOfBackendConfig(
+	ctx context.Context,
+	instance *glancev1.GlanceAPI,
+	backends []string,
+) (string, bool, error) {
+	var hashMap map[string]string
+	Log := r.GetLogger(ctx)
+	changed := false
+	// Compute enabled_backend hash
+	backendHash, err := util.ObjectHash(backends)
+	if err != nil {
+		return backendHash, changed, err
+	}
+	// Compute storage interface settings hash. The StorageRequest is not part
+	// of the hash because a size increase is applied in place to the existing
+	// PVCs (see ensurePVCResize), and the RetentionPolicy can be updated on
+	// the existing StatefulSet
+	storage := instance.Spec.Storage
+	storage.StorageRequest = ""
+	storage.RetentionPolicy = nil
+	// The volumes usage monitoring and the autogrow policy don't touch the
+	// StatefulSet
+	storage.Monitoring = nil
+	storage.Autogrow = nil
+	storageHash, err := util.ObjectHash(storage)
+	if err != nil {
+		return storageHash, changed, err
+	}
+	// Compute Image Cache settings hash (using only the Size parameter as we
+	// don't need to check the cronJobs settings). Only enabling or disabling
+	// the cache plugs or unplugs a PVC, so the actual size is not relevant
+	cacheHash, err := util.ObjectHash(len(instance.Spec.ImageCache.Size) > 0)
+	if err != nil {
+		return cacheHash, changed, err
+	}
+	// An image cache that is not a per-replica PVC drops the
+	// VolumeClaimTemplate, and a custom StorageClass changes it
+	if len(instance.Spec.ImageCache.Size) > 0 {
+		var volumeHash string
+		if !glanceapi.ImageCachePVC(instance) {
+			volumeHash, err = util.ObjectHash(instance.Spec.ImageCache.VolumeType)
+		} else if instance.Spec.ImageCache.StorageClass != "" {
+			volumeHash, err = util.ObjectHash(instance.Spec.ImageCache.StorageClass)
+		}
+		if err != nil {
+			return volumeHash, changed, err
+		}
+		cacheHash += volumeHash
+	}
+	// The final Hash (stored in instance.Status.Hash) is the concatenation
+	// between backendHash (retrieved by customServiceConfig), storageHash
+	// (coming from instance.Spec.Storage interface), and cacheHash (based on
+	// instance.Spec.ImageCache.Size).
+	// The combination of the three represents the "Storage" configuration
+	// of the current GlanceAPI
+	// A PVC based image conversion work dir adds a VolumeClaimTemplate when
+	// an rbd backend is enabled
+	rbd := slices.ContainsFunc(backends, func(b string) bool {
+		return strings.HasSuffix(b, ":rbd")
+	})
+	if glanceapi.ImageConversionPVC(instance, rbd) {
+		convHash, err := util.ObjectHash(instance.Spec.ImageConversion.StorageClass)
+		if err != nil {
+			return convHash, changed, err
+		}
+		cacheHash += convHash
+	}
+	// Same for a per-replica staging PVC, while a shared one or an emptyDir
+	// is a regular pod Volume that can be updated on the existing StatefulSet
+	if glanceapi.StagingPVC(instance) {
+		stagingHash, err := util.ObjectHash(instance.Spec.Staging.StorageClass)
+		if err != nil {
+			return stagingHash, changed, err
+		}
+		cacheHash += stagingHash
+	}
+	hash, err := util.ObjectHash((backendHash + storageHash + cacheHash))
+	if err != nil {
+		return hash, changed, err
+	}
+	// An existing GlanceAPI might carry a hash that still includes the PVCs
+	// size: update it without triggering a glanceAPIRefresh
+	if legacyHash, err := legacyBackendHash(backendHash, instance); err == nil &&
+		instance.Status.Hash["backendHash"] == legacyHash {
+		instance.Status.Hash, _ = util.SetHash(instance.Status.Hash, "backendHash", hash)
+		return hash, changed, nil
+	}
+	if hashMap, changed = util.SetHash(instance.Status.Hash, "backendHash", hash); changed {
+		instance.Status.Hash = hashMap
+		Log.Info(fmt.Sprintf("Backend hash %s - %s", "backendHash", hash))
+	}
+	return hash, changed, nil
+}
+
+// legacyBackendHash - returns the backendHash computed by the previous
+// releases, where the PVCs size was part of the storage configuration. Only
+// the Storage fields known by those releases are hashed, so the hash still
+// matches when a new field is set in the same update
+func legacyBackendHash(backendHash string, instance *glancev1.GlanceAPI) (string, error) {
+	storageHash, err := util.ObjectHash(glancev1.Storage{
+		StorageClass:   instance.Spec.Storage.StorageClass,
+		StorageRequest: instance.Spec.Storage.StorageRequest,
+		External:       instance.Spec.Storage.External,
+	})
+	if err != nil {
+		return "", err
+	}
+	cacheHash, err := util.ObjectHash(instance.Spec.ImageCache.Size)
+	if err != nil {
+		return "", err
+	}
+	return util.ObjectHash((backendHash + storageHash + cacheHash))
 }
 
 // ensureKeystoneEndpoints -  create or update keystone endpoints
@@ -1686,7 +2322,7 @@ func (r *GlanceAPIReconciler) cleanupImageCacheJob(
 			if err := r.Get(ctx, types.NamespacedName{
 				Name:      strings.TrimPrefix(pvcName, glance.CachePVCPrefix),
 				Namespace: instance.Namespace,
-			}, &pod); err != nil && k8s_errors.IsNotFound(err) || instance.Spec.ImageCache.Size == "" {
+			}, &pod); err != nil && k8s_errors.IsNotFound(err) || !glanceapi.ImageCachePVC(instance) {
 				// if we have no pod Running with the associated cache pvc,
 				// we can delete the imageCache cronJob if still exists
 				ctrlResult, err := r.deleteJob(ctx, instance, pvcName)
@@ -1768,88 +2404,1094 @@ func (r *GlanceAPIReconciler) getEndpointID(
 	return epID, nil
 }
 
-// glanceAPIRefresh - delete a StateFulSet when a configuration for a Forbidden
-// parameter happens: it might be required if we add / remove a backend (including
-// ceph)
-func (r *GlanceAPIReconciler) glanceAPIRefresh(
+// ensureMetricsService - creates the Service that exposes the metrics
+// sidecars when Metrics are enabled and the monitoring CRDs are installed, so
+// that a ServiceMonitor can select it. The Service is deleted otherwise
+func (r *GlanceAPIReconciler) ensureMetricsService(
 	ctx context.Context,
 	h *helper.Helper,
 	instance *glancev1.GlanceAPI,
+	serviceLabels map[string]string,
 ) error {
 	Log := r.GetLogger(ctx)
-	stsName := instance.Name
-	if instance.Spec.APIType != glancev1.APISingle {
-		stsName = fmt.Sprintf("%s-api", instance.Name)
-	}
-	sts, err := statefulset.GetStatefulSetWithName(ctx, h, stsName, instance.Namespace)
-	if err != nil {
-		if k8s_errors.IsNotFound(err) {
-			// Request object not found
-			Log.Info(fmt.Sprintf("GlanceAPI %s-api: Statefulset not found.", instance.Name))
+	svc := glanceapi.MetricsService(instance, serviceLabels)
+
+	if instance.Spec.Metrics == nil || !r.hasServiceMonitorCRD() {
+		// avoid a Delete call on every reconcile: only a Service that
+		// exists and is owned by this GlanceAPI is removed
+		current := &corev1.Service{}
+		err := r.Get(ctx, types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}, current)
+		if err != nil {
+			if k8s_errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if !metav1.IsControlledBy(current, instance) {
 			return nil
 		}
-		return err
+		err = r.Delete(ctx, current)
+		if err != nil && !k8s_errors.IsNotFound(err) {
+			return err
+		}
+		Log.Info(fmt.Sprintf("Service %s deleted", current.Name))
+		return nil
 	}
-	err = r.Delete(ctx, sts)
-	if err != nil && !k8s_errors.IsNotFound(err) {
-		err = fmt.Errorf("error deleting %s: %w", instance.Name, err)
+
+	desired := svc.DeepCopy()
+	op, err := controllerutil.CreateOrPatch(ctx, r.Client, svc, func() error {
+		svc.Labels = util.MergeStringMaps(svc.Labels, desired.Labels)
+		svc.Spec.Selector = desired.Spec.Selector
+		svc.Spec.Ports = desired.Spec.Ports
+		return controllerutil.SetControllerReference(instance, svc, h.GetScheme())
+	})
+	if err != nil {
 		return err
 	}
+	if op != controllerutil.OperationResultNone {
+		Log.Info(fmt.Sprintf("Service %s successfully reconciled - operation: %s", svc.Name, string(op)))
+	}
 	return nil
 }
 
-// GetHorizonEndpoint - Returns the horizon endpoint set in the CR .Status
-func (r *GlanceAPIReconciler) GetHorizonEndpoint(
+// ensureSharedStagingPVC - creates the ReadWriteMany PVC mounted by all the
+// replicas when a shared staging area is requested. The PVC is owned by the
+// GlanceAPI and it is deleted when the staging area is not shared anymore
+func (r *GlanceAPIReconciler) ensureSharedStagingPVC(
 	ctx context.Context,
 	h *helper.Helper,
 	instance *glancev1.GlanceAPI,
-) (string, error) {
+	serviceLabels map[string]string,
+) error {
 	Log := r.GetLogger(ctx)
 
-	horizon, err := horizonv1.GetHorizon(ctx, h, instance.Namespace)
-	if err != nil {
-		Log.Info("Error getting Horizon CR")
-		return "", err
+	if !glanceapi.SharedStaging(instance) {
+		// only a PVC that exists and is owned by this GlanceAPI is removed
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      glanceapi.SharedStagingPVCName(instance),
+			Namespace: instance.Namespace,
+		}, pvc)
+		if err != nil {
+			if k8s_errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if !metav1.IsControlledBy(pvc, instance) {
+			return nil
+		}
+		err = r.Delete(ctx, pvc)
+		if err != nil && !k8s_errors.IsNotFound(err) {
+			return fmt.Errorf("error deleting PVC %s: %w", pvc.Name, err)
+		}
+		Log.Info(fmt.Sprintf("PVC %s deleted", pvc.Name))
+		return nil
 	}
 
-	ep, err := horizon.GetEndpoint()
+	desired, err := glance.GetPvc(instance, serviceLabels, glance.PvcStaging)
 	if err != nil {
-		Log.Info("No endpoint present in the Status")
-		return "", err
+		return err
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      glanceapi.SharedStagingPVCName(instance),
+			Namespace: instance.Namespace,
+		},
 	}
+	op, err := controllerutil.CreateOrPatch(ctx, r.Client, pvc, func() error {
+		pvc.Labels = util.MergeStringMaps(pvc.Labels, desired.Labels)
+		pvc.Annotations = util.MergeStringMaps(pvc.Annotations, desired.Annotations)
+		// the spec of a bound PVC is immutable, a size increase is handled
+		// by ensurePVCResize
+		if pvc.CreationTimestamp.IsZero() {
+			pvc.Spec = desired.Spec
+		}
+		return controllerutil.SetControllerReference(instance, pvc, h.GetScheme())
+	})
+	if err != nil {
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		Log.Info(fmt.Sprintf("PVC %s successfully reconciled - operation: %s", pvc.Name, string(op)))
+	}
+	return nil
+}
 
-	return ep, nil
+// pendingGlanceRestore - returns the name of a GlanceRestore of the owning
+// Glance that is still recreating the PVCs or loading the database dump. The
+// target Glance is resolved through the GlanceBackup, as the GlanceRestore
+// status is only set once the backup is completed, and a GlanceRestore that
+// has not been reconciled yet is pending. A failed GlanceRestore does not
+// block the GlanceAPI
+func (r *GlanceAPIReconciler) pendingGlanceRestore(
+	ctx context.Context,
+	instance *glancev1.GlanceAPI,
+) (string, error) {
+	glanceName := glance.GetOwningGlanceName(instance)
+	if glanceName == "" {
+		return "", nil
+	}
+	restores := &glancev1.GlanceRestoreList{}
+	if err := r.List(ctx, restores, client.InNamespace(instance.Namespace)); err != nil {
+		return "", fmt.Errorf("listing GlanceRestores: %w", err)
+	}
+	for _, restore := range restores.Items {
+		if !restore.DeletionTimestamp.IsZero() || restore.Status.CompletionTime != nil {
+			continue
+		}
+		if restoreStepDone(&restore, glancev1.RestorePVCReadyCondition) &&
+			(!restore.Spec.RestoreDatabase || restoreStepDone(&restore, glancev1.DatabaseRestoreReadyCondition)) {
+			continue
+		}
+		target := restore.Status.GlanceName
+		if target == "" {
+			backup := &glancev1.GlanceBackup{}
+			err := r.Get(ctx, types.NamespacedName{Name: restore.Spec.BackupName, Namespace: restore.Namespace}, backup)
+			if err != nil {
+				if k8s_errors.IsNotFound(err) {
+					continue
+				}
+				return "", fmt.Errorf("getting GlanceBackup %s: %w", restore.Spec.BackupName, err)
+			}
+			target = backup.Spec.GlanceName
+		}
+		if target != glanceName {
+			continue
+		}
+		return restore.Name, nil
+	}
+	return "", nil
 }
 
-// reconcilePVCLabels ensures backup/restore labels are set on Glance PVCs
-// for upgrades where VolumeClaimTemplate labels were not set at creation time.
-func (r *GlanceAPIReconciler) reconcilePVCLabels(
+// restoreStepDone - returns true when the GlanceRestore condition of a
+// restore step is True, or reports an error the GlanceAPI can't wait on
+func restoreStepDone(restore *glancev1.GlanceRestore, t condition.Type) bool {
+	c := restore.Status.Conditions.Get(t)
+	return c != nil && (c.Status == corev1.ConditionTrue || c.Reason == condition.ErrorReason)
+}
+
+// hasServiceMonitorCRD - returns true when the ServiceMonitor kind from the
+// monitoring.coreos.com API group is served by the cluster. The result is
+// cached for DiscoveryInterval to not query the API server on each reconcile
+func (r *GlanceAPIReconciler) hasServiceMonitorCRD() bool {
+	r.discoveryMu.Lock()
+	defer r.discoveryMu.Unlock()
+
+	if !r.discoveryTime.IsZero() && time.Since(r.discoveryTime) < glance.DiscoveryInterval {
+		return r.serviceMonitorCRD
+	}
+	resources, err := r.Kclient.Discovery().ServerResourcesForGroupVersion(monitoringGroupVersion)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		// do not cache a transient failure
+		return false
+	}
+	r.serviceMonitorCRD = false
+	if err == nil {
+		for _, res := range resources.APIResources {
+			if res.Kind == "ServiceMonitor" {
+				r.serviceMonitorCRD = true
+				break
+			}
+		}
+	}
+	r.discoveryTime = time.Now()
+	return r.serviceMonitorCRD
+}
+
+// glanceAPIRefresh - starts a blue/green replacement of the StatefulSet when
+// a configuration for a Forbidden parameter happens: it might be required if
+// we add / remove a backend (including ceph). A new StatefulSet revision is
+// deployed next to the current one, which keeps serving the API until the
+// new Pods are Ready (see ensureServingRevision).
+// The PVCs of a StatefulSet are bound to its name: when they hold the images
+// of a file backend, or when the running Pods do not carry the RevisionLabel
+// yet, the StatefulSet is deleted and recreated with the same name instead,
+// which stops the API until the new Pods are Ready. It returns true in that
+// case
+func (r *GlanceAPIReconciler) glanceAPIRefresh(
 	ctx context.Context,
+	h *helper.Helper,
 	instance *glancev1.GlanceAPI,
-) error {
-	pvcList := &corev1.PersistentVolumeClaimList{}
-	listOpts := []client.ListOption{
-		client.InNamespace(instance.Namespace),
-		client.MatchingLabels{
-			common.OwnerSelector:     instance.Name,
-			common.ComponentSelector: glance.Component,
-		},
+) (bool, error) {
+	Log := r.GetLogger(ctx)
+	stsName := glanceapi.StatefulSetName(instance, instance.Status.StatefulSetRevision)
+	sts, err := statefulset.GetStatefulSetWithName(ctx, h, stsName, instance.Namespace)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			// Nothing is deployed yet: the StatefulSet is created with the
+			// new configuration
+			Log.Info(fmt.Sprintf("GlanceAPI %s: Statefulset %s not found.", instance.Name, stsName))
+			return false, nil
+		}
+		return false, err
 	}
-	if err := r.List(ctx, pvcList, listOpts...); err != nil {
-		return fmt.Errorf("listing PVCs for %s: %w", instance.Name, err)
+	labelled, err := r.podsHaveRevisionLabel(ctx, instance, sts)
+	if err != nil {
+		return false, err
+	}
+	if glanceapi.LocalFileStore(instance) || !labelled {
+		// The PVCs are owned by the StatefulSet when its retention policy
+		// deletes them: orphan the dependents, the StatefulSet recreated
+		// with the same name adopts them again
+		opts := []client.DeleteOption{}
+		if p := sts.Spec.PersistentVolumeClaimRetentionPolicy; p != nil &&
+			p.WhenDeleted == appsv1.DeletePersistentVolumeClaimRetentionPolicyType {
+			opts = append(opts, client.PropagationPolicy(metav1.DeletePropagationOrphan))
+		}
+		err = r.Delete(ctx, sts, opts...)
+		if err != nil && !k8s_errors.IsNotFound(err) {
+			err = fmt.Errorf("error deleting %s: %w", stsName, err)
+			return false, err
+		}
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, eventReasonStatefulSetRecreated,
+			"StatefulSet %s deleted to apply a backend configuration change, the API is unavailable until it is recreated", stsName)
+		glanceAPIRecreationsTotal.WithLabelValues(instance.Namespace, instance.Name).Inc()
+		return true, nil
+	}
+	instance.Status.StatefulSetRevision++
+	Log.Info(fmt.Sprintf("GlanceAPI %s: rolling out StatefulSet %s", instance.Name,
+		glanceapi.StatefulSetName(instance, instance.Status.StatefulSetRevision)))
+	return false, nil
+}
+
+// podsHaveRevisionLabel - returns true when all the Pods of the StatefulSet
+// carry the RevisionLabel, so that the API Services can keep selecting them
+// while a new revision is rolled out. Pods created before the label was
+// introduced are replaced by a rolling update of the StatefulSet
+func (r *GlanceAPIReconciler) podsHaveRevisionLabel(
+	ctx context.Context,
+	instance *glancev1.GlanceAPI,
+	sts *appsv1.StatefulSet,
+) (bool, error) {
+	if _, ok := sts.Spec.Template.Labels[glanceapi.RevisionLabel]; !ok {
+		return false, nil
+	}
+	pods, err := r.Kclient.CoreV1().Pods(instance.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: metav1.FormatLabelSelector(sts.Spec.Selector),
+	})
+	if err != nil {
+		return false, err
 	}
-	for i := range pvcList.Items {
-		// Skip cache PVCs — they are ephemeral and don't need backup
-		if _, isCache := pvcList.Items[i].Annotations["image-cache"]; isCache {
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !metav1.IsControlledBy(pod, sts) {
 			continue
 		}
-		if _, err := backup.EnsureBackupLabels(ctx, r.Client, &pvcList.Items[i],
-			util.MergeMaps(
-				backup.GetBackupLabels(backup.CategoryControlPlane),
-				backup.GetRestoreLabels(backup.RestoreOrder00, backup.CategoryControlPlane),
-			)); err != nil {
-			return err
+		if _, ok := pod.Labels[glanceapi.RevisionLabel]; !ok {
+			return false, nil
 		}
 	}
-	return nil
+	return true, nil
+}
+
+// ensureServingRevision - points the API Services to the StatefulSet revision
+// being deployed once all its Pods are Ready, and deletes the StatefulSets of
+// the previous revisions, and their PVCs, after the Services have been
+// switched. It returns true when the Services have to be updated in a new
+// reconcile loop
+func (r *GlanceAPIReconciler) ensureServingRevision(
+	ctx context.Context,
+	instance *glancev1.GlanceAPI,
+	sts *appsv1.StatefulSet,
+) (bool, error) {
+	if instance.Status.ServingRevision != instance.Status.StatefulSetRevision {
+		if sts.Generation != sts.Status.ObservedGeneration ||
+			sts.Status.ReadyReplicas != *instance.Spec.Replicas {
+			return false, nil
+		}
+		instance.Status.ServingRevision = instance.Status.StatefulSetRevision
+		return true, nil
+	}
+
+	stsList := &appsv1.StatefulSetList{}
+	if err := r.List(ctx, stsList, client.InNamespace(instance.Namespace),
+		client.MatchingLabels(GetServiceLabels(instance))); err != nil {
+		return false, err
+	}
+	for i := range stsList.Items {
+		old := &stsList.Items[i]
+		if old.Name == sts.Name || !metav1.IsControlledBy(old, instance) {
+			continue
+		}
+		if err := r.Delete(ctx, old); err != nil && !k8s_errors.IsNotFound(err) {
+			return false, fmt.Errorf("error deleting %s: %w", old.Name, err)
+		}
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, eventReasonStatefulSetRecreated,
+			"StatefulSet %s replaced by %s to apply a backend configuration change", old.Name, sts.Name)
+		glanceAPIRecreationsTotal.WithLabelValues(instance.Namespace, instance.Name).Inc()
+	}
+	return false, r.deleteSupersededPVCs(ctx, instance)
+}
+
+// deleteSupersededPVCs - deletes the PVCs of the previous StatefulSet
+// revisions. The image cache, conversion and staging PVCs hold no data that
+// survives a Pod, and they are always deleted; the glance PVCs follow the
+// whenDeleted retention policy, as they might hold the images of a file
+// backend that has been removed
+func (r *GlanceAPIReconciler) deleteSupersededPVCs(
+	ctx context.Context,
+	instance *glancev1.GlanceAPI,
+) error {
+	if instance.Status.StatefulSetRevision == 0 {
+		return nil
+	}
+	pvcList, err := r.listGlanceAPIPVCs(ctx, instance)
+	if err != nil {
+		return err
+	}
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
+		if !pvc.DeletionTimestamp.IsZero() || !supersededPVC(instance, pvc.Name) {
+			continue
+		}
+		_, isCache := pvc.Annotations["image-cache"]
+		_, isConv := pvc.Annotations["image-conversion"]
+		_, isStaging := pvc.Annotations["image-staging"]
+		if !isCache && !isConv && !isStaging &&
+			pvcRetentionPolicy(instance, pvc).WhenDeleted != appsv1.DeletePersistentVolumeClaimRetentionPolicyType {
+			continue
+		}
+		if err := r.Delete(ctx, pvc); err != nil && !k8s_errors.IsNotFound(err) {
+			return fmt.Errorf("error deleting PVC %s: %w", pvc.Name, err)
+		}
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, eventReasonPVCDeleted,
+			"PVC %s deleted with the StatefulSet of a previous revision", pvc.Name)
+	}
+	return nil
+}
+
+// supersededPVC - returns true if pvcName belongs to a StatefulSet revision
+// older than the current one
+func supersededPVC(instance *glancev1.GlanceAPI, pvcName string) bool {
+	for rev := int32(0); rev < instance.Status.StatefulSetRevision; rev++ {
+		if _, ok := glanceapi.PVCOrdinal(pvcName, glanceapi.StatefulSetName(instance, rev)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// GetHorizonEndpoint - Returns the horizon endpoint set in the CR .Status
+func (r *GlanceAPIReconciler) GetHorizonEndpoint(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *glancev1.GlanceAPI,
+) (string, error) {
+	Log := r.GetLogger(ctx)
+
+	horizon, err := horizonv1.GetHorizon(ctx, h, instance.Namespace)
+	if err != nil {
+		Log.Info("Error getting Horizon CR")
+		return "", err
+	}
+
+	ep, err := horizon.GetEndpoint()
+	if err != nil {
+		Log.Info("No endpoint present in the Status")
+		return "", err
+	}
+
+	return ep, nil
+}
+
+// reconcilePVCLabels ensures backup/restore labels are set on Glance PVCs
+// for upgrades where VolumeClaimTemplate labels were not set at creation time.
+func (r *GlanceAPIReconciler) reconcilePVCLabels(
+	ctx context.Context,
+	instance *glancev1.GlanceAPI,
+) error {
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	listOpts := []client.ListOption{
+		client.InNamespace(instance.Namespace),
+		client.MatchingLabels{
+			common.OwnerSelector:     instance.Name,
+			common.ComponentSelector: glance.Component,
+		},
+	}
+	if err := r.List(ctx, pvcList, listOpts...); err != nil {
+		return fmt.Errorf("listing PVCs for %s: %w", instance.Name, err)
+	}
+	for i := range pvcList.Items {
+		// Skip cache PVCs — they are ephemeral and don't need backup
+		if _, isCache := pvcList.Items[i].Annotations["image-cache"]; isCache {
+			continue
+		}
+		// Same for the image conversion work dir and the staging area
+		if _, isConv := pvcList.Items[i].Annotations["image-conversion"]; isConv {
+			continue
+		}
+		if _, isStaging := pvcList.Items[i].Annotations["image-staging"]; isStaging {
+			continue
+		}
+		if _, err := backup.EnsureBackupLabels(ctx, r.Client, &pvcList.Items[i],
+			util.MergeMaps(
+				backup.GetBackupLabels(backup.CategoryControlPlane),
+				backup.GetRestoreLabels(backup.RestoreOrder00, backup.CategoryControlPlane),
+			)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensurePVCResize - expands the PVCs of the current StatefulSet when
+// Storage.StorageRequest or ImageCache.Size are increased. VolumeClaimTemplates
+// are immutable, hence the existing PVCs are patched in place, which requires
+// a StorageClass that allows volume expansion. It returns true when the
+// GlanceAPI should be requeued to track the resize progress, and the
+// StorageClass of the PVCs that can't be expanded, left at their size.
+func (r *GlanceAPIReconciler) ensurePVCResize(
+	ctx context.Context,
+	instance *glancev1.GlanceAPI,
+) (bool, string, error) {
+	Log := r.GetLogger(ctx)
+
+	pvcList, err := r.listGlanceAPIPVCs(ctx, instance)
+	if err != nil {
+		return false, "", err
+	}
+
+	stsName := glanceapi.StatefulSetName(instance, instance.Status.StatefulSetRevision)
+	pending := []string{}
+	notSupported := ""
+	cacheResized := true
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
+		// skip the PVCs created by a previous StatefulSet revision, the
+		// shared staging PVC is not bound to any revision
+		_, ok := glanceapi.PVCOrdinal(pvc.Name, stsName)
+		if !ok && pvc.Name != glanceapi.SharedStagingPVCName(instance) {
+			continue
+		}
+		requestSize := instance.Spec.Storage.StorageRequest
+		_, isCache := pvc.Annotations["image-cache"]
+		if isCache {
+			requestSize = instance.Spec.ImageCache.Size
+		}
+		if _, isConv := pvc.Annotations["image-conversion"]; isConv {
+			requestSize = ""
+			if instance.Spec.ImageConversion != nil {
+				requestSize = instance.Spec.ImageConversion.Size
+			}
+		}
+		if _, isStaging := pvc.Annotations["image-staging"]; isStaging {
+			requestSize = ""
+			if instance.Spec.Staging != nil {
+				requestSize = instance.Spec.Staging.Size
+			}
+		}
+		// a PVC left behind by a disabled image cache, conversion or staging
+		if requestSize == "" {
+			continue
+		}
+		size, err := resource.ParseQuantity(requestSize)
+		if err != nil {
+			return false, "", err
+		}
+		current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if current.Cmp(size) < 0 {
+			expandable, err := r.storageClassAllowsExpansion(ctx, pvc.Spec.StorageClassName)
+			if err != nil {
+				return false, "", err
+			}
+			if !expandable {
+				if notSupported == "" {
+					notSupported = ptr.Deref(pvc.Spec.StorageClassName, "")
+				}
+				if isCache {
+					cacheResized = false
+				}
+				continue
+			}
+			patch := client.MergeFrom(pvc.DeepCopy())
+			if pvc.Spec.Resources.Requests == nil {
+				pvc.Spec.Resources.Requests = corev1.ResourceList{}
+			}
+			pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size
+			if err := r.Patch(ctx, pvc, patch); err != nil {
+				return false, "", fmt.Errorf("error resizing PVC %s: %w", pvc.Name, err)
+			}
+			Log.Info(fmt.Sprintf("PVC %s resized from %s to %s", pvc.Name, current.String(), requestSize))
+		}
+		// The resize is completed when the bound volume reports the new
+		// capacity
+		capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+		if pvc.Status.Phase == corev1.ClaimBound && capacity.Cmp(size) < 0 {
+			pending = append(pending, pvc.Name)
+			if isCache {
+				cacheResized = false
+			}
+		}
+	}
+
+	requeue := false
+	// image_cache_max_size is updated only when the cache PVCs can hold it
+	if cacheResized && instance.Status.ImageCacheSize != instance.Spec.ImageCache.Size {
+		instance.Status.ImageCacheSize = instance.Spec.ImageCache.Size
+		requeue = true
+	}
+	if len(pending) > 0 {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.StorageResizeReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			glancev1.StorageResizeReadyRunningMessage,
+			strings.Join(pending, ", ")))
+		return true, notSupported, nil
+	}
+	instance.Status.Conditions.MarkTrue(
+		glancev1.StorageResizeReadyCondition,
+		glancev1.StorageResizeReadyMessage,
+	)
+	return requeue, notSupported, nil
+}
+
+// storageClassAllowsExpansion - returns true if the referenced StorageClass
+// has allowVolumeExpansion set
+func (r *GlanceAPIReconciler) storageClassAllowsExpansion(
+	ctx context.Context,
+	name *string,
+) (bool, error) {
+	if name == nil || *name == "" {
+		return false, nil
+	}
+	sc := &storagev1.StorageClass{}
+	if err := r.Get(ctx, types.NamespacedName{Name: *name}, sc); err != nil {
+		if k8s_errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return ptr.Deref(sc.AllowVolumeExpansion, false), nil
+}
+
+// listGlanceAPIPVCs - returns the PVCs created for a GlanceAPI, across all
+// the StatefulSet revisions
+func (r *GlanceAPIReconciler) listGlanceAPIPVCs(
+	ctx context.Context,
+	instance *glancev1.GlanceAPI,
+) (*corev1.PersistentVolumeClaimList, error) {
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	listOpts := []client.ListOption{
+		client.InNamespace(instance.Namespace),
+		client.MatchingLabels{
+			common.OwnerSelector:     instance.Name,
+			common.ComponentSelector: glance.Component,
+		},
+	}
+	if err := r.List(ctx, pvcList, listOpts...); err != nil {
+		return nil, fmt.Errorf("listing PVCs for %s: %w", instance.Name, err)
+	}
+	return pvcList, nil
+}
+
+// pvcRetentionPolicy - returns the retention policy that applies to a PVC,
+// based on its kind (glance, glance-cache, glance-conv or glance-staging)
+func pvcRetentionPolicy(
+	instance *glancev1.GlanceAPI,
+	pvc *corev1.PersistentVolumeClaim,
+) glancev1.PVCRetentionPolicy {
+	if _, isCache := pvc.Annotations["image-cache"]; isCache {
+		return instance.Spec.ImageCache.GetRetentionPolicy()
+	}
+	if _, isConv := pvc.Annotations["image-conversion"]; isConv {
+		return instance.Spec.ImageConversion.GetRetentionPolicy()
+	}
+	if _, isStaging := pvc.Annotations["image-staging"]; isStaging {
+		return instance.Spec.Staging.GetRetentionPolicy()
+	}
+	return instance.Spec.Storage.GetRetentionPolicy()
+}
+
+// ensurePVCRetention - aligns the persistentVolumeClaimRetentionPolicy of the
+// StatefulSet to the requested one, and deletes the PVCs of the scaled down
+// replicas whose policy is more aggressive than the StatefulSet one (e.g. the
+// glance-cache PVCs when the image data are retained)
+func (r *GlanceAPIReconciler) ensurePVCRetention(
+	ctx context.Context,
+	instance *glancev1.GlanceAPI,
+	sts *appsv1.StatefulSet,
+) error {
+	Log := r.GetLogger(ctx)
+
+	policy := glanceapi.PVCRetentionPolicy(instance)
+	if policy == nil {
+		return nil
+	}
+	if current := sts.Spec.PersistentVolumeClaimRetentionPolicy; current == nil || *current != *policy {
+		patch := client.MergeFrom(sts.DeepCopy())
+		sts.Spec.PersistentVolumeClaimRetentionPolicy = policy
+		if err := r.Patch(ctx, sts, patch); err != nil {
+			return fmt.Errorf("error updating %s retention policy: %w", sts.Name, err)
+		}
+	}
+
+	pvcList, err := r.listGlanceAPIPVCs(ctx, instance)
+	if err != nil {
+		return err
+	}
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
+		ordinal, ok := glanceapi.PVCOrdinal(pvc.Name, sts.Name)
+		if !ok || ordinal < int(*instance.Spec.Replicas) || !pvc.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if pvcRetentionPolicy(instance, pvc).WhenScaled != appsv1.DeletePersistentVolumeClaimRetentionPolicyType {
+			continue
+		}
+		if err := r.Delete(ctx, pvc); err != nil && !k8s_errors.IsNotFound(err) {
+			return fmt.Errorf("error deleting PVC %s: %w", pvc.Name, err)
+		}
+		Log.Info(fmt.Sprintf("PVC %s deleted after scale down", pvc.Name))
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, eventReasonPVCDeleted,
+			"PVC %s deleted after scale down", pvc.Name)
+	}
+	return nil
+}
+
+// ensureDeletedPVCs - deletes the PVCs whose retention policy is Delete when
+// the GlanceAPI is removed. The StatefulSet only takes care of them when all
+// the PVC kinds agree on the policy
+func (r *GlanceAPIReconciler) ensureDeletedPVCs(
+	ctx context.Context,
+	instance *glancev1.GlanceAPI,
+) error {
+	pvcList, err := r.listGlanceAPIPVCs(ctx, instance)
+	if err != nil {
+		return err
+	}
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
+		if !pvc.DeletionTimestamp.IsZero() ||
+			pvcRetentionPolicy(instance, pvc).WhenDeleted != appsv1.DeletePersistentVolumeClaimRetentionPolicyType {
+			continue
+		}
+		if err := r.Delete(ctx, pvc); err != nil && !k8s_errors.IsNotFound(err) {
+			return fmt.Errorf("error deleting PVC %s: %w", pvc.Name, err)
+		}
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, eventReasonPVCDeleted,
+			"PVC %s deleted with the GlanceAPI", pvc.Name)
+	}
+	return nil
+}
+
+// ensureConsistencyCheckJobs - creates a ConsistencyCheck cronJob for each
+// replica when the GlanceAPI stores the images on per-replica PVCs, and
+// deletes the cronJobs no longer required. The result of the last run of
+// each replica is reported in the status and in the consistency report
+// ConfigMap
+func (r *GlanceAPIReconciler) ensureConsistencyCheckJobs(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *glancev1.GlanceAPI,
+	serviceLabels map[string]string,
+	serviceAnnotations map[string]string,
+) (ctrl.Result, error) {
+	var overallCtrlResult ctrl.Result
+
+	// cronJob name -> replica Pod name
+	required := map[string]string{}
+	if instance.Spec.ConsistencyCheck != nil && !instance.Spec.Storage.External {
+		stsName := glanceapi.StatefulSetName(instance, instance.Status.StatefulSetRevision)
+		for i := int32(0); i < *instance.Spec.Replicas; i++ {
+			podName := fmt.Sprintf("%s-%d", stsName, i)
+			required[fmt.Sprintf("%s-%s", podName, glance.ConsistencyCheck)] = podName
+		}
+	}
+
+	for _, cronJobName := range slices.Sorted(maps.Keys(required)) {
+		pvcName := fmt.Sprintf("%s-%s", glance.ServiceName, required[cronJobName])
+		cronSpec := glance.CronJobSpec{
+			Name:        cronJobName,
+			PvcClaim:    &pvcName,
+			Command:     glanceapi.ConsistencyCheckCommand,
+			CjType:      glance.ConsistencyCheck,
+			Schedule:    instance.Spec.ConsistencyCheck.Schedule,
+			Labels:      serviceLabels,
+			Annotations: serviceAnnotations,
+		}
+		cronjobDef := glanceapi.ConsistencyCheckJob(
+			instance,
+			required[cronJobName],
+			cronSpec,
+		)
+		ctrlResult, err := cronjob.NewCronJob(cronjobDef, glance.ShortDuration).CreateOrPatch(ctx, h)
+		if err != nil {
+			return ctrlResult, err
+		} else if (ctrlResult != ctrl.Result{}) {
+			overallCtrlResult = ctrlResult
+		}
+	}
+
+	// Delete the cronJobs of the replicas removed by a scale down, of a
+	// previous StatefulSet revision, or when the check is disabled
+	cronJobs := &batchv1.CronJobList{}
+	if err := r.List(ctx, cronJobs, client.InNamespace(instance.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+	for _, cj := range cronJobs.Items {
+		if _, ok := required[cj.Name]; ok ||
+			!strings.HasSuffix(cj.Name, "-"+string(glance.ConsistencyCheck)) ||
+			!metav1.IsControlledBy(&cj, instance) {
+			continue
+		}
+		if err := r.Delete(ctx, &cj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !k8s_errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if instance.Spec.ConsistencyCheck == nil {
+		instance.Status.ConsistencyCheck = nil
+		instance.Status.MissingImageFiles = nil
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      glanceapi.ConsistencyReportName(instance),
+				Namespace: instance.Namespace,
+			},
+		}
+		if err := r.Delete(ctx, cm); err != nil && !k8s_errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		return overallCtrlResult, nil
+	}
+
+	return overallCtrlResult, r.reportConsistencyCheck(ctx, h, instance, serviceLabels, required)
+}
+
+// reportConsistencyCheck - reads the summary written by the last run of each
+// ConsistencyCheck cronJob, and publishes it in the status and in the
+// consistency report ConfigMap. An orphan file is found by the replica that
+// holds it, while a file is only missing when no replica holds it: every
+// replica reads the same locations, so the missing files are the locations
+// not counted in the files held by any of them
+func (r *GlanceAPIReconciler) reportConsistencyCheck(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *glancev1.GlanceAPI,
+	serviceLabels map[string]string,
+	cronJobs map[string]string,
+) error {
+	Log := r.GetLogger(ctx)
+
+	results := map[string]glancev1.ConsistencyCheckStatus{}
+	report := map[string]string{}
+	locations, held := 0, 0
+	for cronJobName, podName := range cronJobs {
+		latest, err := latestTerminationMessage(
+			ctx, r.Kclient, instance.Namespace, glance.CronJobNameLabel+"="+cronJobName)
+		if err != nil {
+			return err
+		}
+		if latest == nil {
+			continue
+		}
+		summary := glanceapi.ConsistencyCheckSummary{}
+		if err := json.Unmarshal([]byte(latest.Message), &summary); err != nil {
+			// not a summary, e.g. a traceback of a failed run
+			continue
+		}
+		summary.LastRunTime = latest.FinishedAt.DeepCopy()
+		results[podName] = summary.ConsistencyCheckStatus
+		report[podName+"-orphan-files"] = strings.Join(summary.OrphanFileIDs, "\n")
+		locations = max(locations, summary.Locations)
+		held += summary.HeldFiles
+	}
+	instance.Status.ConsistencyCheck = nil
+	if len(results) > 0 {
+		instance.Status.ConsistencyCheck = results
+	}
+	instance.Status.MissingImageFiles = nil
+	if len(results) > 0 && len(results) == len(cronJobs) {
+		instance.Status.MissingImageFiles = ptr.To(max(locations-held, 0))
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      glanceapi.ConsistencyReportName(instance),
+			Namespace: instance.Namespace,
+		},
+	}
+	op, err := controllerutil.CreateOrPatch(ctx, r.Client, cm, func() error {
+		cm.Labels = util.MergeStringMaps(cm.Labels, serviceLabels)
+		cm.Data = report
+		return controllerutil.SetControllerReference(instance, cm, h.GetScheme())
+	})
+	if err != nil {
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		Log.Info(fmt.Sprintf("ConfigMap %s successfully reconciled - operation: %s", cm.Name, string(op)))
+	}
+	return nil
+}
+
+// ensureStorageCapacity - collects the usage of the image and cache volumes
+// of each replica from the kubelet stats summary of its node, applies the
+// autogrow policy, and sets the StorageCapacity condition according to the
+// monitoring thresholds. The kubelet being unreachable is reported in the
+// condition, not as an error
+func (r *GlanceAPIReconciler) ensureStorageCapacity(
+	ctx context.Context,
+	instance *glancev1.GlanceAPI,
+) error {
+	Log := r.GetLogger(ctx)
+
+	if instance.Spec.Storage.Autogrow == nil {
+		instance.Status.StorageAutogrow = nil
+	}
+	monitoring := instance.Spec.Storage.Monitoring
+	if monitoring == nil && instance.Spec.Storage.Autogrow == nil {
+		instance.Status.StorageCapacity = nil
+		return nil
+	}
+
+	pods, err := r.Kclient.CoreV1().Pods(instance.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: metav1.FormatLabelSelector(&metav1.LabelSelector{
+			MatchLabels: GetServiceLabels(instance),
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
+	stsName := glanceapi.StatefulSetName(instance, instance.Status.StatefulSetRevision)
+	var replicas []corev1.Pod
+	for _, p := range pods.Items {
+		owner := metav1.GetControllerOf(&p)
+		if owner == nil || owner.Kind != "StatefulSet" || owner.Name != stsName || p.Spec.NodeName == "" {
+			continue
+		}
+		replicas = append(replicas, p)
+	}
+	// Every collection updates the status, which triggers a new Reconcile:
+	// the nodes are only polled again once the interval has passed, or for a
+	// replica not reported yet. The StorageCapacity condition is restored
+	// when it is not evaluated
+	if storageCapacityFresh(instance.Status.StorageCapacity, replicas) {
+		return nil
+	}
+
+	capacity := map[string]glancev1.ReplicaStorageStatus{}
+	// the summary is fetched once per node
+	summaries := map[string][]byte{}
+	var failures []string
+	for _, p := range replicas {
+		summary, ok := summaries[p.Spec.NodeName]
+		if !ok {
+			summary, err = r.getNodeStats(ctx, p.Spec.NodeName)
+			if err != nil {
+				Log.Info(fmt.Sprintf("could not get the stats summary of node %s: %s", p.Spec.NodeName, err))
+				failures = append(failures, p.Spec.NodeName)
+				continue
+			}
+			summaries[p.Spec.NodeName] = summary
+		}
+		replica, err := glanceapi.ReplicaStorage(summary, instance.Namespace, p.Name)
+		if err != nil {
+			failures = append(failures, p.Spec.NodeName)
+			continue
+		}
+		if replica != nil {
+			capacity[p.Name] = *replica
+		}
+	}
+	instance.Status.StorageCapacity = capacity
+
+	if instance.Spec.Storage.Autogrow != nil {
+		if err := r.ensurePVCAutogrow(ctx, instance); err != nil {
+			return err
+		}
+	}
+	if monitoring == nil {
+		return nil
+	}
+
+	if len(failures) > 0 {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.StorageCapacityCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			glancev1.StorageCapacityErrorMessage,
+			strings.Join(failures, ", ")))
+		return nil
+	}
+
+	severity := condition.Severity("")
+	var full []string
+	for _, podName := range slices.Sorted(maps.Keys(capacity)) {
+		replica := capacity[podName]
+		for _, v := range []struct {
+			name  string
+			usage *glancev1.VolumeUsage
+		}{
+			{glance.ServiceName, replica.Image},
+			{glance.CacheVolume, replica.Cache},
+		} {
+			if v.usage == nil || v.usage.UsedPercent < monitoring.WarningPercent {
+				continue
+			}
+			full = append(full, fmt.Sprintf("%s/%s %d%%", podName, v.name, v.usage.UsedPercent))
+			if v.usage.UsedPercent >= monitoring.CriticalPercent {
+				severity = condition.SeverityError
+			} else if severity == "" {
+				severity = condition.SeverityWarning
+			}
+		}
+	}
+	if len(full) > 0 {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.StorageCapacityCondition,
+			condition.ErrorReason,
+			severity,
+			glancev1.StorageCapacityLowMessage,
+			strings.Join(full, ", ")))
+		return nil
+	}
+	instance.Status.Conditions.MarkTrue(
+		glancev1.StorageCapacityCondition,
+		glancev1.StorageCapacityMessage,
+	)
+	return nil
+}
+
+// getNodeStats - returns the kubelet stats summary of the node nodeName
+func (r *GlanceAPIReconciler) getNodeStats(
+	ctx context.Context,
+	nodeName string,
+) ([]byte, error) {
+	if r.NodeStats != nil {
+		return r.NodeStats(ctx, nodeName)
+	}
+	return r.Kclient.CoreV1().RESTClient().Get().
+		AbsPath("/api/v1/nodes", nodeName, "proxy", "stats", "summary").
+		DoRaw(ctx)
+}
+
+// storageCapacityFresh - true when the usage of every replica has been
+// collected in the last StorageCapacityInterval. The periodic requeue may come
+// slightly before the interval has passed, hence the NormalDuration margin
+func storageCapacityFresh(
+	capacity map[string]glancev1.ReplicaStorageStatus,
+	replicas []corev1.Pod,
+) bool {
+	for _, p := range replicas {
+		replica, ok := capacity[p.Name]
+		if !ok || time.Since(replica.LastUpdateTime.Time) >= glance.StorageCapacityInterval-glance.NormalDuration {
+			return false
+		}
+	}
+	return len(replicas) > 0
+}
+
+// ensurePVCAutogrow - expands the glance PVC of the replicas whose image
+// volume is used above the autogrow threshold, by the policy increment and up
+// to its maximum size. A PVC is not expanded again until the previous
+// expansion is completed: the volume and its file system are resized, and
+// the kubelet reports the new file system size
+func (r *GlanceAPIReconciler) ensurePVCAutogrow(
+	ctx context.Context,
+	instance *glancev1.GlanceAPI,
+) error {
+	Log := r.GetLogger(ctx)
+
+	autogrow := instance.Spec.Storage.Autogrow
+	increment, err := resource.ParseQuantity(autogrow.Increment)
+	if err != nil {
+		return err
+	}
+	maximum, err := resource.ParseQuantity(autogrow.MaximumSize)
+	if err != nil {
+		return err
+	}
+
+	if instance.Status.StorageAutogrow == nil {
+		instance.Status.StorageAutogrow = map[string]glancev1.PVCAutogrowStatus{}
+	}
+	for _, podName := range slices.Sorted(maps.Keys(instance.Status.StorageCapacity)) {
+		usage := instance.Status.StorageCapacity[podName].Image
+		if usage == nil || usage.UsedPercent < autogrow.ThresholdPercent {
+			continue
+		}
+		pvcName := fmt.Sprintf("%s-%s", glance.ServiceName, podName)
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := r.Get(ctx, types.NamespacedName{Name: pvcName, Namespace: instance.Namespace}, pvc)
+		if err != nil {
+			if k8s_errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+		if capacity.Cmp(current) < 0 || pvcResizing(pvc) {
+			// the previous expansion is still in progress
+			continue
+		}
+
+		status := instance.Status.StorageAutogrow[pvcName]
+		if !status.FilesystemCapacity.IsZero() && usage.Capacity.Cmp(status.FilesystemCapacity) <= 0 {
+			// the usage has been collected before the file system resize
+			continue
+		}
+		if current.Cmp(maximum) >= 0 {
+			if !status.LimitReached {
+				r.Recorder.Eventf(instance, corev1.EventTypeWarning, eventReasonPVCAutogrowLimitReached,
+					"PVC %s is %d%% used and can't be expanded beyond %s",
+					pvcName, usage.UsedPercent, autogrow.MaximumSize)
+				status.Size = current.String()
+				status.LimitReached = true
+				instance.Status.StorageAutogrow[pvcName] = status
+			}
+			continue
+		}
+		expandable, err := r.storageClassAllowsExpansion(ctx, pvc.Spec.StorageClassName)
+		if err != nil {
+			return err
+		}
+		if !expandable {
+			Log.Info(fmt.Sprintf("PVC %s is %d%% used but StorageClass %s does not allow volume expansion",
+				pvcName, usage.UsedPercent, ptr.Deref(pvc.Spec.StorageClassName, "")))
+			continue
+		}
+
+		size := current.DeepCopy()
+		size.Add(increment)
+		if size.Cmp(maximum) > 0 {
+			size = maximum.DeepCopy()
+		}
+		patch := client.MergeFrom(pvc.DeepCopy())
+		if pvc.Spec.Resources.Requests == nil {
+			pvc.Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size
+		if err := r.Patch(ctx, pvc, patch); err != nil {
+			return fmt.Errorf("error expanding PVC %s: %w", pvcName, err)
+		}
+		Log.Info(fmt.Sprintf("PVC %s expanded from %s to %s", pvcName, current.String(), size.String()))
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, eventReasonPVCExpanded,
+			"PVC %s expanded from %s to %s (%d%% used)",
+			pvcName, current.String(), size.String(), usage.UsedPercent)
+		instance.Status.StorageAutogrow[pvcName] = glancev1.PVCAutogrowStatus{
+			Size:               size.String(),
+			Expansions:         status.Expansions + 1,
+			LastExpansionTime:  metav1.Now(),
+			LimitReached:       size.Cmp(maximum) >= 0,
+			FilesystemCapacity: usage.Capacity.DeepCopy(),
+		}
+	}
+	return nil
+}
+
+// pvcResizing - true while the volume of the PVC or its file system is being
+// resized
+func pvcResizing(pvc *corev1.PersistentVolumeClaim) bool {
+	for _, c := range pvc.Status.Conditions {
+		if (c.Type == corev1.PersistentVolumeClaimResizing ||
+			c.Type == corev1.PersistentVolumeClaimFileSystemResizePending) &&
+			c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,500 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	glancev1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/glance-operator/internal/glance"
+	"github.com/openstack-k8s-operators/glance-operator/internal/glanceapi"
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/job"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// GlanceBackupReconciler reconciles a GlanceBackup object
+type GlanceBackupReconciler struct {
+	client.Client
+	Kclient  kubernetes.Interface
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// cached result of the VolumeSnapshot discovery
+	discoveryMu       sync.Mutex
+	volumeSnapshotCRD bool
+	discoveryTime     time.Time
+}
+
+// GetLogger returns a logger object with a prefix of "controller.name" and additional controller context fields
+func (r *GlanceBackupReconciler) GetLogger(ctx context.Context) logr.Logger {
+	return log.FromContext(ctx).WithName("Controllers").WithName("GlanceBackup")
+}
+
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glancebackups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glancebackups/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glancebackups/finalizers,verbs=update;patch
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glances,verbs=get;list;watch
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glanceapis,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;create;update;delete;watch;patch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete;
+// +kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create;delete
+
+// Reconcile reconcile GlanceBackup requests
+func (r *GlanceBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, _err error) {
+	Log := r.GetLogger(ctx)
+
+	instance := &glancev1.GlanceBackup{}
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			// Snapshots, database PVC and Job are owned by the GlanceBackup
+			// and garbage collected
+			return ctrl.Result{}, nil
+		}
+		Log.Error(err, fmt.Sprintf("could not fetch GlanceBackup instance %s", instance.Name))
+		return ctrl.Result{}, err
+	}
+
+	helper, err := helper.NewHelper(
+		instance,
+		r.Client,
+		r.Kclient,
+		r.Scheme,
+		Log,
+	)
+	if err != nil {
+		Log.Error(err, fmt.Sprintf("could not instantiate helper for instance %s", instance.Name))
+		return ctrl.Result{}, err
+	}
+
+	// A completed backup is never taken again
+	if instance.Status.CompletionTime != nil {
+		return ctrl.Result{}, nil
+	}
+
+	isNewInstance := instance.Status.Conditions == nil
+	if isNewInstance {
+		instance.Status.Conditions = condition.Conditions{}
+	}
+	savedConditions := instance.Status.Conditions.DeepCopy()
+
+	defer func() {
+		// Don't update the status, if Reconciler Panics
+		if rc := recover(); rc != nil {
+			Log.Info(fmt.Sprintf("Panic during reconcile %v\n", rc))
+			panic(rc)
+		}
+		condition.RestoreLastTransitionTimes(
+			&instance.Status.Conditions, savedConditions)
+		if instance.Status.Conditions.IsUnknown(condition.ReadyCondition) {
+			instance.Status.Conditions.Set(
+				instance.Status.Conditions.Mirror(condition.ReadyCondition))
+		}
+		err := helper.PatchInstance(ctx, instance)
+		if err != nil {
+			_err = err
+			return
+		}
+	}()
+
+	cl := condition.CreateList(
+		condition.UnknownCondition(condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage),
+		condition.UnknownCondition(glancev1.SnapshotsReadyCondition, condition.InitReason, glancev1.SnapshotsReadyInitMessage),
+		condition.UnknownCondition(glancev1.DatabaseBackupReadyCondition, condition.InitReason, glancev1.DatabaseBackupReadyInitMessage),
+	)
+	instance.Status.Conditions.Init(&cl)
+	instance.Status.ObservedGeneration = instance.Generation
+
+	if isNewInstance {
+		// Register overall status immediately to have an early feedback e.g. in the cli
+		return ctrl.Result{}, nil
+	}
+
+	if instance.Status.Hash == nil {
+		instance.Status.Hash = map[string]string{}
+	}
+
+	return r.reconcileNormal(ctx, instance, helper)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GlanceBackupReconciler) SetupWithManager(_ context.Context, mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&glancev1.GlanceBackup{}).
+		Owns(&batchv1.Job{}).
+		Owns(&corev1.PersistentVolumeClaim{}).
+		Complete(r)
+}
+
+func (r *GlanceBackupReconciler) reconcileNormal(
+	ctx context.Context,
+	instance *glancev1.GlanceBackup,
+	h *helper.Helper,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	glanceInstance := &glancev1.Glance{}
+	err := r.Get(ctx, types.NamespacedName{Name: instance.Spec.GlanceName, Namespace: instance.Namespace}, glanceInstance)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			Log.Info(fmt.Sprintf("Glance %s not found", instance.Spec.GlanceName))
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				glancev1.SnapshotsReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				glancev1.GlanceBackupWaitingMessage,
+				instance.Spec.GlanceName))
+			return glance.ResultRequeue, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// The database is dumped before the PVCs are snapshotted: an image
+	// uploaded in between leaves an orphan file behind, rather than a
+	// database record without its data
+	ctrlResult, err := r.ensureDatabaseBackup(ctx, h, instance, glanceInstance)
+	if err != nil || (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, err
+	}
+
+	ctrlResult, err = r.ensureSnapshots(ctx, h, instance, glanceInstance)
+	if err != nil || (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, err
+	}
+
+	instance.Status.CompletionTime = ptr.To(metav1.Now())
+	instance.Status.Conditions.MarkTrue(condition.ReadyCondition, condition.ReadyMessage)
+	Log.Info(fmt.Sprintf("GlanceBackup %s completed", instance.Name))
+	return ctrl.Result{}, nil
+}
+
+// ensureSnapshots - takes a VolumeSnapshot of every image PVC of the
+// GlanceAPIs that belong to the Glance and waits for them to be ready to use
+func (r *GlanceBackupReconciler) ensureSnapshots(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *glancev1.GlanceBackup,
+	glanceInstance *glancev1.Glance,
+) (ctrl.Result, error) {
+	setError := func(err error) (ctrl.Result, error) {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.SnapshotsReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			glancev1.SnapshotsReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	// The set of PVCs is frozen once discovered: PVCs that show up later
+	// (e.g. a scale up) are not part of this backup
+	if instance.Status.Volumes == nil {
+		volumes, err := r.getImageVolumes(ctx, instance, glanceInstance)
+		if err != nil {
+			return setError(err)
+		}
+		instance.Status.Volumes = volumes
+	}
+	if len(instance.Status.Volumes) == 0 {
+		instance.Status.Conditions.MarkTrue(glancev1.SnapshotsReadyCondition, glancev1.SnapshotsReadyMessage)
+		return ctrl.Result{}, nil
+	}
+
+	if !r.hasVolumeSnapshotCRD() {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.SnapshotsReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			glancev1.SnapshotsNotSupportedMessage))
+		return glance.ResultRequeue, nil
+	}
+
+	pending := []string{}
+	for i := range instance.Status.Volumes {
+		vol := &instance.Status.Volumes[i]
+		snap, err := r.ensureVolumeSnapshot(ctx, h, instance, vol)
+		if err != nil {
+			return setError(err)
+		}
+		ready, _, _ := unstructured.NestedBool(snap.Object, "status", "readyToUse")
+		if msg, found, _ := unstructured.NestedString(snap.Object, "status", "error", "message"); found && msg != "" {
+			return setError(fmt.Errorf("%w: VolumeSnapshot %s: %s", ErrVolumeSnapshot, vol.SnapshotName, msg))
+		}
+		vol.ReadyToUse = ready
+		if !ready {
+			pending = append(pending, vol.SnapshotName)
+		}
+	}
+	if len(pending) > 0 {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.SnapshotsReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			glancev1.SnapshotsReadyRunningMessage,
+			strings.Join(pending, ",")))
+		return glance.ResultRequeue, nil
+	}
+	instance.Status.Conditions.MarkTrue(glancev1.SnapshotsReadyCondition, glancev1.SnapshotsReadyMessage)
+	return ctrl.Result{}, nil
+}
+
+// getImageVolumes - returns the image PVCs of the StatefulSets deployed for
+// the GlanceAPIs that belong to the Glance. Cache, conversion and staging
+// PVCs only hold transient data and are not backed up
+func (r *GlanceBackupReconciler) getImageVolumes(
+	ctx context.Context,
+	instance *glancev1.GlanceBackup,
+	glanceInstance *glancev1.Glance,
+) ([]glancev1.GlanceBackupVolume, error) {
+	volumes := []glancev1.GlanceBackupVolume{}
+
+	apis := &glancev1.GlanceAPIList{}
+	if err := r.List(ctx, apis, client.InNamespace(instance.Namespace)); err != nil {
+		return nil, fmt.Errorf("listing GlanceAPIs: %w", err)
+	}
+	for _, api := range apis.Items {
+		if glance.GetOwningGlanceName(&api) != glanceInstance.Name {
+			continue
+		}
+		pvcList := &corev1.PersistentVolumeClaimList{}
+		listOpts := []client.ListOption{
+			client.InNamespace(instance.Namespace),
+			client.MatchingLabels{
+				common.OwnerSelector:     api.Name,
+				common.ComponentSelector: glance.Component,
+			},
+		}
+		if err := r.List(ctx, pvcList, listOpts...); err != nil {
+			return nil, fmt.Errorf("listing PVCs for %s: %w", api.Name, err)
+		}
+		stsName := glanceapi.StatefulSetName(&api, api.Status.StatefulSetRevision)
+		for _, pvc := range pvcList.Items {
+			if _, isCache := pvc.Annotations["image-cache"]; isCache {
+				continue
+			}
+			if _, isConv := pvc.Annotations["image-conversion"]; isConv {
+				continue
+			}
+			if _, isStaging := pvc.Annotations["image-staging"]; isStaging {
+				continue
+			}
+			// Skip the PVCs left behind by a previous StatefulSet revision
+			ordinal, ok := glanceapi.PVCOrdinal(pvc.Name, stsName)
+			if !ok {
+				continue
+			}
+			storageClass := ""
+			if pvc.Spec.StorageClassName != nil {
+				storageClass = *pvc.Spec.StorageClassName
+			}
+			size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			// The PVC is restored for the first StatefulSet revision
+			pvcLabels := maps.Clone(pvc.Labels)
+			delete(pvcLabels, glanceapi.RevisionLabel)
+			volumes = append(volumes, glancev1.GlanceBackupVolume{
+				SourcePVCName: pvc.Name,
+				PVCName: fmt.Sprintf("%s-%s-%d", glance.ServiceName,
+					glanceapi.StatefulSetName(&api, 0), ordinal),
+				SnapshotName:     fmt.Sprintf("%s-%s", instance.Name, pvc.Name),
+				StorageClassName: storageClass,
+				StorageRequest:   size.String(),
+				Labels:           pvcLabels,
+			})
+		}
+	}
+	return volumes, nil
+}
+
+// ensureVolumeSnapshot - creates the VolumeSnapshot of a PVC, owned by the
+// GlanceBackup, and returns it. The VolumeSnapshot spec is immutable
+func (r *GlanceBackupReconciler) ensureVolumeSnapshot(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *glancev1.GlanceBackup,
+	vol *glancev1.GlanceBackupVolume,
+) (*unstructured.Unstructured, error) {
+	Log := r.GetLogger(ctx)
+
+	snap := newVolumeSnapshot(vol.SnapshotName, instance.Namespace)
+	err := r.Get(ctx, types.NamespacedName{Name: vol.SnapshotName, Namespace: instance.Namespace}, snap)
+	if err == nil {
+		return snap, nil
+	}
+	if !k8s_errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	snap = newVolumeSnapshot(vol.SnapshotName, instance.Namespace)
+	spec := map[string]any{
+		"source": map[string]any{
+			"persistentVolumeClaimName": vol.SourcePVCName,
+		},
+	}
+	if instance.Spec.VolumeSnapshotClassName != "" {
+		spec["volumeSnapshotClassName"] = instance.Spec.VolumeSnapshotClassName
+	}
+	if err := unstructured.SetNestedMap(snap.Object, spec, "spec"); err != nil {
+		return nil, err
+	}
+	if err := controllerutil.SetControllerReference(instance, snap, h.GetScheme()); err != nil {
+		return nil, err
+	}
+	if err := r.Create(ctx, snap); err != nil {
+		return nil, fmt.Errorf("error creating VolumeSnapshot %s: %w", vol.SnapshotName, err)
+	}
+	Log.Info(fmt.Sprintf("VolumeSnapshot %s of PVC %s created", vol.SnapshotName, vol.SourcePVCName))
+	return snap, nil
+}
+
+// ensureDatabaseBackup - creates the PVC holding the database dump and runs
+// the Job that takes it
+func (r *GlanceBackupReconciler) ensureDatabaseBackup(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *glancev1.GlanceBackup,
+	glanceInstance *glancev1.Glance,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+	setError := func(err error) (ctrl.Result, error) {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.DatabaseBackupReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			glancev1.DatabaseBackupReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	backupLabels := map[string]string{
+		common.AppSelector:   glance.ServiceName,
+		common.OwnerSelector: instance.Name,
+	}
+
+	desired, err := glance.DatabaseBackupPVC(glanceInstance, instance, backupLabels)
+	if err != nil {
+		return setError(err)
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      desired.Name,
+			Namespace: desired.Namespace,
+		},
+	}
+	op, err := controllerutil.CreateOrPatch(ctx, r.Client, pvc, func() error {
+		pvc.Labels = desired.Labels
+		if pvc.CreationTimestamp.IsZero() {
+			pvc.Spec = desired.Spec
+		}
+		return controllerutil.SetControllerReference(instance, pvc, h.GetScheme())
+	})
+	if err != nil {
+		return setError(err)
+	}
+	if op != controllerutil.OperationResultNone {
+		Log.Info(fmt.Sprintf("PVC %s successfully reconciled - operation: %s", pvc.Name, string(op)))
+	}
+	instance.Status.DatabaseBackupPVC = pvc.Name
+
+	jobDef := glance.DatabaseBackupJob(glanceInstance, instance, backupLabels, map[string]string{})
+	backupJob := job.NewJob(
+		jobDef,
+		glancev1.DatabaseBackupHash,
+		glanceInstance.Spec.PreserveJobs,
+		glance.ShortDuration,
+		instance.Status.Hash[glancev1.DatabaseBackupHash],
+	)
+	ctrlResult, err := backupJob.DoJob(ctx, h)
+	if (ctrlResult != ctrl.Result{}) {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.DatabaseBackupReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			glancev1.DatabaseBackupReadyRunningMessage))
+		return ctrlResult, nil
+	}
+	if err != nil {
+		return setError(err)
+	}
+	if backupJob.HasChanged() {
+		instance.Status.Hash[glancev1.DatabaseBackupHash] = backupJob.GetHash()
+		Log.Info(fmt.Sprintf("Service '%s' - Job %s hash added - %s", instance.Name, jobDef.Name, instance.Status.Hash[glancev1.DatabaseBackupHash]))
+	}
+	instance.Status.Conditions.MarkTrue(glancev1.DatabaseBackupReadyCondition, glancev1.DatabaseBackupReadyMessage)
+	return ctrl.Result{}, nil
+}
+
+// newVolumeSnapshot - returns an empty VolumeSnapshot object. The type is
+// handled as unstructured so that the snapshot CRDs are only required when
+// there is something to snapshot
+func newVolumeSnapshot(name string, namespace string) *unstructured.Unstructured {
+	snap := &unstructured.Unstructured{}
+	snap.SetGroupVersionKind(schema.FromAPIVersionAndKind(glance.SnapshotGroupVersion, glance.SnapshotKind))
+	snap.SetName(name)
+	snap.SetNamespace(namespace)
+	return snap
+}
+
+// hasVolumeSnapshotCRD - returns true when the VolumeSnapshot kind from the
+// snapshot.storage.k8s.io API group is served by the cluster. The result is
+// cached for DiscoveryInterval to not query the API server on each reconcile
+func (r *GlanceBackupReconciler) hasVolumeSnapshotCRD() bool {
+	r.discoveryMu.Lock()
+	defer r.discoveryMu.Unlock()
+
+	if !r.discoveryTime.IsZero() && time.Since(r.discoveryTime) < glance.DiscoveryInterval {
+		return r.volumeSnapshotCRD
+	}
+	resources, err := r.Kclient.Discovery().ServerResourcesForGroupVersion(glance.SnapshotGroupVersion)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		// do not cache a transient failure
+		return false
+	}
+	r.volumeSnapshotCRD = false
+	if err == nil {
+		for _, res := range resources.APIResources {
+			if res.Kind == glance.SnapshotKind {
+				r.volumeSnapshotCRD = true
+				break
+			}
+		}
+	}
+	r.discoveryTime = time.Now()
+	return r.volumeSnapshotCRD
+}
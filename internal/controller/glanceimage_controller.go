@@ -0,0 +1,586 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/gophercloud/gophercloud/v2"
+	gophercloud_openstack "github.com/gophercloud/gophercloud/v2/openstack"
+	"github.com/gophercloud/gophercloud/v2/openstack/image/v2/imageimport"
+	"github.com/gophercloud/gophercloud/v2/openstack/image/v2/images"
+	glancev1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/glance-operator/internal/glance"
+	"github.com/openstack-k8s-operators/glance-operator/internal/glanceapi"
+	keystonev1 "github.com/openstack-k8s-operators/keystone-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/job"
+	batchv1 "k8s.io/api/batch/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ImageClientFunc - returns the client of the image API served by a GlanceAPI
+type ImageClientFunc func(
+	ctx context.Context,
+	h *helper.Helper,
+	api *glancev1.GlanceAPI,
+) (*gophercloud.ServiceClient, ctrl.Result, error)
+
+// GlanceImageReconciler reconciles a GlanceImage object
+type GlanceImageReconciler struct {
+	client.Client
+	Kclient  kubernetes.Interface
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// ImageClient - returns the image API client, GetImageServiceClient
+	// when nil
+	ImageClient ImageClientFunc
+}
+
+// GetLogger returns a logger object with a prefix of "controller.name" and additional controller context fields
+func (r *GlanceImageReconciler) GetLogger(ctx context.Context) logr.Logger {
+	return log.FromContext(ctx).WithName("Controllers").WithName("GlanceImage")
+}
+
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glanceimages,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glanceimages/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glanceimages/finalizers,verbs=update;patch
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glances,verbs=get;list;watch
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glanceapis,verbs=get;list;watch
+// +kubebuilder:rbac:groups=keystone.openstack.org,resources=keystoneapis,verbs=get;list;watch;
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete;
+
+// Reconcile reconcile GlanceImage requests
+func (r *GlanceImageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, _err error) {
+	Log := r.GetLogger(ctx)
+
+	instance := &glancev1.GlanceImage{}
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		Log.Error(err, fmt.Sprintf("could not fetch GlanceImage instance %s", instance.Name))
+		return ctrl.Result{}, err
+	}
+
+	helper, err := helper.NewHelper(
+		instance,
+		r.Client,
+		r.Kclient,
+		r.Scheme,
+		Log,
+	)
+	if err != nil {
+		Log.Error(err, fmt.Sprintf("could not instantiate helper for instance %s", instance.Name))
+		return ctrl.Result{}, err
+	}
+
+	isNewInstance := instance.Status.Conditions == nil
+	if isNewInstance {
+		instance.Status.Conditions = condition.Conditions{}
+	}
+	savedConditions := instance.Status.Conditions.DeepCopy()
+
+	defer func() {
+		// Don't update the status, if Reconciler Panics
+		if rc := recover(); rc != nil {
+			Log.Info(fmt.Sprintf("Panic during reconcile %v\n", rc))
+			panic(rc)
+		}
+		condition.RestoreLastTransitionTimes(
+			&instance.Status.Conditions, savedConditions)
+		if instance.Status.Conditions.IsUnknown(condition.ReadyCondition) {
+			instance.Status.Conditions.Set(
+				instance.Status.Conditions.Mirror(condition.ReadyCondition))
+		}
+		err := helper.PatchInstance(ctx, instance)
+		if err != nil {
+			_err = err
+			return
+		}
+	}()
+
+	cl := condition.CreateList(
+		condition.UnknownCondition(condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage),
+		condition.UnknownCondition(condition.InputReadyCondition, condition.InitReason, condition.InputReadyInitMessage),
+		condition.UnknownCondition(glancev1.ImageReadyCondition, condition.InitReason, glancev1.ImageReadyInitMessage),
+	)
+	instance.Status.Conditions.Init(&cl)
+	instance.Status.ObservedGeneration = instance.Generation
+
+	// If we're not deleting this and the service object doesn't have our finalizer, add it.
+	if instance.DeletionTimestamp.IsZero() && controllerutil.AddFinalizer(instance, helper.GetFinalizer()) || isNewInstance {
+		// Register overall status immediately to have an early feedback e.g. in the cli
+		return ctrl.Result{}, nil
+	}
+
+	if instance.Status.Hash == nil {
+		instance.Status.Hash = map[string]string{}
+	}
+
+	if !instance.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, instance, helper)
+	}
+	return r.reconcileNormal(ctx, instance, helper)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GlanceImageReconciler) SetupWithManager(_ context.Context, mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&glancev1.GlanceImage{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
+
+func (r *GlanceImageReconciler) reconcileDelete(
+	ctx context.Context,
+	instance *glancev1.GlanceImage,
+	h *helper.Helper,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+	Log.Info(fmt.Sprintf("Reconciling GlanceImage '%s' delete", instance.Name))
+
+	// The image is left behind when Glance is gone: there is no API left to
+	// delete it from
+	api, err := r.getGlanceAPI(ctx, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if api != nil && instance.Status.ImageID != "" {
+		imageClient, ctrlResult, err := r.getImageClient(ctx, h, api)
+		if err != nil || (ctrlResult != ctrl.Result{}) {
+			return ctrlResult, err
+		}
+		err = images.Delete(ctx, imageClient, instance.Status.ImageID).ExtractErr()
+		if err != nil && !gophercloud.ResponseCodeIs(err, http.StatusNotFound) {
+			return ctrl.Result{}, fmt.Errorf("deleting image %s: %w", instance.Status.ImageID, err)
+		}
+		Log.Info(fmt.Sprintf("Image %s deleted", instance.Status.ImageID))
+	}
+
+	controllerutil.RemoveFinalizer(instance, h.GetFinalizer())
+	Log.Info(fmt.Sprintf("Reconciled GlanceImage '%s' delete successfully", instance.Name))
+	return ctrl.Result{}, nil
+}
+
+func (r *GlanceImageReconciler) reconcileNormal(
+	ctx context.Context,
+	instance *glancev1.GlanceImage,
+	h *helper.Helper,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	if (instance.Spec.Source.URL == "") == (instance.Spec.Source.PVC == nil) {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityError,
+			glancev1.ImageSourceErrorMessage))
+		return ctrl.Result{}, nil
+	}
+
+	api, err := r.getGlanceAPI(ctx, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if api == nil || !api.IsReady() {
+		Log.Info(fmt.Sprintf("GlanceAPI of Glance %s not ready", instance.Spec.GlanceName))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			glancev1.ImageWaitingMessage,
+			instance.Spec.GlanceName))
+		return glance.ResultRequeue, nil
+	}
+	stores := getEnabledStores(api)
+	for _, store := range instance.Spec.Stores {
+		if !slices.Contains(stores, store) {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				condition.InputReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityError,
+				glancev1.ImageStoreErrorMessage,
+				store,
+				api.Name))
+			return ctrl.Result{}, nil
+		}
+	}
+	instance.Status.Conditions.MarkTrue(condition.InputReadyCondition, condition.InputReadyMessage)
+
+	imageClient, ctrlResult, err := r.getImageClient(ctx, h, api)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.ImageReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			glancev1.ImageReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	if (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, nil
+	}
+
+	image, err := r.ensureImage(ctx, imageClient, instance)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.ImageReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			glancev1.ImageReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	instance.Status.ImageID = image.ID
+	instance.Status.ImageStatus = string(image.Status)
+	instance.Status.Stores = imageStoreList(image, "stores")
+
+	switch image.Status {
+	case images.ImageStatusActive:
+		instance.Status.Conditions.MarkTrue(glancev1.ImageReadyCondition, glancev1.ImageReadyMessage)
+		instance.Status.Conditions.MarkTrue(condition.ReadyCondition, condition.ReadyMessage)
+		return ctrl.Result{}, nil
+
+	case images.ImageStatusQueued:
+		if failed := imageStoreList(image, "os_glance_failed_import"); len(failed) > 0 {
+			// The data is not imported again: the GlanceImage has to be
+			// recreated once the cause of the failure is fixed
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				glancev1.ImageReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				glancev1.ImageImportFailedMessage,
+				image.ID,
+				strings.Join(failed, ",")))
+			return ctrl.Result{}, nil
+		}
+		if image.Properties["os_glance_import_task"] != nil {
+			// the import task has not started yet
+			break
+		}
+		if instance.Spec.Source.PVC != nil {
+			return r.stageImage(ctx, h, instance, api)
+		}
+		err = r.importImage(ctx, imageClient, instance, imageimport.WebDownloadMethod)
+
+	case images.ImageStatusUploading:
+		// The data has been staged by the upload Job
+		err = r.importImage(ctx, imageClient, instance, imageimport.GlanceDirectMethod)
+
+	case images.ImageStatusImporting, images.ImageStatusSaving:
+
+	default:
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.ImageReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			glancev1.ImageReadyErrorMessage,
+			fmt.Sprintf("image %s is %s", image.ID, image.Status)))
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.ImageReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			glancev1.ImageReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	instance.Status.Conditions.Set(condition.FalseCondition(
+		glancev1.ImageReadyCondition,
+		condition.RequestedReason,
+		condition.SeverityInfo,
+		glancev1.ImageReadyRunningMessage,
+		image.ID,
+		image.Status))
+	return glance.ResultRequeue, nil
+}
+
+// getGlanceAPI - returns the GlanceAPI the image is created through, nil
+// when the Glance or the GlanceAPI do not exist
+func (r *GlanceImageReconciler) getGlanceAPI(
+	ctx context.Context,
+	instance *glancev1.GlanceImage,
+) (*glancev1.GlanceAPI, error) {
+	glanceInstance := &glancev1.Glance{}
+	err := r.Get(ctx, types.NamespacedName{Name: instance.Spec.GlanceName, Namespace: instance.Namespace}, glanceInstance)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return GetKeystoneGlanceAPI(ctx, r.Client, glanceInstance)
+}
+
+func (r *GlanceImageReconciler) getImageClient(
+	ctx context.Context,
+	h *helper.Helper,
+	api *glancev1.GlanceAPI,
+) (*gophercloud.ServiceClient, ctrl.Result, error) {
+	if r.ImageClient != nil {
+		return r.ImageClient(ctx, h, api)
+	}
+	return GetImageServiceClient(ctx, h, api)
+}
+
+// GetImageServiceClient - returns an image API client authenticated as the
+// keystone admin user, reaching the internal endpoint of the image service
+func GetImageServiceClient(
+	ctx context.Context,
+	h *helper.Helper,
+	api *glancev1.GlanceAPI,
+) (*gophercloud.ServiceClient, ctrl.Result, error) {
+	keystoneAPI, err := keystonev1.GetKeystoneAPI(ctx, h, api.Namespace, map[string]string{})
+	if err != nil {
+		return nil, ctrl.Result{}, err
+	}
+	scope := &gophercloud.AuthScope{
+		ProjectName: keystoneAPI.Spec.AdminProject,
+		DomainName:  "Default",
+	}
+	//nolint:staticcheck // SA1019: Using deprecated function until migration is complete
+	o, ctrlResult, err := keystonev1.GetScopedAdminServiceClient(ctx, h, keystoneAPI, scope)
+	if err != nil || (ctrlResult != ctrl.Result{}) {
+		return nil, ctrlResult, err
+	}
+	imageClient, err := gophercloud_openstack.NewImageV2(
+		o.GetOSClient().ProviderClient,
+		gophercloud.EndpointOpts{
+			Region:       o.GetRegion(),
+			Availability: gophercloud.AvailabilityInternal,
+		})
+	if err != nil {
+		return nil, ctrl.Result{}, err
+	}
+	return imageClient, ctrl.Result{}, nil
+}
+
+// imageTag - tag identifying the image of a GlanceImage, used to find it
+// when the ID is not recorded in the status
+func imageTag(instance *glancev1.GlanceImage) string {
+	return fmt.Sprintf("glanceimage:%s/%s", instance.Namespace, instance.Name)
+}
+
+// ensureImage - returns the image of the GlanceImage, created if it does not
+// exist. The name, visibility and properties are kept in sync with the spec
+func (r *GlanceImageReconciler) ensureImage(
+	ctx context.Context,
+	imageClient *gophercloud.ServiceClient,
+	instance *glancev1.GlanceImage,
+) (*images.Image, error) {
+	Log := r.GetLogger(ctx)
+
+	var image *images.Image
+	var err error
+	if instance.Status.ImageID != "" {
+		image, err = images.Get(ctx, imageClient, instance.Status.ImageID).Extract()
+		if err != nil && !gophercloud.ResponseCodeIs(err, http.StatusNotFound) {
+			return nil, fmt.Errorf("getting image %s: %w", instance.Status.ImageID, err)
+		}
+	} else {
+		allPages, err := images.List(imageClient, images.ListOpts{Tags: []string{imageTag(instance)}}).AllPages(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing images: %w", err)
+		}
+		found, err := images.ExtractImages(allPages)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) > 0 {
+			image = &found[0]
+		}
+	}
+
+	visibility := images.ImageVisibility(instance.Spec.Visibility)
+	if image == nil {
+		image, err = images.Create(ctx, imageClient, images.CreateOpts{
+			Name:            instance.GetImageName(),
+			Visibility:      &visibility,
+			Tags:            []string{imageTag(instance)},
+			ContainerFormat: instance.Spec.ContainerFormat,
+			DiskFormat:      instance.Spec.DiskFormat,
+			Properties:      instance.Spec.Properties,
+		}).Extract()
+		if err != nil {
+			return nil, fmt.Errorf("creating image %s: %w", instance.GetImageName(), err)
+		}
+		Log.Info(fmt.Sprintf("Image %s created", image.ID))
+		return image, nil
+	}
+
+	patch := images.UpdateOpts{}
+	if image.Name != instance.GetImageName() {
+		patch = append(patch, images.ReplaceImageName{NewName: instance.GetImageName()})
+	}
+	if image.Visibility != visibility {
+		patch = append(patch, images.UpdateVisibility{Visibility: visibility})
+	}
+	for _, k := range slices.Sorted(maps.Keys(instance.Spec.Properties)) {
+		if v, ok := image.Properties[k].(string); !ok || v != instance.Spec.Properties[k] {
+			patch = append(patch, images.UpdateImageProperty{
+				Op:    images.AddOp,
+				Name:  k,
+				Value: instance.Spec.Properties[k],
+			})
+		}
+	}
+	if len(patch) == 0 {
+		return image, nil
+	}
+	imageID := image.ID
+	image, err = images.Update(ctx, imageClient, imageID, patch).Extract()
+	if err != nil {
+		return nil, fmt.Errorf("updating image %s: %w", imageID, err)
+	}
+	Log.Info(fmt.Sprintf("Image %s updated", image.ID))
+	return image, nil
+}
+
+// imageImportOpts - import request with the stores the image is imported to,
+// not supported by imageimport.CreateOpts
+type imageImportOpts struct {
+	Method imageimport.ImportMethod
+	URI    string
+	Stores []string
+}
+
+// ToImportCreateMap - builds the body of the import request
+func (opts imageImportOpts) ToImportCreateMap() (map[string]any, error) {
+	method := map[string]any{"name": opts.Method}
+	if opts.URI != "" {
+		method["uri"] = opts.URI
+	}
+	b := map[string]any{"method": method}
+	if len(opts.Stores) > 0 {
+		b["stores"] = opts.Stores
+		b["all_stores_must_succeed"] = true
+	}
+	return b, nil
+}
+
+// importImage - starts the import of the image data with the given method
+func (r *GlanceImageReconciler) importImage(
+	ctx context.Context,
+	imageClient *gophercloud.ServiceClient,
+	instance *glancev1.GlanceImage,
+	method imageimport.ImportMethod,
+) error {
+	Log := r.GetLogger(ctx)
+	err := imageimport.Create(ctx, imageClient, instance.Status.ImageID, imageImportOpts{
+		Method: method,
+		URI:    instance.Spec.Source.URL,
+		Stores: instance.Spec.Stores,
+	}).ExtractErr()
+	if gophercloud.ResponseCodeIs(err, http.StatusConflict) {
+		// an import is already in progress
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("importing image %s: %w", instance.Status.ImageID, err)
+	}
+	Log.Info(fmt.Sprintf("Image %s import started with %s", instance.Status.ImageID, method))
+	return nil
+}
+
+// stageImage - runs the Job that stages the data of a GlanceImage with a PVC
+// source
+func (r *GlanceImageReconciler) stageImage(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *glancev1.GlanceImage,
+	api *glancev1.GlanceAPI,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+	uploadLabels := map[string]string{
+		common.AppSelector:   glance.ServiceName,
+		common.OwnerSelector: instance.Name,
+	}
+	jobDef := glanceapi.ImageUploadJob(api, instance, uploadLabels)
+	uploadJob := job.NewJob(
+		jobDef,
+		glancev1.ImageUploadHash,
+		false,
+		glance.ShortDuration,
+		instance.Status.Hash[glancev1.ImageUploadHash],
+	)
+	ctrlResult, err := uploadJob.DoJob(ctx, h)
+	if (ctrlResult != ctrl.Result{}) {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.ImageReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			glancev1.ImageUploadRunningMessage))
+		return ctrlResult, nil
+	}
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.ImageReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			glancev1.ImageReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	if !uploadJob.HasChanged() {
+		// The Job already succeeded, but the image is still queued
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.ImageReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			glancev1.ImageReadyErrorMessage,
+			fmt.Sprintf("image %s data not staged by Job %s", instance.Status.ImageID, jobDef.Name)))
+		return ctrl.Result{}, nil
+	}
+	instance.Status.Hash[glancev1.ImageUploadHash] = uploadJob.GetHash()
+	Log.Info(fmt.Sprintf("Service '%s' - Job %s hash added - %s", instance.Name, jobDef.Name, instance.Status.Hash[glancev1.ImageUploadHash]))
+	// The staged image is imported in the next reconcile
+	return glance.ResultRequeue, nil
+}
+
+// imageStoreList - returns the stores listed in a comma separated image
+// property (e.g. stores, os_glance_failed_import)
+func imageStoreList(image *images.Image, property string) []string {
+	value, _ := image.Properties[property].(string)
+	stores := []string{}
+	for _, s := range strings.Split(value, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			stores = append(stores, s)
+		}
+	}
+	return stores
+}
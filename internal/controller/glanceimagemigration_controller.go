@@ -0,0 +1,383 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+
+	"github.com/go-logr/logr"
+	"github.com/gophercloud/gophercloud/v2"
+	"github.com/gophercloud/gophercloud/v2/openstack/image/v2/images"
+	glancev1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/glance-operator/internal/glance"
+	"github.com/openstack-k8s-operators/glance-operator/internal/glanceapi"
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/job"
+	batchv1 "k8s.io/api/batch/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// GlanceImageMigrationReconciler reconciles a GlanceImageMigration object
+type GlanceImageMigrationReconciler struct {
+	client.Client
+	Kclient  kubernetes.Interface
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// ImageClient - returns the image API client, GetImageServiceClient
+	// when nil
+	ImageClient ImageClientFunc
+}
+
+// GetLogger returns a logger object with a prefix of "controller.name" and additional controller context fields
+func (r *GlanceImageMigrationReconciler) GetLogger(ctx context.Context) logr.Logger {
+	return log.FromContext(ctx).WithName("Controllers").WithName("GlanceImageMigration")
+}
+
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glanceimagemigrations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glanceimagemigrations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glanceimagemigrations/finalizers,verbs=update;patch
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glances,verbs=get;list;watch
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glanceapis,verbs=get;list;watch
+// +kubebuilder:rbac:groups=keystone.openstack.org,resources=keystoneapis,verbs=get;list;watch;
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete;
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list
+
+// Reconcile reconcile GlanceImageMigration requests
+func (r *GlanceImageMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, _err error) {
+	Log := r.GetLogger(ctx)
+
+	instance := &glancev1.GlanceImageMigration{}
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			// The Job is owned by the GlanceImageMigration and garbage
+			// collected
+			return ctrl.Result{}, nil
+		}
+		Log.Error(err, fmt.Sprintf("could not fetch GlanceImageMigration instance %s", instance.Name))
+		return ctrl.Result{}, err
+	}
+
+	helper, err := helper.NewHelper(
+		instance,
+		r.Client,
+		r.Kclient,
+		r.Scheme,
+		Log,
+	)
+	if err != nil {
+		Log.Error(err, fmt.Sprintf("could not instantiate helper for instance %s", instance.Name))
+		return ctrl.Result{}, err
+	}
+
+	// A completed migration is never run again
+	if instance.Status.CompletionTime != nil {
+		return ctrl.Result{}, nil
+	}
+
+	isNewInstance := instance.Status.Conditions == nil
+	if isNewInstance {
+		instance.Status.Conditions = condition.Conditions{}
+	}
+	savedConditions := instance.Status.Conditions.DeepCopy()
+
+	defer func() {
+		// Don't update the status, if Reconciler Panics
+		if rc := recover(); rc != nil {
+			Log.Info(fmt.Sprintf("Panic during reconcile %v\n", rc))
+			panic(rc)
+		}
+		condition.RestoreLastTransitionTimes(
+			&instance.Status.Conditions, savedConditions)
+		if instance.Status.Conditions.IsUnknown(condition.ReadyCondition) {
+			instance.Status.Conditions.Set(
+				instance.Status.Conditions.Mirror(condition.ReadyCondition))
+		}
+		err := helper.PatchInstance(ctx, instance)
+		if err != nil {
+			_err = err
+			return
+		}
+	}()
+
+	cl := condition.CreateList(
+		condition.UnknownCondition(condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage),
+		condition.UnknownCondition(condition.InputReadyCondition, condition.InitReason, condition.InputReadyInitMessage),
+		condition.UnknownCondition(glancev1.ImageMigrationReadyCondition, condition.InitReason, glancev1.ImageMigrationReadyInitMessage),
+	)
+	instance.Status.Conditions.Init(&cl)
+	instance.Status.ObservedGeneration = instance.Generation
+
+	if isNewInstance {
+		// Register overall status immediately to have an early feedback e.g. in the cli
+		return ctrl.Result{}, nil
+	}
+
+	if instance.Status.Hash == nil {
+		instance.Status.Hash = map[string]string{}
+	}
+
+	return r.reconcileNormal(ctx, instance, helper)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GlanceImageMigrationReconciler) SetupWithManager(_ context.Context, mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&glancev1.GlanceImageMigration{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
+
+func (r *GlanceImageMigrationReconciler) reconcileNormal(
+	ctx context.Context,
+	instance *glancev1.GlanceImageMigration,
+	h *helper.Helper,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	if instance.Spec.SourceStore == instance.Spec.TargetStore {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityError,
+			glancev1.ImageMigrationSameStoreErrorMessage))
+		return ctrl.Result{}, nil
+	}
+
+	glanceInstance := &glancev1.Glance{}
+	err := r.Get(ctx, types.NamespacedName{Name: instance.Spec.GlanceName, Namespace: instance.Namespace}, glanceInstance)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	var api *glancev1.GlanceAPI
+	if err == nil && glanceInstance.IsReady() {
+		api, err = GetKeystoneGlanceAPI(ctx, r.Client, glanceInstance)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if api == nil {
+		Log.Info(fmt.Sprintf("Glance %s not ready", instance.Spec.GlanceName))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			glancev1.ImageMigrationWaitingMessage,
+			instance.Spec.GlanceName))
+		return glance.ResultRequeue, nil
+	}
+
+	// Refuse to start unless both stores are configured in the GlanceAPI
+	// that serves the images. The GlanceAPI config is not watched: check it
+	// again later, a store might be added to the Glance
+	stores := getEnabledStores(api)
+	for _, store := range []string{instance.Spec.SourceStore, instance.Spec.TargetStore} {
+		if !slices.Contains(stores, store) {
+			Log.Info(fmt.Sprintf("Store %s is not configured in GlanceAPI %s", store, api.Name))
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				condition.InputReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityError,
+				glancev1.ImageMigrationStoreErrorMessage,
+				store,
+				api.Name))
+			return glance.ResultRequeue, nil
+		}
+	}
+	instance.Status.Conditions.MarkTrue(condition.InputReadyCondition, condition.InputReadyMessage)
+
+	migrationLabels := map[string]string{
+		common.AppSelector:   glance.ServiceName,
+		common.OwnerSelector: instance.Name,
+	}
+	jobDef, err := glanceapi.ImageMigrationJob(api, instance, migrationLabels)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	// The Job is preserved: its Pods hold the migration summary, and it is
+	// garbage collected with the GlanceImageMigration
+	migrationJob := job.NewJob(
+		jobDef,
+		glancev1.ImageMigrationHash,
+		true,
+		glance.ShortDuration,
+		instance.Status.Hash[glancev1.ImageMigrationHash],
+	)
+	ctrlResult, jobErr := migrationJob.DoJob(ctx, h)
+	if (ctrlResult != ctrl.Result{}) {
+		// The progress is informational: a failure to reach the image
+		// API does not affect the Job
+		if err := r.pollProgress(ctx, h, api, instance); err != nil {
+			Log.Info(fmt.Sprintf("Unable to get the progress of %s: %s", instance.Name, err))
+		}
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.ImageMigrationReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			glancev1.ImageMigrationReadyRunningMessage))
+		// The Job completion triggers a reconcile, the image API is polled
+		// at a lower pace
+		return ctrl.Result{RequeueAfter: glance.ImageMigrationProgressInterval}, nil
+	}
+	if err := r.updateProgress(ctx, instance, jobDef.Name); err != nil {
+		return ctrl.Result{}, err
+	}
+	if jobErr != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.ImageMigrationReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			glancev1.ImageMigrationReadyErrorMessage,
+			jobErr.Error()))
+		return ctrl.Result{}, jobErr
+	}
+	if migrationJob.HasChanged() {
+		instance.Status.Hash[glancev1.ImageMigrationHash] = migrationJob.GetHash()
+		Log.Info(fmt.Sprintf("Service '%s' - Job %s hash added - %s", instance.Name, jobDef.Name, instance.Status.Hash[glancev1.ImageMigrationHash]))
+	}
+
+	instance.Status.CompletionTime = ptr.To(metav1.Now())
+	if instance.Status.Failed > 0 {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.ImageMigrationReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			glancev1.ImageMigrationFailedMessage,
+			instance.Status.Failed,
+			instance.Status.Total))
+		return ctrl.Result{}, nil
+	}
+	instance.Status.Conditions.MarkTrue(glancev1.ImageMigrationReadyCondition, glancev1.ImageMigrationReadyMessage)
+	instance.Status.Conditions.MarkTrue(condition.ReadyCondition, condition.ReadyMessage)
+	Log.Info(fmt.Sprintf("GlanceImageMigration %s completed", instance.Name))
+	return ctrl.Result{}, nil
+}
+
+// imageFilterOpts - image list request with the filters of a
+// GlanceImageMigration, not supported by images.ListOpts
+type imageFilterOpts map[string]string
+
+// ToImageListQuery - builds the query string of the image list request
+func (opts imageFilterOpts) ToImageListQuery() (string, error) {
+	q := url.Values{}
+	for k, v := range opts {
+		q.Set(k, v)
+	}
+	q.Set("limit", "100")
+	return "?" + q.Encode(), nil
+}
+
+func (r *GlanceImageMigrationReconciler) getImageClient(
+	ctx context.Context,
+	h *helper.Helper,
+	api *glancev1.GlanceAPI,
+) (*gophercloud.ServiceClient, ctrl.Result, error) {
+	if r.ImageClient != nil {
+		return r.ImageClient(ctx, h, api)
+	}
+	return GetImageServiceClient(ctx, h, api)
+}
+
+// pollProgress - reports the progress of a running migration from the
+// stores of the images, as the Job summary is only available once its Pod
+// terminates. Total is the number of images found in SourceStore by the
+// first poll, the images still waiting for a copy to TargetStore are
+// Remaining and the ones whose copy failed are Failed
+func (r *GlanceImageMigrationReconciler) pollProgress(
+	ctx context.Context,
+	h *helper.Helper,
+	api *glancev1.GlanceAPI,
+	instance *glancev1.GlanceImageMigration,
+) error {
+	imageClient, ctrlResult, err := r.getImageClient(ctx, h, api)
+	if err != nil || (ctrlResult != ctrl.Result{}) {
+		return err
+	}
+	allPages, err := images.List(imageClient, imageFilterOpts(instance.Spec.ImageFilter)).AllPages(ctx)
+	if err != nil {
+		return fmt.Errorf("listing images: %w", err)
+	}
+	found, err := images.ExtractImages(allPages)
+	if err != nil {
+		return err
+	}
+	source, remaining, failed := 0, 0, 0
+	failedImages := []string{}
+	for i := range found {
+		image := &found[i]
+		stores := imageStoreList(image, "stores")
+		if image.Status != images.ImageStatusActive || !slices.Contains(stores, instance.Spec.SourceStore) {
+			continue
+		}
+		source++
+		switch {
+		case slices.Contains(stores, instance.Spec.TargetStore):
+		case slices.Contains(imageStoreList(image, "os_glance_failed_import"), instance.Spec.TargetStore):
+			failed++
+			failedImages = append(failedImages, image.ID)
+		default:
+			remaining++
+		}
+	}
+	if instance.Status.Total == 0 {
+		instance.Status.Total = source
+	}
+	instance.Status.Remaining = remaining
+	instance.Status.Failed = failed
+	instance.Status.FailedImages = failedImages
+	instance.Status.Copied = max(instance.Status.Total-remaining-failed, 0)
+	return nil
+}
+
+// updateProgress - copies the summary of the most recent migration attempt,
+// written by the Job in the termination message, to the status
+func (r *GlanceImageMigrationReconciler) updateProgress(
+	ctx context.Context,
+	instance *glancev1.GlanceImageMigration,
+	jobName string,
+) error {
+	latest, err := latestTerminationMessage(ctx, r.Kclient, instance.Namespace, "job-name="+jobName)
+	if err != nil || latest == nil {
+		return err
+	}
+	summary := glanceapi.ImageMigrationSummary{}
+	if err := json.Unmarshal([]byte(latest.Message), &summary); err != nil {
+		// not a summary, e.g. a traceback of a failed attempt
+		return nil
+	}
+	instance.Status.Total = summary.Total
+	instance.Status.Copied = summary.Copied
+	instance.Status.Failed = summary.Failed
+	instance.Status.Remaining = summary.Remaining
+	instance.Status.FailedImages = summary.FailedImages
+	return nil
+}
@@ -0,0 +1,368 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	glancev1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/glance-operator/internal/glance"
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/job"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// GlanceRestoreReconciler reconciles a GlanceRestore object
+type GlanceRestoreReconciler struct {
+	client.Client
+	Kclient  kubernetes.Interface
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// GetLogger returns a logger object with a prefix of "controller.name" and additional controller context fields
+func (r *GlanceRestoreReconciler) GetLogger(ctx context.Context) logr.Logger {
+	return log.FromContext(ctx).WithName("Controllers").WithName("GlanceRestore")
+}
+
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glancerestores,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glancerestores/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glancerestores/finalizers,verbs=update;patch
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glancebackups,verbs=get;list;watch
+// +kubebuilder:rbac:groups=glance.openstack.org,resources=glances,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;create;update;delete;watch;patch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete;
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// Reconcile reconcile GlanceRestore requests
+func (r *GlanceRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, _err error) {
+	Log := r.GetLogger(ctx)
+
+	instance := &glancev1.GlanceRestore{}
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			// The restored PVCs are not owned by the GlanceRestore: they are
+			// adopted by the GlanceAPI StatefulSets
+			return ctrl.Result{}, nil
+		}
+		Log.Error(err, fmt.Sprintf("could not fetch GlanceRestore instance %s", instance.Name))
+		return ctrl.Result{}, err
+	}
+
+	helper, err := helper.NewHelper(
+		instance,
+		r.Client,
+		r.Kclient,
+		r.Scheme,
+		Log,
+	)
+	if err != nil {
+		Log.Error(err, fmt.Sprintf("could not instantiate helper for instance %s", instance.Name))
+		return ctrl.Result{}, err
+	}
+
+	// A completed restore is never run again
+	if instance.Status.CompletionTime != nil {
+		return ctrl.Result{}, nil
+	}
+
+	isNewInstance := instance.Status.Conditions == nil
+	if isNewInstance {
+		instance.Status.Conditions = condition.Conditions{}
+	}
+	savedConditions := instance.Status.Conditions.DeepCopy()
+
+	defer func() {
+		// Don't update the status, if Reconciler Panics
+		if rc := recover(); rc != nil {
+			Log.Info(fmt.Sprintf("Panic during reconcile %v\n", rc))
+			panic(rc)
+		}
+		condition.RestoreLastTransitionTimes(
+			&instance.Status.Conditions, savedConditions)
+		if instance.Status.Conditions.IsUnknown(condition.ReadyCondition) {
+			instance.Status.Conditions.Set(
+				instance.Status.Conditions.Mirror(condition.ReadyCondition))
+		}
+		err := helper.PatchInstance(ctx, instance)
+		if err != nil {
+			_err = err
+			return
+		}
+	}()
+
+	cl := condition.CreateList(
+		condition.UnknownCondition(condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage),
+		condition.UnknownCondition(glancev1.RestorePVCReadyCondition, condition.InitReason, glancev1.RestorePVCReadyInitMessage),
+	)
+	if instance.Spec.RestoreDatabase {
+		cl.Set(condition.UnknownCondition(
+			glancev1.DatabaseRestoreReadyCondition,
+			condition.InitReason,
+			glancev1.DatabaseRestoreReadyInitMessage))
+	}
+	instance.Status.Conditions.Init(&cl)
+	instance.Status.ObservedGeneration = instance.Generation
+
+	if isNewInstance {
+		// Register overall status immediately to have an early feedback e.g. in the cli
+		return ctrl.Result{}, nil
+	}
+
+	if instance.Status.Hash == nil {
+		instance.Status.Hash = map[string]string{}
+	}
+
+	return r.reconcileNormal(ctx, instance, helper)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GlanceRestoreReconciler) SetupWithManager(_ context.Context, mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&glancev1.GlanceRestore{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
+
+func (r *GlanceRestoreReconciler) reconcileNormal(
+	ctx context.Context,
+	instance *glancev1.GlanceRestore,
+	h *helper.Helper,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	backup := &glancev1.GlanceBackup{}
+	err := r.Get(ctx, types.NamespacedName{Name: instance.Spec.BackupName, Namespace: instance.Namespace}, backup)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	if err != nil || backup.Status.CompletionTime == nil {
+		Log.Info(fmt.Sprintf("GlanceBackup %s not completed", instance.Spec.BackupName))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.RestorePVCReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			glancev1.GlanceRestoreWaitingMessage,
+			instance.Spec.BackupName))
+		return glance.ResultRequeue, nil
+	}
+	instance.Status.GlanceName = backup.Spec.GlanceName
+
+	ctrlResult, err := r.ensureRestoredPVCs(ctx, instance, backup)
+	if err != nil || (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, err
+	}
+
+	if instance.Spec.RestoreDatabase {
+		ctrlResult, err = r.ensureDatabaseRestore(ctx, h, instance, backup)
+		if err != nil || (ctrlResult != ctrl.Result{}) {
+			return ctrlResult, err
+		}
+	}
+
+	instance.Status.CompletionTime = ptr.To(metav1.Now())
+	instance.Status.Conditions.MarkTrue(condition.ReadyCondition, condition.ReadyMessage)
+	Log.Info(fmt.Sprintf("GlanceRestore %s completed", instance.Name))
+	return ctrl.Result{}, nil
+}
+
+// ensureRestoredPVCs - recreates the image PVCs from the GlanceBackup
+// VolumeSnapshots. The PVCs are named after the StatefulSet VolumeClaimTemplate
+// and are not owned by the GlanceRestore, so that the GlanceAPI StatefulSets
+// adopt them instead of provisioning empty volumes
+func (r *GlanceRestoreReconciler) ensureRestoredPVCs(
+	ctx context.Context,
+	instance *glancev1.GlanceRestore,
+	backup *glancev1.GlanceBackup,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+	setError := func(err error) (ctrl.Result, error) {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.RestorePVCReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			glancev1.RestorePVCReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	restored := []string{}
+	for _, vol := range backup.Status.Volumes {
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := r.Get(ctx, types.NamespacedName{Name: vol.PVCName, Namespace: instance.Namespace}, pvc)
+		if err == nil {
+			// A PVC that was not restored from this backup holds data the
+			// restore must not silently replace
+			if pvc.Annotations[glancev1.RestoredFromAnnotation] != backup.Name {
+				instance.Status.Conditions.Set(condition.FalseCondition(
+					glancev1.RestorePVCReadyCondition,
+					condition.ErrorReason,
+					condition.SeverityWarning,
+					glancev1.RestorePVCExistsErrorMessage,
+					vol.PVCName,
+					backup.Name))
+				return ctrl.Result{}, fmt.Errorf("%w: %s", ErrRestorePVCExists, vol.PVCName)
+			}
+			restored = append(restored, vol.PVCName)
+			continue
+		}
+		if !k8s_errors.IsNotFound(err) {
+			return setError(err)
+		}
+
+		pvc, err = restoredPVC(instance.Namespace, backup.Name, vol)
+		if err != nil {
+			return setError(err)
+		}
+		if err := r.Create(ctx, pvc); err != nil {
+			return setError(fmt.Errorf("error creating PVC %s: %w", vol.PVCName, err))
+		}
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, eventReasonPVCRestored,
+			"PVC %s restored from VolumeSnapshot %s", vol.PVCName, vol.SnapshotName)
+		Log.Info(fmt.Sprintf("PVC %s restored from VolumeSnapshot %s", vol.PVCName, vol.SnapshotName))
+		restored = append(restored, vol.PVCName)
+	}
+	instance.Status.RestoredPVCs = restored
+	instance.Status.Conditions.MarkTrue(glancev1.RestorePVCReadyCondition, glancev1.RestorePVCReadyMessage)
+	return ctrl.Result{}, nil
+}
+
+// ensureDatabaseRestore - loads the database dump of the GlanceBackup once
+// db_sync has run for the Glance: the dump replaces the content of the
+// database, and the GlanceAPIs are held until it is loaded
+func (r *GlanceRestoreReconciler) ensureDatabaseRestore(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *glancev1.GlanceRestore,
+	backup *glancev1.GlanceBackup,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	glanceInstance := &glancev1.Glance{}
+	err := r.Get(ctx, types.NamespacedName{Name: backup.Spec.GlanceName, Namespace: instance.Namespace}, glanceInstance)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	if err != nil || !glanceInstance.Status.Conditions.IsTrue(condition.DBSyncReadyCondition) {
+		Log.Info(fmt.Sprintf("Glance %s db sync not completed", backup.Spec.GlanceName))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.DatabaseRestoreReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			glancev1.DatabaseRestoreWaitingMessage,
+			backup.Spec.GlanceName))
+		return glance.ResultRequeue, nil
+	}
+
+	restoreLabels := map[string]string{
+		common.AppSelector:   glance.ServiceName,
+		common.OwnerSelector: instance.Name,
+	}
+	jobDef := glance.DatabaseRestoreJob(glanceInstance, instance, backup.Status.DatabaseBackupPVC,
+		restoreLabels, map[string]string{})
+	restoreJob := job.NewJob(
+		jobDef,
+		glancev1.DatabaseRestoreHash,
+		glanceInstance.Spec.PreserveJobs,
+		glance.ShortDuration,
+		instance.Status.Hash[glancev1.DatabaseRestoreHash],
+	)
+	ctrlResult, err := restoreJob.DoJob(ctx, h)
+	if (ctrlResult != ctrl.Result{}) {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.DatabaseRestoreReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			glancev1.DatabaseRestoreReadyRunningMessage))
+		return ctrlResult, nil
+	}
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			glancev1.DatabaseRestoreReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			glancev1.DatabaseRestoreReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	if restoreJob.HasChanged() {
+		instance.Status.Hash[glancev1.DatabaseRestoreHash] = restoreJob.GetHash()
+		Log.Info(fmt.Sprintf("Service '%s' - Job %s hash added - %s", instance.Name, jobDef.Name, instance.Status.Hash[glancev1.DatabaseRestoreHash]))
+	}
+	instance.Status.Conditions.MarkTrue(glancev1.DatabaseRestoreReadyCondition, glancev1.DatabaseRestoreReadyMessage)
+	return ctrl.Result{}, nil
+}
+
+// restoredPVC - returns a PVC provisioned from the VolumeSnapshot of a
+// GlanceBackup volume
+func restoredPVC(
+	namespace string,
+	backupName string,
+	vol glancev1.GlanceBackupVolume,
+) (*corev1.PersistentVolumeClaim, error) {
+	storageSize, err := resource.ParseQuantity(vol.StorageRequest)
+	if err != nil {
+		return nil, err
+	}
+	snapshotGroup := "snapshot.storage.k8s.io"
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      vol.PVCName,
+			Namespace: namespace,
+			Labels:    vol.Labels,
+			Annotations: map[string]string{
+				glancev1.RestoredFromAnnotation: backupName,
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{
+				corev1.ReadWriteOnce,
+			},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: storageSize,
+				},
+			},
+			DataSource: &corev1.TypedLocalObjectReference{
+				APIGroup: &snapshotGroup,
+				Kind:     glance.SnapshotKind,
+				Name:     vol.SnapshotName,
+			},
+		},
+	}
+	if vol.StorageClassName != "" {
+		pvc.Spec.StorageClassName = &vol.StorageClassName
+	}
+	return pvc, nil
+}
@@ -0,0 +1,154 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	glancev1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/glance-operator/internal/glance"
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
+	"github.com/prometheus/client_golang/prometheus"
+	batchv1 "k8s.io/api/batch/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// metricsNamespace - prefix of the glance-operator metrics
+	metricsNamespace = "glance_operator"
+	// metricsCollectTimeout - upper bound of a single scrape of the cached
+	// GlanceAPI and CronJob objects
+	metricsCollectTimeout = 10 * time.Second
+)
+
+var (
+	// glanceAPIRecreationsTotal - StatefulSets deleted by glanceAPIRefresh
+	// because of a backend hash change
+	glanceAPIRecreationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "glanceapi_statefulset_recreations_total",
+			Help:      "Number of GlanceAPI StatefulSets recreated because of a backend change",
+		},
+		[]string{"namespace", "glanceapi"},
+	)
+	// registeredLimitsSyncErrorsTotal - failures to create or update the
+	// Glance registered limits in keystone
+	registeredLimitsSyncErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "registered_limits_sync_errors_total",
+			Help:      "Number of failed Keystone registered limits syncs",
+		},
+		[]string{"namespace", "glance"},
+	)
+
+	glanceAPIReplicasDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "glanceapi", "replicas"),
+		"Desired replicas of a GlanceAPI",
+		[]string{"namespace", "glanceapi", "api_type"}, nil,
+	)
+	glanceAPIReadyReplicasDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "glanceapi", "ready_replicas"),
+		"Ready replicas of a GlanceAPI",
+		[]string{"namespace", "glanceapi", "api_type"}, nil,
+	)
+	cronJobLastSuccessAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "cronjob", "last_success_age_seconds"),
+		"Seconds elapsed since the last successful run of a Glance CronJob",
+		[]string{"namespace", "cronjob", "type"}, nil,
+	)
+)
+
+// glanceCollector - computes the GlanceAPI replicas and the CronJobs last
+// success age at scrape time from the manager cache, so no state has to be
+// kept in sync with the reconcile loops
+type glanceCollector struct {
+	client client.Reader
+}
+
+// Describe - implements prometheus.Collector
+func (c *glanceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- glanceAPIReplicasDesc
+	ch <- glanceAPIReadyReplicasDesc
+	ch <- cronJobLastSuccessAgeDesc
+}
+
+// Collect - implements prometheus.Collector
+func (c *glanceCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), metricsCollectTimeout)
+	defer cancel()
+
+	apis := &glancev1.GlanceAPIList{}
+	if err := c.client.List(ctx, apis); err != nil {
+		ch <- prometheus.NewInvalidMetric(glanceAPIReplicasDesc, err)
+	} else {
+		for _, api := range apis.Items {
+			var replicas int32
+			if api.Spec.Replicas != nil {
+				replicas = *api.Spec.Replicas
+			}
+			ch <- prometheus.MustNewConstMetric(glanceAPIReplicasDesc, prometheus.GaugeValue,
+				float64(replicas), api.Namespace, api.Name, api.Spec.APIType)
+			ch <- prometheus.MustNewConstMetric(glanceAPIReadyReplicasDesc, prometheus.GaugeValue,
+				float64(api.Status.ReadyCount), api.Namespace, api.Name, api.Spec.APIType)
+		}
+	}
+
+	// db-purge, cache cleaner and cache pruner CronJobs all carry the glance
+	// service label
+	cronJobs := &batchv1.CronJobList{}
+	if err := c.client.List(ctx, cronJobs, client.MatchingLabels{
+		common.AppSelector: glance.ServiceName,
+	}); err != nil {
+		ch <- prometheus.NewInvalidMetric(cronJobLastSuccessAgeDesc, err)
+		return
+	}
+	for _, cj := range cronJobs.Items {
+		// A CronJob that never succeeded has no age to report
+		if cj.Status.LastSuccessfulTime == nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(cronJobLastSuccessAgeDesc, prometheus.GaugeValue,
+			time.Since(cj.Status.LastSuccessfulTime.Time).Seconds(),
+			cj.Namespace, cj.Name, cronJobType(cj.Name))
+	}
+}
+
+// cronJobType - returns the glance.CronJobType a CronJob was created for,
+// based on the suffix of its name
+func cronJobType(name string) string {
+	for _, t := range []glance.CronJobType{glance.DBPurge, glance.CacheCleaner, glance.CachePruner, glance.ImageCleanup, glance.ConsistencyCheck} {
+		if strings.HasSuffix(name, "-"+string(t)) {
+			return string(t)
+		}
+	}
+	return ""
+}
+
+// RegisterMetrics - registers the Glance metrics in the controller-runtime
+// Registry served by the manager metrics endpoint. c is expected to be the
+// manager (cached) client
+func RegisterMetrics(c client.Reader) {
+	metrics.Registry.MustRegister(
+		glanceAPIRecreationsTotal,
+		registeredLimitsSyncErrorsTotal,
+		&glanceCollector{client: c},
+	)
+}
@@ -0,0 +1,229 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package glance
+
+import (
+	"maps"
+	"slices"
+
+	glancev1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/pod"
+	"github.com/openstack-k8s-operators/lib-common/modules/users"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// DatabaseBackupDir - path where the PVC holding the database dump is
+	// mounted
+	DatabaseBackupDir = "/var/lib/glance/backup"
+	// DatabaseBackupCommand - dumps the Glance database
+	DatabaseBackupCommand = "/usr/local/bin/container-scripts/glance-db backup"
+	// DatabaseRestoreCommand - loads the Glance database dump
+	DatabaseRestoreCommand = "/usr/local/bin/container-scripts/glance-db restore"
+	// SnapshotGroupVersion - API group providing the CSI VolumeSnapshot kind
+	SnapshotGroupVersion = "snapshot.storage.k8s.io/v1"
+	// SnapshotKind -
+	SnapshotKind = "VolumeSnapshot"
+)
+
+// DatabaseBackupJob - returns the Job that dumps the database of the given
+// Glance in the GlanceBackup database PVC
+func DatabaseBackupJob(
+	instance *glancev1.Glance,
+	backup *glancev1.GlanceBackup,
+	labels map[string]string,
+	annotations map[string]string,
+) *batchv1.Job {
+	return databaseJob(instance, backup.Name+"-db-backup", backup.DatabaseBackupPVCName(),
+		DatabaseBackupCommand, labels, annotations)
+}
+
+// DatabaseRestoreJob - returns the Job that loads the database dump of a
+// GlanceBackup in the database of the given Glance
+func DatabaseRestoreJob(
+	instance *glancev1.Glance,
+	restore *glancev1.GlanceRestore,
+	backupPVC string,
+	labels map[string]string,
+	annotations map[string]string,
+) *batchv1.Job {
+	return databaseJob(instance, restore.Name+"-db-restore", backupPVC,
+		DatabaseRestoreCommand, labels, annotations)
+}
+
+// databaseJob - like the MetadefsJob, only DefaultsConfigFileName and my.cnf
+// are required to reach the database
+func databaseJob(
+	instance *glancev1.Glance,
+	name string,
+	backupPVC string,
+	command string,
+	labels map[string]string,
+	annotations map[string]string,
+) *batchv1.Job {
+	jobVolumes := []corev1.Volume{
+		{
+			Name: "db-config-data",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					DefaultMode: &configMode,
+					SecretName:  instance.Name + "-config-data",
+					Items: []corev1.KeyToPath{
+						{
+							Key:  DefaultsConfigFileName,
+							Path: DefaultsConfigFileName,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "config-data",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					DefaultMode: &configMode,
+					SecretName:  instance.Name + "-config-data",
+				},
+			},
+		},
+		{
+			Name: "backup",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: backupPVC,
+				},
+			},
+		},
+	}
+	jobVolumes = append(jobVolumes, GetScriptVolume()...)
+
+	jobMounts := []corev1.VolumeMount{
+		{
+			Name:      "db-config-data",
+			MountPath: "/etc/glance/glance.conf.d",
+			ReadOnly:  true,
+		},
+		{
+			Name:      "config-data",
+			MountPath: "/etc/my.cnf",
+			SubPath:   "my.cnf",
+			ReadOnly:  true,
+		},
+		{
+			Name:      "backup",
+			MountPath: DatabaseBackupDir,
+		},
+	}
+	jobMounts = append(jobMounts, GetScriptVolumeMount()...)
+
+	// add CA cert if defined from the first api (sorted for deterministic selection)
+	for _, apiName := range slices.Sorted(maps.Keys(instance.Spec.GlanceAPIs)) {
+		api := instance.Spec.GlanceAPIs[apiName]
+		if api.TLS.CaBundleSecretName != "" {
+			jobVolumes = append(jobVolumes, api.TLS.CreateVolume())
+			jobMounts = append(jobMounts, api.TLS.CreateVolumeMounts(nil)...)
+
+			break
+		}
+	}
+
+	envVars := map[string]env.Setter{}
+	envVars["GLANCE_DB_BACKUP_DIR"] = env.SetValue(DatabaseBackupDir)
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: annotations,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:                corev1.RestartPolicyOnFailure,
+					ServiceAccountName:           instance.RbacResourceName(),
+					AutomountServiceAccountToken: ptr.To(false),
+					SecurityContext:              pod.RestrictivePodSecurityContext(users.GlanceUID, users.GlanceGID),
+					Containers: []corev1.Container{
+						{
+							Name: name,
+							Command: []string{
+								"/bin/bash",
+							},
+							Args:            []string{"-c", command},
+							Image:           instance.Spec.ContainerImage,
+							SecurityContext: pod.RestrictiveSecurityContext(users.GlanceUID, users.GlanceGID),
+							Env:             env.MergeEnvs([]corev1.EnvVar{}, envVars),
+							VolumeMounts:    jobMounts,
+						},
+					},
+				},
+			},
+		},
+	}
+	if instance.Spec.NodeSelector != nil {
+		job.Spec.Template.Spec.NodeSelector = *instance.Spec.NodeSelector
+	}
+	job.Spec.Template.Spec.Volumes = jobVolumes
+	return job
+}
+
+// DatabaseBackupPVC - returns the PVC holding the database dump of a
+// GlanceBackup
+func DatabaseBackupPVC(
+	instance *glancev1.Glance,
+	backup *glancev1.GlanceBackup,
+	labels map[string]string,
+) (*corev1.PersistentVolumeClaim, error) {
+	// MustParse can't be used in this context as it generates panic() and
+	// we can't recover the operator
+	storageSize, err := resource.ParseQuantity(backup.Spec.DatabaseStorageRequest)
+	if err != nil {
+		return nil, err
+	}
+	storageClass := backup.Spec.DatabaseStorageClass
+	if storageClass == "" {
+		storageClass = instance.Spec.Storage.StorageClass
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      backup.DatabaseBackupPVCName(),
+			Namespace: backup.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{
+				corev1.ReadWriteOnce,
+			},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: storageSize,
+				},
+			},
+		},
+	}
+	if storageClass != "" {
+		pvc.Spec.StorageClassName = &storageClass
+	}
+	return pvc, nil
+}
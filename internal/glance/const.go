@@ -50,6 +50,9 @@ const (
 	// PvcImageConv is used to define a PVC mounted for image conversion purposes
 	// when Ceph is detected as a backend
 	PvcImageConv PvcType = "imageConv"
+	// PvcStaging is used to define a PVC mounted as staging area of the
+	// image import
+	PvcStaging PvcType = "staging"
 	// GlancePublicPort -
 	GlancePublicPort int32 = 9292
 	// GlanceInternalPort -
@@ -62,6 +65,38 @@ const (
 	CustomServiceConfigFileName = "02-config.conf"
 	// CustomServiceConfigSecretsFileName -
 	CustomServiceConfigSecretsFileName = "03-config.conf" // #nosec G101
+	// PropertyProtectionsFileName - key of the referenced ConfigMap holding
+	// the property protection rules
+	PropertyProtectionsFileName = "property-protections.conf"
+	// PropertyProtectionsPath - path of the property protection rules file
+	// in the GlanceAPI containers
+	PropertyProtectionsPath = "/etc/glance/" + PropertyProtectionsFileName
+	// PropertyProtectionsVolume - name of the Volume built from the
+	// property protections ConfigMap
+	PropertyProtectionsVolume = "property-protections"
+	// PasteFileName - paste pipeline including the audit and the signature
+	// filters
+	PasteFileName = "glance-api-paste.ini"
+	// PasteDir - path where the paste pipeline is mounted in the GlanceAPI
+	// containers
+	PasteDir = "/etc/glance/paste"
+	// AuditMapFileName - CADF mapping of the Image API requests
+	AuditMapFileName = "api_audit_map.conf"
+	// AuditDir - path where the audit map is mounted in the GlanceAPI
+	// containers. It is kept out of glance.conf.d because any *.conf file
+	// there would be parsed as a service config file
+	AuditDir = "/etc/glance/audit"
+	// MetricsStatusPort - localhost port where httpd serves mod_status
+	MetricsStatusPort int32 = 9280
+	// HTTPDExporterPort - Prometheus port of the mod_status exporter
+	HTTPDExporterPort int32 = 9117
+	// LogExporterPort - Prometheus port of the access log (mtail) exporter
+	LogExporterPort int32 = 3903
+	// MetricsAccessLog - access log parsed by the mtail sidecar
+	MetricsAccessLog = GlanceLogPath + "access.log"
+	// MetricsProgramFileName - mtail program deriving the request metrics
+	// from MetricsAccessLog
+	MetricsProgramFileName = "glance.mtail"
 
 	// GlanceExtraVolTypeUndefined can be used to label an extraMount which
 	// is not associated with a specific backend
@@ -91,8 +126,44 @@ const (
 	CacheCleaner CronJobType = "cleaner"
 	//CachePruner -
 	CachePruner CronJobType = "pruner"
+	// ImageCleanup - removes the stale images and the orphaned staging files
+	ImageCleanup CronJobType = "image-cleanup"
+	// ConsistencyCheck - compares the file stores with the image locations
+	ConsistencyCheck CronJobType = "consistency-check"
+	// StagingCleanup - removes the orphaned files of a per-replica staging
+	// area
+	StagingCleanup CronJobType = "staging-cleanup"
+	// CronJobNameLabel - set on the Pods of the CronJobs whose termination
+	// message is read by the operator
+	CronJobNameLabel = "glance.openstack.org/cronjob"
 	//ImageCacheDir -
 	ImageCacheDir = "/var/lib/glance/image-cache"
+	// CacheVolume - name of the image cache volume
+	CacheVolume = ServiceName + "-cache"
+	// CacheCleanupInterval - interval (in seconds) between two runs of the
+	// in-pod cache cleaner, used when the cache volume can't be shared with
+	// the CronJobs
+	CacheCleanupInterval = 1800
+	// CachePruneInterval - interval (in seconds) between two runs of the
+	// in-pod cache pruner
+	CachePruneInterval = 60
+	// CacheSizeLimitHeadroom - percentage added to the image cache size to
+	// get the sizeLimit of an emptyDir cache: the cache grows beyond
+	// image_cache_max_size until the next prune
+	CacheSizeLimitHeadroom = 25
+	// ImageConversionDir - path of the os_glance_tasks_store reserved store,
+	// used as work dir by the import tasks (it replaces [task]/work_dir)
+	ImageConversionDir = "/var/lib/glance/os_glance_tasks_store"
+	// ImageConversionVolume - name of the image conversion volume
+	ImageConversionVolume = ServiceName + "-conv"
+	// StagingDir - path of the os_glance_staging_store reserved store when a
+	// dedicated staging volume is requested
+	StagingDir = "/var/lib/glance/staging"
+	// DefaultStagingDir - path of the os_glance_staging_store reserved store
+	// when no staging volume is requested: it lives in the glance PVC
+	DefaultStagingDir = "/var/lib/glance/os_glance_staging_store"
+	// StagingVolume - name of the staging volume
+	StagingVolume = ServiceName + "-staging"
 	// CachePVCPrefix is the VolumeClaimTemplate name prefix used by
 	// StatefulSets for image-cache PVCs (format: <prefix>-<sts-pod-name>)
 	CachePVCPrefix = ServiceName + "-cache-"
@@ -103,10 +174,36 @@ const (
 	GlanceCacheCleaner = "/usr/bin/glance-cache-cleaner"
 	// GlanceCachePruner -
 	GlanceCachePruner = "/usr/bin/glance-cache-pruner"
+	// BarbicanServiceName - name of the Barbican service registered in keystone
+	BarbicanServiceName = "barbican"
+	// KeyManagerDisabledBackend - castellan key manager that rejects any
+	// request, used when no key manager is available
+	KeyManagerDisabledBackend = "castellan.key_manager.not_implemented_key_manager.NotImplementedKeyManager"
+	// KeyManagerLocalBackend - castellan key manager of the local backend,
+	// shipped in the scripts volume (glance_signature.py)
+	KeyManagerLocalBackend = "glance_signature.LocalCertificateManager"
+	// SigningCertificatesVolume - name of the Volume built from the
+	// KeyManager localKeySecret
+	SigningCertificatesVolume = "signing-certificates"
+	// SigningCertificatesDir - path where the local KeyManager reads the
+	// signing certificates
+	SigningCertificatesDir = "/etc/glance/signing-certificates"
+	// ScriptsDir - path of the scripts volume, added to the GlanceAPI python
+	// path when the glance_signature extensions are loaded
+	ScriptsDir = "/usr/local/bin/container-scripts"
 	// ShortDuration -
 	ShortDuration = time.Duration(5) * time.Second
 	// NormalDuration -
 	NormalDuration = time.Duration(10) * time.Second
+	// StorageCapacityInterval - how often the volumes usage is collected when
+	// the storage monitoring is enabled
+	StorageCapacityInterval = time.Duration(5) * time.Minute
+	// ImageMigrationProgressInterval - how often the image API is polled to
+	// report the progress of a running image migration
+	ImageMigrationProgressInterval = time.Duration(30) * time.Second
+	// DiscoveryInterval - how long the result of an API discovery is cached
+	// before the API server is queried again
+	DiscoveryInterval = time.Duration(10) * time.Minute
 )
 
 // DbsyncPropagation keeps track of the DBSync Service Propagation Type
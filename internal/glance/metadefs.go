@@ -0,0 +1,176 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package glance
+
+import (
+	"maps"
+	"slices"
+
+	glancev1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/pod"
+	"github.com/openstack-k8s-operators/lib-common/modules/users"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// MetadefsDir - path where the metadefs ConfigMaps are projected
+	MetadefsDir = "/var/lib/glance/metadefs-custom"
+	// MetadefsCommand - loads the custom metadefs: existing namespaces are
+	// merged, and the new definitions take precedence
+	MetadefsCommand = "glance-manage --config-dir /etc/glance/glance.conf.d db load_metadefs " +
+		"--path " + MetadefsDir + " --merge --prefer_new"
+)
+
+// MetadefsJob - returns the Job that loads the metadefs provided by the
+// ConfigMaps referenced in the Glance spec. metadefsHash is the hash of the
+// ConfigMaps content, and it is passed to the Job to trigger a new run when
+// any of them changes
+func MetadefsJob(
+	instance *glancev1.Glance,
+	labels map[string]string,
+	annotations map[string]string,
+	metadefsHash string,
+) *batchv1.Job {
+	sources := []corev1.VolumeProjection{}
+	for _, cm := range instance.Spec.Metadefs {
+		sources = append(sources, corev1.VolumeProjection{
+			ConfigMap: &corev1.ConfigMapProjection{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: cm,
+				},
+			},
+		})
+	}
+
+	// Like the DbSyncJob, only DefaultsConfigFileName and
+	// CustomConfigFileName are required to reach the database
+	metadefsVolume := []corev1.Volume{
+		{
+			Name: "db-sync-config-data",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					DefaultMode: &configMode,
+					SecretName:  instance.Name + "-config-data",
+					Items: []corev1.KeyToPath{
+						{
+							Key:  DefaultsConfigFileName,
+							Path: DefaultsConfigFileName,
+						},
+						{
+							Key:  CustomConfigFileName,
+							Path: CustomConfigFileName,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "config-data",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					DefaultMode: &configMode,
+					SecretName:  instance.Name + "-config-data",
+				},
+			},
+		},
+		{
+			Name: "metadefs",
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					DefaultMode: &configMode,
+					Sources:     sources,
+				},
+			},
+		},
+	}
+
+	metadefsMounts := []corev1.VolumeMount{
+		{
+			Name:      "db-sync-config-data",
+			MountPath: "/etc/glance/glance.conf.d",
+			ReadOnly:  true,
+		},
+		{
+			Name:      "config-data",
+			MountPath: "/etc/my.cnf",
+			SubPath:   "my.cnf",
+			ReadOnly:  true,
+		},
+		{
+			Name:      "metadefs",
+			MountPath: MetadefsDir,
+			ReadOnly:  true,
+		},
+	}
+
+	// add CA cert if defined from the first api (sorted for deterministic selection)
+	for _, name := range slices.Sorted(maps.Keys(instance.Spec.GlanceAPIs)) {
+		api := instance.Spec.GlanceAPIs[name]
+		if api.TLS.CaBundleSecretName != "" {
+			metadefsVolume = append(metadefsVolume, api.TLS.CreateVolume())
+			metadefsMounts = append(metadefsMounts, api.TLS.CreateVolumeMounts(nil)...)
+
+			break
+		}
+	}
+
+	args := []string{"-c", MetadefsCommand}
+	envVars := map[string]env.Setter{}
+	envVars["METADEFS_HASH"] = env.SetValue(metadefsHash)
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ServiceName + "-metadefs",
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: annotations,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:                corev1.RestartPolicyOnFailure,
+					ServiceAccountName:           instance.RbacResourceName(),
+					AutomountServiceAccountToken: ptr.To(false),
+					SecurityContext:              pod.RestrictivePodSecurityContext(users.GlanceUID, users.GlanceGID),
+					Containers: []corev1.Container{
+						{
+							Name: ServiceName + "-metadefs",
+							Command: []string{
+								"/bin/bash",
+							},
+							Args:            args,
+							Image:           instance.Spec.ContainerImage,
+							SecurityContext: pod.RestrictiveSecurityContext(users.GlanceUID, users.GlanceGID),
+							Env:             env.MergeEnvs([]corev1.EnvVar{}, envVars),
+							VolumeMounts:    metadefsMounts,
+						},
+					},
+				},
+			},
+		},
+	}
+	if instance.Spec.NodeSelector != nil {
+		job.Spec.Template.Spec.NodeSelector = *instance.Spec.NodeSelector
+	}
+	job.Spec.Template.Spec.Volumes = metadefsVolume
+	return job
+}
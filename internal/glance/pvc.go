@@ -20,13 +20,31 @@ func GetPvc(api *glancev1.GlanceAPI, labels map[string]string, pvcType PvcType)
 	var requestSize string
 	var pvcName string
 	pvcAnnotation := map[string]string{}
+	storageClass := api.Spec.Storage.StorageClass
 
 	switch pvcType {
+	case PvcImageConv:
+		pvcAnnotation["image-conversion"] = "true"
+		requestSize = api.Spec.ImageConversion.Size
+		pvcName = ImageConversionVolume
+		if api.Spec.ImageConversion.StorageClass != "" {
+			storageClass = api.Spec.ImageConversion.StorageClass
+		}
+	case PvcStaging:
+		pvcAnnotation["image-staging"] = "true"
+		requestSize = api.Spec.Staging.Size
+		pvcName = StagingVolume
+		if api.Spec.Staging.StorageClass != "" {
+			storageClass = api.Spec.Staging.StorageClass
+		}
 	case PvcCache:
 		pvcAnnotation["image-cache"] = "true"
 		requestSize = api.Spec.ImageCache.Size
 		// append -cache to avoid confusion when listing PVCs
 		pvcName = fmt.Sprintf("%s-cache", ServiceName)
+		if api.Spec.ImageCache.StorageClass != "" {
+			storageClass = api.Spec.ImageCache.StorageClass
+		}
 	default:
 		pvcName = ServiceName
 		requestSize = api.Spec.Storage.StorageRequest
@@ -54,16 +72,28 @@ func GetPvc(api *glancev1.GlanceAPI, labels map[string]string, pvcType PvcType)
 		return pvc, err
 	}
 
+	accessMode := corev1.ReadWriteOnce
+	// a shared staging area is mounted by all the replicas
+	if pvcType == PvcStaging && api.Spec.Staging.Shared {
+		accessMode = corev1.ReadWriteMany
+	}
+
 	pvc.Spec = corev1.PersistentVolumeClaimSpec{
 		AccessModes: []corev1.PersistentVolumeAccessMode{
-			corev1.ReadWriteOnce,
+			accessMode,
 		},
 		Resources: corev1.VolumeResourceRequirements{
 			Requests: corev1.ResourceList{
 				corev1.ResourceStorage: storageSize,
 			},
 		},
-		StorageClassName: &api.Spec.Storage.StorageClass,
+	}
+	// an empty StorageClass disables the dynamic provisioning: leave it unset
+	// to use the default StorageClass of the cluster. The glance and cache
+	// VolumeClaimTemplates of the existing StatefulSets are immutable and
+	// keep it
+	if storageClass != "" || pvcType == PvcLocal || pvcType == PvcCache {
+		pvc.Spec.StorageClassName = &storageClass
 	}
 
 	return pvc, err
@@ -19,6 +19,8 @@ import (
 	"github.com/openstack-k8s-operators/lib-common/modules/common/volume"
 	"github.com/openstack-k8s-operators/lib-common/modules/storage"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
 )
 
 // configMode is the DefaultMode applied to every config Secret volume.
@@ -35,6 +37,7 @@ func GetVolumes(
 	secretNames []string,
 	extraVol []glancev1.GlanceExtraVolMounts,
 	svc []storage.PropagationType,
+	propertyProtections *glancev1.PropertyProtections,
 ) []corev1.Volume {
 
 	vm := []corev1.Volume{
@@ -65,6 +68,21 @@ func GetVolumes(
 	secretConfig, _ := volume.ConfigSecretVolumes(secretNames)
 	vm = append(vm, secretConfig...)
 
+	// PropertyProtections
+	if propertyProtections != nil {
+		vm = append(vm, corev1.Volume{
+			Name: PropertyProtectionsVolume,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					DefaultMode: &configMode,
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: propertyProtections.ConfigMap,
+					},
+				},
+			},
+		})
+	}
+
 	if hasCinder {
 		var dirOrCreate = corev1.HostPathDirectoryOrCreate
 
@@ -161,6 +179,9 @@ func GetVolumeMounts(
 	svc []storage.PropagationType,
 	apiMode string,
 	wsgi bool,
+	propertyProtections *glancev1.PropertyProtections,
+	audit bool,
+	signature bool,
 ) []corev1.VolumeMount {
 
 	vm := []corev1.VolumeMount{
@@ -241,6 +262,32 @@ func GetVolumeMounts(
 	}
 	_, secretConfig := volume.ConfigSecretVolumes(secretNames)
 	vm = append(vm, secretConfig...)
+	if propertyProtections != nil {
+		vm = append(vm, corev1.VolumeMount{
+			Name:      PropertyProtectionsVolume,
+			MountPath: PropertyProtectionsPath,
+			SubPath:   PropertyProtectionsFileName,
+			ReadOnly:  true,
+		})
+	}
+	// the paste pipeline is rendered when either the audit or the signature
+	// filter is enabled
+	if audit || signature {
+		vm = append(vm, corev1.VolumeMount{
+			Name:      "config-data",
+			MountPath: PasteDir + "/" + PasteFileName,
+			SubPath:   PasteFileName,
+			ReadOnly:  true,
+		})
+	}
+	if audit {
+		vm = append(vm, corev1.VolumeMount{
+			Name:      "config-data",
+			MountPath: AuditDir + "/" + AuditMapFileName,
+			SubPath:   AuditMapFileName,
+			ReadOnly:  true,
+		})
+	}
 	if hasCinder {
 		storageVolumeMounts := []corev1.VolumeMount{
 			{
@@ -289,7 +336,7 @@ func GetVolumeMounts(
 func GetCacheVolume(pvcName string) []corev1.Volume {
 	return []corev1.Volume{
 		{
-			Name: "glance-cache",
+			Name: CacheVolume,
 			VolumeSource: corev1.VolumeSource{
 				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
 					ClaimName: pvcName,
@@ -303,13 +350,101 @@ func GetCacheVolume(pvcName string) []corev1.Volume {
 func GetCacheVolumeMount() []corev1.VolumeMount {
 	return []corev1.VolumeMount{
 		{
-			Name:      "glance-cache",
+			Name:      CacheVolume,
 			MountPath: ImageCacheDir,
 			ReadOnly:  false,
 		},
 	}
 }
 
+// GetImageConversionVolume - Return the generic ephemeral Volume used as
+// image conversion work dir, when a per-replica PVC is not requested
+func GetImageConversionVolume(api *glancev1.GlanceAPI) ([]corev1.Volume, error) {
+	return GetEphemeralVolume(api, ImageConversionVolume, PvcImageConv, glancev1.VolumeTypeEphemeral)
+}
+
+// GetEphemeralVolume - Return a Volume that lives and dies with the Pod for
+// the given kind of glance PVC: either an emptyDir limited to the requested
+// size or a generic ephemeral volume
+func GetEphemeralVolume(
+	api *glancev1.GlanceAPI,
+	name string,
+	pvcType PvcType,
+	volumeType glancev1.VolumeType,
+) ([]corev1.Volume, error) {
+	pvc, err := GetPvc(api, nil, pvcType)
+	if err != nil {
+		return nil, err
+	}
+	if ptr.Deref(pvc.Spec.StorageClassName, "") == "" {
+		pvc.Spec.StorageClassName = nil
+	}
+	source := corev1.VolumeSource{
+		Ephemeral: &corev1.EphemeralVolumeSource{
+			VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
+				Spec: pvc.Spec,
+			},
+		},
+	}
+	if volumeType == glancev1.VolumeTypeEmptyDir {
+		sizeLimit := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		// the pruner brings the cache back under image_cache_max_size
+		// only after it has grown beyond it
+		if pvcType == PvcCache {
+			sizeLimit = *resource.NewQuantity(
+				sizeLimit.Value()*(100+CacheSizeLimitHeadroom)/100, sizeLimit.Format)
+		}
+		source = corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{
+				SizeLimit: &sizeLimit,
+			},
+		}
+	}
+	return []corev1.Volume{
+		{
+			Name:         name,
+			VolumeSource: source,
+		},
+	}, nil
+}
+
+// GetImageConversionVolumeMount - Return the VolumeMount of the image
+// conversion work dir
+func GetImageConversionVolumeMount() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{
+			Name:      ImageConversionVolume,
+			MountPath: ImageConversionDir,
+			ReadOnly:  false,
+		},
+	}
+}
+
+// GetStagingVolume - Return the Volume backed by the shared staging PVC
+func GetStagingVolume(claimName string) []corev1.Volume {
+	return []corev1.Volume{
+		{
+			Name: StagingVolume,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: claimName,
+				},
+			},
+		},
+	}
+}
+
+// GetStagingVolumeMount - Return the VolumeMount of the staging area
+func GetStagingVolumeMount() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{
+			Name:      StagingVolume,
+			MountPath: StagingDir,
+			ReadOnly:  false,
+		},
+	}
+}
+
 // GetScriptVolume -
 func GetScriptVolume() []corev1.Volume {
 	var scriptsVolumeDefaultMode int32 = 0755
@@ -332,7 +467,36 @@ func GetScriptVolumeMount() []corev1.VolumeMount {
 	return []corev1.VolumeMount{
 		{
 			Name:      "scripts",
-			MountPath: "/usr/local/bin/container-scripts",
+			MountPath: ScriptsDir,
+			ReadOnly:  true,
+		},
+	}
+}
+
+// GetSigningCertificatesVolume - Return the Volume built from the Secret
+// holding the certificates served by the local KeyManager
+func GetSigningCertificatesVolume(secretName string) []corev1.Volume {
+	return []corev1.Volume{
+		{
+			Name: SigningCertificatesVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					DefaultMode: &configMode,
+					SecretName:  secretName,
+				},
+			},
+		},
+	}
+}
+
+// GetSigningCertificatesVolumeMount - Return the VolumeMount of the
+// certificates served by the local KeyManager. The directory is not mounted
+// through a SubPath, so that the Secret updates are propagated to the Pods
+func GetSigningCertificatesVolumeMount() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{
+			Name:      SigningCertificatesVolume,
+			MountPath: SigningCertificatesDir,
 			ReadOnly:  true,
 		},
 	}
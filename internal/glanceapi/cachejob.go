@@ -134,3 +134,56 @@ func ImageCacheJob(
 	}
 	return cronjob
 }
+
+// ImageCacheCleanerContainer - returns the sidecar that periodically runs the
+// cache cleaner and pruner when the image cache is an emptyDir or an
+// ephemeral volume: such a volume can't be mounted by the CronJobs, and the
+// CleanerScheduler and PrunerScheduler are not used. The cache only goes
+// back under image_cache_max_size when the pruner runs, so it runs every
+// CachePruneInterval: an emptyDir that exceeds its size limit, which has a
+// CacheSizeLimitHeadroom, gets the Pod evicted
+func ImageCacheCleanerContainer(instance *glancev1.GlanceAPI) []corev1.Container {
+	if len(instance.Spec.ImageCache.Size) == 0 || ImageCachePVC(instance) {
+		return nil
+	}
+	configDir := "/etc/glance/glance.conf.d"
+	cleanupScript := fmt.Sprintf(
+		"while true; do %s --config-dir %s; for i in $(seq %d); do %s --config-dir %s; sleep %d; done; done",
+		glance.GlanceCacheCleaner, configDir,
+		glance.CacheCleanupInterval/glance.CachePruneInterval,
+		glance.GlanceCachePruner, configDir,
+		glance.CachePruneInterval,
+	)
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      "config-data",
+			MountPath: configDir + "/" + glance.DefaultsConfigFileName,
+			SubPath:   glance.DefaultsConfigFileName,
+			ReadOnly:  true,
+		},
+	}
+	if instance.Spec.TLS.CaBundleSecretName != "" {
+		volumeMounts = append(volumeMounts, instance.Spec.TLS.CreateVolumeMounts(nil)...)
+	}
+	volumeMounts = append(volumeMounts, glance.GetCacheVolumeMount()...)
+
+	return []corev1.Container{
+		{
+			Name:  glance.ServiceName + "-cache-cleaner",
+			Image: instance.Spec.ContainerImage,
+			Command: []string{
+				"/usr/bin/dumb-init",
+			},
+			Args: []string{
+				"--single-child",
+				"--",
+				"/bin/bash",
+				"-c",
+				cleanupScript,
+			},
+			VolumeMounts:    volumeMounts,
+			SecurityContext: pod.RestrictiveSecurityContext(users.GlanceUID, users.GlanceGID),
+			Resources:       instance.Spec.GetLogResources(),
+		},
+	}
+}
@@ -0,0 +1,96 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package glanceapi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	glancev1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/glance-operator/internal/glance"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// statsSummary - the subset of the kubelet stats summary
+// (/stats/summary) that reports the usage of the Pod volumes
+type statsSummary struct {
+	Pods []podStats `json:"pods"`
+}
+
+type podStats struct {
+	PodRef struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"podRef"`
+	Volumes []volumeStats `json:"volume"`
+}
+
+type volumeStats struct {
+	Name           string  `json:"name"`
+	CapacityBytes  *uint64 `json:"capacityBytes"`
+	UsedBytes      *uint64 `json:"usedBytes"`
+	AvailableBytes *uint64 `json:"availableBytes"`
+}
+
+// ReplicaStorage - parses the kubelet stats summary of the node running the
+// Pod podName and returns the usage of its image and cache volumes. It
+// returns nil when the summary does not report the Pod
+func ReplicaStorage(
+	summary []byte,
+	namespace string,
+	podName string,
+) (*glancev1.ReplicaStorageStatus, error) {
+	stats := statsSummary{}
+	if err := json.Unmarshal(summary, &stats); err != nil {
+		return nil, fmt.Errorf("parsing the stats summary: %w", err)
+	}
+	for _, p := range stats.Pods {
+		if p.PodRef.Name != podName || p.PodRef.Namespace != namespace {
+			continue
+		}
+		replica := &glancev1.ReplicaStorageStatus{
+			LastUpdateTime: metav1.Now(),
+		}
+		for _, v := range p.Volumes {
+			switch v.Name {
+			case glance.ServiceName:
+				replica.Image = volumeUsage(v)
+			case glance.CacheVolume:
+				replica.Cache = volumeUsage(v)
+			}
+		}
+		return replica, nil
+	}
+	return nil, nil
+}
+
+// volumeUsage - converts the kubelet volume stats, it returns nil when the
+// kubelet has not measured the filesystem yet
+func volumeUsage(v volumeStats) *glancev1.VolumeUsage {
+	if v.CapacityBytes == nil || v.UsedBytes == nil || *v.CapacityBytes == 0 {
+		return nil
+	}
+	usage := &glancev1.VolumeUsage{
+		Capacity:    *resource.NewQuantity(int64(*v.CapacityBytes), resource.BinarySI),
+		Used:        *resource.NewQuantity(int64(*v.UsedBytes), resource.BinarySI),
+		UsedPercent: int(*v.UsedBytes * 100 / *v.CapacityBytes),
+	}
+	if v.AvailableBytes != nil {
+		usage.Available = *resource.NewQuantity(int64(*v.AvailableBytes), resource.BinarySI)
+	}
+	return usage
+}
@@ -0,0 +1,185 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package glanceapi
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"strconv"
+
+	glancev1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/glance-operator/internal/glance"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/pod"
+	"github.com/openstack-k8s-operators/lib-common/modules/users"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// ImageCleanupCommand - removes the stale images and the orphaned
+	// staging files, see templates/common/bin/glance-image-cleanup
+	ImageCleanupCommand = "/usr/local/bin/container-scripts/glance-image-cleanup"
+)
+
+// ImageCleanupJob - returns the CronJob that removes the stale images through
+// the given GlanceAPI, and the orphaned files of the shared staging PVCs
+// passed in stagingPVCs. Each PVC is mounted in a sub directory of
+// glance.StagingDir
+func ImageCleanupJob(
+	instance *glancev1.GlanceAPI,
+	cleanup glancev1.ImageCleanup,
+	stagingPVCs []string,
+	cronSpec glance.CronJobSpec,
+) *batchv1.CronJob {
+	cronJobVolume, cronJobVolumeMounts := apiClientVolumes(instance)
+	for i, claim := range stagingPVCs {
+		volumeName := fmt.Sprintf("%s-%d", glance.StagingVolume, i)
+		cronJobVolume = append(cronJobVolume, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: claim,
+				},
+			},
+		})
+		cronJobVolumeMounts = append(cronJobVolumeMounts, corev1.VolumeMount{
+			Name:      volumeName,
+			MountPath: filepath.Join(glance.StagingDir, claim),
+		})
+	}
+
+	envVars := map[string]env.Setter{}
+	envVars["DRY_RUN"] = env.SetValue(strconv.FormatBool(cleanup.DryRun))
+	envVars["MAX_AGE_QUEUED"] = env.SetValue(strconv.Itoa(cleanup.MaxAge.Queued))
+	envVars["MAX_AGE_SAVING"] = env.SetValue(strconv.Itoa(cleanup.MaxAge.Saving))
+	envVars["MAX_AGE_UPLOADING"] = env.SetValue(strconv.Itoa(cleanup.MaxAge.Uploading))
+	envVars["MAX_AGE_IMPORTING"] = env.SetValue(strconv.Itoa(cleanup.MaxAge.Importing))
+	envVars["STAGING_ROOT"] = env.SetValue(glance.StagingDir)
+
+	return imageCleanupCronJob(instance, cronJobVolume, cronJobVolumeMounts, envVars, nil, cronSpec)
+}
+
+// StagingCleanupJob - returns the CronJob that removes the orphaned files of
+// the per-replica staging area of the replica podName, held by the PVC
+// cronSpec.PvcClaim. Like the ConsistencyCheckJob it runs on the same node
+// as the replica, and the pending imports are listed through the GlanceAPI
+func StagingCleanupJob(
+	instance *glancev1.GlanceAPI,
+	cleanup glancev1.ImageCleanup,
+	podName string,
+	cronSpec glance.CronJobSpec,
+) *batchv1.CronJob {
+	cronJobVolume, cronJobVolumeMounts := apiClientVolumes(instance)
+	cronJobVolume = append(cronJobVolume, corev1.Volume{
+		Name: glance.StagingVolume,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: *cronSpec.PvcClaim,
+			},
+		},
+	})
+	// without a staging volume, os_glance_staging_store is a directory of
+	// the glance PVC
+	stagingRoot := glance.DefaultStagingDir
+	mountPath := "/var/lib/glance"
+	if StagingPVC(instance) {
+		stagingRoot = glance.StagingDir
+		mountPath = glance.StagingDir
+	}
+	cronJobVolumeMounts = append(cronJobVolumeMounts, corev1.VolumeMount{
+		Name:      glance.StagingVolume,
+		MountPath: mountPath,
+	})
+
+	envVars := map[string]env.Setter{}
+	envVars["DRY_RUN"] = env.SetValue(strconv.FormatBool(cleanup.DryRun))
+	envVars["STAGING_ONLY"] = env.SetValue("true")
+	envVars["STAGING_ROOT"] = env.SetValue(stagingRoot)
+
+	return imageCleanupCronJob(instance, cronJobVolume, cronJobVolumeMounts, envVars,
+		ColocateWithPod(podName), cronSpec)
+}
+
+// imageCleanupCronJob - returns the CronJob running ImageCleanupCommand with
+// the given volumes and environment
+func imageCleanupCronJob(
+	instance *glancev1.GlanceAPI,
+	cronJobVolume []corev1.Volume,
+	cronJobVolumeMounts []corev1.VolumeMount,
+	envVars map[string]env.Setter,
+	affinity *corev1.Affinity,
+	cronSpec glance.CronJobSpec,
+) *batchv1.CronJob {
+	// the operator finds the Pods by label to read their termination message
+	podLabels := maps.Clone(cronSpec.Labels)
+	if podLabels == nil {
+		podLabels = map[string]string{}
+	}
+	podLabels[glance.CronJobNameLabel] = cronSpec.Name
+
+	cronjob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cronSpec.Name,
+			Namespace: instance.Namespace,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:          cronSpec.Schedule,
+			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: cronSpec.Annotations,
+					Labels:      cronSpec.Labels,
+				},
+				Spec: batchv1.JobSpec{
+					Parallelism: ptr.To(int32(1)),
+					Completions: ptr.To(int32(1)),
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: podLabels,
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:                     glance.ServiceName + "-" + string(cronSpec.CjType),
+									Image:                    instance.Spec.ContainerImage,
+									Command:                  []string{ImageCleanupCommand},
+									Env:                      env.MergeEnvs([]corev1.EnvVar{}, envVars),
+									VolumeMounts:             cronJobVolumeMounts,
+									SecurityContext:          pod.RestrictiveSecurityContext(users.GlanceUID, users.GlanceGID),
+									TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+								},
+							},
+							Volumes:                      cronJobVolume,
+							Affinity:                     affinity,
+							RestartPolicy:                corev1.RestartPolicyNever,
+							ServiceAccountName:           instance.Spec.ServiceAccount,
+							AutomountServiceAccountToken: ptr.To(false),
+							SecurityContext:              pod.RestrictivePodSecurityContext(users.GlanceUID, users.GlanceGID),
+						},
+					},
+				},
+			},
+		},
+	}
+	if instance.Spec.NodeSelector != nil {
+		cronjob.Spec.JobTemplate.Spec.Template.Spec.NodeSelector = *instance.Spec.NodeSelector
+	}
+	return cronjob
+}
@@ -0,0 +1,192 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package glanceapi
+
+import (
+	"maps"
+	"strconv"
+
+	glancev1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/glance-operator/internal/glance"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/pod"
+	"github.com/openstack-k8s-operators/lib-common/modules/users"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// ConsistencyCheckCommand - compares the file stores with the image
+	// locations, see templates/common/bin/glance-consistency-check
+	ConsistencyCheckCommand = "/usr/local/bin/container-scripts/glance-consistency-check"
+)
+
+// ConsistencyCheckSummary - result written by the consistency check Job in
+// its termination message. The list of IDs is truncated, the Job log reports
+// all of them
+type ConsistencyCheckSummary struct {
+	glancev1.ConsistencyCheckStatus
+	OrphanFileIDs []string `json:"orphanFileIDs"`
+}
+
+// ConsistencyReportName - name of the ConfigMap listing the IDs found by
+// the consistency checks of a GlanceAPI
+func ConsistencyReportName(instance *glancev1.GlanceAPI) string {
+	return instance.Name + "-consistency-report"
+}
+
+// ConsistencyCheckJob - returns the CronJob that checks the file stores of
+// the replica podName. Like the DBPurgeJob it gets the database connection
+// from the config, and like the ImageCacheJob it mounts the glance PVC of
+// the replica, so it runs on the same node
+func ConsistencyCheckJob(
+	instance *glancev1.GlanceAPI,
+	podName string,
+	cronSpec glance.CronJobSpec,
+) *batchv1.CronJob {
+	cronJobVolume := []corev1.Volume{
+		{
+			Name: "config-data",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					DefaultMode: &configMode,
+					SecretName:  instance.Name + "-config-data",
+					Items: []corev1.KeyToPath{
+						{
+							Key:  glance.DefaultsConfigFileName,
+							Path: glance.DefaultsConfigFileName,
+						},
+						{
+							Key:  glance.CustomConfigFileName,
+							Path: glance.CustomConfigFileName,
+						},
+						{
+							Key:  glance.CustomServiceConfigFileName,
+							Path: glance.CustomServiceConfigFileName,
+						},
+						{
+							Key:  glance.CustomServiceConfigSecretsFileName,
+							Path: glance.CustomServiceConfigSecretsFileName,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "db-config-data",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					DefaultMode: &configMode,
+					SecretName:  instance.Name + "-config-data",
+				},
+			},
+		},
+		{
+			Name: glance.ServiceName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: *cronSpec.PvcClaim,
+				},
+			},
+		},
+	}
+	cronJobVolume = append(cronJobVolume, glance.GetScriptVolume()...)
+	cronJobVolumeMounts := []corev1.VolumeMount{
+		{
+			Name:      "config-data",
+			MountPath: "/etc/glance/glance.conf.d",
+			ReadOnly:  true,
+		},
+		{
+			Name:      "db-config-data",
+			MountPath: "/etc/my.cnf",
+			SubPath:   "my.cnf",
+			ReadOnly:  true,
+		},
+		{
+			Name:      glance.ServiceName,
+			MountPath: "/var/lib/glance",
+			ReadOnly:  !instance.Spec.ConsistencyCheck.Repair,
+		},
+	}
+	cronJobVolumeMounts = append(cronJobVolumeMounts, glance.GetScriptVolumeMount()...)
+
+	if instance.Spec.TLS.CaBundleSecretName != "" {
+		cronJobVolume = append(cronJobVolume, instance.Spec.TLS.CreateVolume())
+		cronJobVolumeMounts = append(cronJobVolumeMounts, instance.Spec.TLS.CreateVolumeMounts(nil)...)
+	}
+
+	envVars := map[string]env.Setter{}
+	envVars["REPAIR"] = env.SetValue(strconv.FormatBool(instance.Spec.ConsistencyCheck.Repair))
+
+	// the operator finds the Pods by label to read their termination message
+	podLabels := maps.Clone(cronSpec.Labels)
+	if podLabels == nil {
+		podLabels = map[string]string{}
+	}
+	podLabels[glance.CronJobNameLabel] = cronSpec.Name
+
+	cronjob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cronSpec.Name,
+			Namespace: instance.Namespace,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:          cronSpec.Schedule,
+			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: cronSpec.Annotations,
+					Labels:      cronSpec.Labels,
+				},
+				Spec: batchv1.JobSpec{
+					Parallelism: ptr.To(int32(1)),
+					Completions: ptr.To(int32(1)),
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: podLabels,
+						},
+						Spec: corev1.PodSpec{
+							SecurityContext:              pod.RestrictivePodSecurityContext(users.GlanceUID, users.GlanceGID),
+							Affinity:                     ColocateWithPod(podName),
+							AutomountServiceAccountToken: ptr.To(false),
+							Containers: []corev1.Container{
+								{
+									Name:                     cronSpec.Name,
+									Image:                    instance.Spec.ContainerImage,
+									Command:                  []string{cronSpec.Command},
+									Env:                      env.MergeEnvs([]corev1.EnvVar{}, envVars),
+									VolumeMounts:             cronJobVolumeMounts,
+									SecurityContext:          pod.RestrictiveSecurityContext(users.GlanceUID, users.GlanceGID),
+									TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+								},
+							},
+							Volumes:            cronJobVolume,
+							RestartPolicy:      corev1.RestartPolicyNever,
+							ServiceAccountName: instance.Spec.ServiceAccount,
+						},
+					},
+				},
+			},
+		},
+	}
+	if instance.Spec.NodeSelector != nil {
+		cronjob.Spec.JobTemplate.Spec.Template.Spec.NodeSelector = *instance.Spec.NodeSelector
+	}
+	return cronjob
+}
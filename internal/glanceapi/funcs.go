@@ -1,6 +1,9 @@
 package glanceapi
 
 import (
+	"strconv"
+	"strings"
+
 	glancev1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
 
 	glance "github.com/openstack-k8s-operators/glance-operator/internal/glance"
@@ -8,9 +11,35 @@ import (
 	"github.com/openstack-k8s-operators/lib-common/modules/common/service"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// DefaultWorkers - glance-api workers used when no CPU is requested
+	DefaultWorkers int32 = 3
+	// DefaultProcesses - WSGIDaemonProcess processes used when no CPU is
+	// requested
+	DefaultProcesses int32 = 4
+	// DefaultThreads - threads of each WSGIDaemonProcess process
+	DefaultThreads int32 = 10
+	// MaxProcesses - upper bound of the workers/processes derived from the
+	// container resources
+	MaxProcesses int32 = 32
+)
+
+// processMemory - memory budget of a single glance-api process, used to
+// bound the number of workers/processes when a memory limit is set
+var processMemory = resource.MustParse("256Mi")
+
+// ProcessSizing - number of workers/processes/threads rendered in the
+// GlanceAPI config
+type ProcessSizing struct {
+	Workers   int32
+	Processes int32
+	Threads   int32
+}
+
 // GetGlanceEndpoints - returns the glance endpoints map based on the apiType of the glance-api
 // default is split, in case of single both internal and public endpoint get returned
 func GetGlanceEndpoints(apiType string) map[service.Endpoint]endpoint.Data {
@@ -64,3 +93,95 @@ func ColocateWithPod(podName string) *corev1.Affinity {
 		},
 	}
 }
+
+// GetImportFilteringOpts - Returns the [import_filtering_opts] key/value pairs
+// that should be rendered for the given ImportFiltering. Glance honors an
+// allow list over the matching deny list, and it ships a non-empty default
+// for allowed_schemes and allowed_ports: when only a deny list is provided
+// the allow list is explicitly reset, otherwise the deny list is ignored
+func GetImportFilteringOpts(f *glancev1.ImportFiltering) map[string]string {
+	opts := map[string]string{}
+	if f == nil {
+		return opts
+	}
+	setOpts := func(name string, allowed []string, disallowed []string) {
+		if len(allowed) > 0 {
+			opts["allowed_"+name] = strings.Join(allowed, ",")
+			return
+		}
+		if len(disallowed) > 0 {
+			opts["allowed_"+name] = ""
+			opts["disallowed_"+name] = strings.Join(disallowed, ",")
+		}
+	}
+	portsToString := func(ports []int32) []string {
+		p := []string{}
+		for _, port := range ports {
+			p = append(p, strconv.Itoa(int(port)))
+		}
+		return p
+	}
+	setOpts("schemes", f.AllowedSchemes, f.DisallowedSchemes)
+	setOpts("hosts", f.AllowedHosts, f.DisallowedHosts)
+	setOpts("ports", portsToString(f.AllowedPorts), portsToString(f.DisallowedPorts))
+	return opts
+}
+
+// GetProcessSizing - Returns the workers/processes/threads of a GlanceAPI.
+// Explicit values take precedence; otherwise one process per requested CPU
+// of the serving container (glance-api for workers, httpd for processes) is
+// run, bounded by the memory available to it. Without any CPU/memory
+// resources the historical defaults are preserved
+func GetProcessSizing(t glancev1.GlanceAPITemplate) ProcessSizing {
+	s := ProcessSizing{
+		Workers:   getProcesses(t.GetAPIResources(), DefaultWorkers),
+		Processes: getProcesses(t.GetHTTPDResources(), DefaultProcesses),
+		Threads:   DefaultThreads,
+	}
+	if t.Workers != nil {
+		s.Workers = *t.Workers
+	}
+	if t.Processes != nil {
+		s.Processes = *t.Processes
+	}
+	if t.Threads != nil {
+		s.Threads = *t.Threads
+	}
+	return s
+}
+
+// getProcesses - Returns the number of processes that fit the given
+// resources, or defaultProcesses if no CPU is requested
+func getProcesses(r corev1.ResourceRequirements, defaultProcesses int32) int32 {
+	processes := defaultProcesses
+	// CPU requests are guaranteed, while limits only cap the usage
+	if cpu := getResource(r, corev1.ResourceCPU, false); cpu != nil {
+		cores := int32(min((cpu.MilliValue()+999)/1000, int64(MaxProcesses)))
+		processes = max(cores, 1)
+	}
+	// the memory limit is what triggers the OOM killer
+	if mem := getResource(r, corev1.ResourceMemory, true); mem != nil {
+		maxProcesses := int32(max(min(mem.Value()/processMemory.Value(), int64(MaxProcesses)), 1))
+		processes = min(processes, maxProcesses)
+	}
+	return processes
+}
+
+// getResource - Returns the request (or the limit, if preferLimit is set) of
+// the given resource, falling back to the other one when it's not set
+func getResource(
+	r corev1.ResourceRequirements,
+	name corev1.ResourceName,
+	preferLimit bool,
+) *resource.Quantity {
+	lists := []corev1.ResourceList{r.Requests, r.Limits}
+	if preferLimit {
+		lists = []corev1.ResourceList{r.Limits, r.Requests}
+	}
+	for _, l := range lists {
+		if q, ok := l[name]; ok && !q.IsZero() {
+			return &q
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package glanceapi
+
+import (
+	"path/filepath"
+
+	glancev1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/glance-operator/internal/glance"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/pod"
+	"github.com/openstack-k8s-operators/lib-common/modules/users"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// ImageUploadCommand - stages the image data, see
+	// templates/common/bin/glance-upload
+	ImageUploadCommand = "/usr/local/bin/container-scripts/glance-upload"
+	// imageSourcePath - where the PVC holding the image file is mounted
+	imageSourcePath = "/var/lib/glance/image-source"
+)
+
+// ImageUploadJob - returns the Job that stages the data of a GlanceImage with
+// a PVC source through the given GlanceAPI. The image is then imported with
+// the glance-direct method
+func ImageUploadJob(
+	instance *glancev1.GlanceAPI,
+	image *glancev1.GlanceImage,
+	labels map[string]string,
+) *batchv1.Job {
+	jobVolumes, jobMounts := apiClientVolumes(instance)
+	jobVolumes = append(jobVolumes, corev1.Volume{
+		Name: "image-source",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: image.Spec.Source.PVC.ClaimName,
+				ReadOnly:  true,
+			},
+		},
+	})
+	jobMounts = append(jobMounts, corev1.VolumeMount{
+		Name:      "image-source",
+		MountPath: imageSourcePath,
+		ReadOnly:  true,
+	})
+
+	envVars := map[string]env.Setter{}
+	envVars["IMAGE_ID"] = env.SetValue(image.Status.ImageID)
+	envVars["IMAGE_FILE"] = env.SetValue(filepath.Join(imageSourcePath, filepath.Clean("/"+image.Spec.Source.PVC.Path)))
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      image.Name + "-upload",
+			Namespace: image.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.To(int32(2)),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:                corev1.RestartPolicyOnFailure,
+					ServiceAccountName:           instance.Spec.ServiceAccount,
+					AutomountServiceAccountToken: ptr.To(false),
+					SecurityContext:              pod.RestrictivePodSecurityContext(users.GlanceUID, users.GlanceGID),
+					Containers: []corev1.Container{
+						{
+							Name:            glance.ServiceName + "-upload",
+							Command:         []string{ImageUploadCommand},
+							Image:           instance.Spec.ContainerImage,
+							SecurityContext: pod.RestrictiveSecurityContext(users.GlanceUID, users.GlanceGID),
+							Env:             env.MergeEnvs([]corev1.EnvVar{}, envVars),
+							VolumeMounts:    jobMounts,
+						},
+					},
+					Volumes: jobVolumes,
+				},
+			},
+		},
+	}
+	if instance.Spec.NodeSelector != nil {
+		job.Spec.Template.Spec.NodeSelector = *instance.Spec.NodeSelector
+	}
+	return job
+}
@@ -0,0 +1,143 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package glanceapi
+
+import (
+	"fmt"
+
+	glancev1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	glance "github.com/openstack-k8s-operators/glance-operator/internal/glance"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/pod"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/volume"
+	"github.com/openstack-k8s-operators/lib-common/modules/users"
+	"golang.org/x/exp/maps"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// MetricsLabel - label of the metrics Service, used by the ServiceMonitor
+	// selector
+	MetricsLabel = "glance-metrics"
+	// httpdExporterPortName -
+	httpdExporterPortName = "httpd-metrics"
+	// logExporterPortName -
+	logExporterPortName = "request-metrics"
+)
+
+// MetricsContainers - returns the sidecars exporting the GlanceAPI metrics:
+// the mod_status exporter is always deployed, while the access log exporter
+// is only added when its image is provided
+func MetricsContainers(instance *glancev1.GlanceAPI) []corev1.Container {
+	m := instance.Spec.Metrics
+	if m == nil {
+		return nil
+	}
+	containers := []corev1.Container{
+		{
+			Name:  glance.ServiceName + "-httpd-exporter",
+			Image: m.GetHTTPDExporterImage(),
+			Args: []string{
+				fmt.Sprintf("--scrape_uri=http://localhost:%d/server-status?auto", glance.MetricsStatusPort),
+				fmt.Sprintf("--web.listen-address=:%d", glance.HTTPDExporterPort),
+			},
+			Ports: []corev1.ContainerPort{
+				{
+					Name:          httpdExporterPortName,
+					ContainerPort: glance.HTTPDExporterPort,
+					Protocol:      corev1.ProtocolTCP,
+				},
+			},
+			SecurityContext: pod.RestrictiveSecurityContext(users.GlanceUID, users.GlanceGID),
+			Resources:       instance.Spec.GetLogResources(),
+		},
+	}
+	if m.LogExporterImage != "" {
+		containers = append(containers, corev1.Container{
+			Name:  glance.ServiceName + "-request-exporter",
+			Image: m.LogExporterImage,
+			Args: []string{
+				"--progs", "/etc/mtail",
+				"--logs", glance.MetricsAccessLog,
+				"--port", fmt.Sprintf("%d", glance.LogExporterPort),
+			},
+			Ports: []corev1.ContainerPort{
+				{
+					Name:          logExporterPortName,
+					ContainerPort: glance.LogExporterPort,
+					Protocol:      corev1.ProtocolTCP,
+				},
+			},
+			SecurityContext: pod.RestrictiveSecurityContext(users.GlanceUID, users.GlanceGID),
+			VolumeMounts: []corev1.VolumeMount{
+				volume.WritableDirVolumeMount(glance.LogVolume, "/var/log/glance"),
+				{
+					Name:      "config-data",
+					MountPath: "/etc/mtail/" + glance.MetricsProgramFileName,
+					SubPath:   glance.MetricsProgramFileName,
+					ReadOnly:  true,
+				},
+			},
+			Resources: instance.Spec.GetLogResources(),
+		})
+	}
+	return containers
+}
+
+// MetricsService - returns the Service exposing the metrics sidecars ports,
+// that can be selected by a ServiceMonitor through the MetricsLabel
+func MetricsService(
+	instance *glancev1.GlanceAPI,
+	serviceLabels map[string]string,
+) *corev1.Service {
+	labels := maps.Clone(serviceLabels)
+	labels[MetricsLabel] = instance.Name
+
+	ports := []corev1.ServicePort{
+		{
+			Name:       httpdExporterPortName,
+			Port:       glance.HTTPDExporterPort,
+			TargetPort: intstr.FromString(httpdExporterPortName),
+			Protocol:   corev1.ProtocolTCP,
+		},
+	}
+	if instance.Spec.Metrics != nil && instance.Spec.Metrics.LogExporterImage != "" {
+		ports = append(ports, corev1.ServicePort{
+			Name:       logExporterPortName,
+			Port:       glance.LogExporterPort,
+			TargetPort: intstr.FromString(logExporterPortName),
+			Protocol:   corev1.ProtocolTCP,
+		})
+	}
+
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      MetricsServiceName(instance),
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: serviceLabels,
+			Ports:    ports,
+		},
+	}
+}
+
+// MetricsServiceName -
+func MetricsServiceName(instance *glancev1.GlanceAPI) string {
+	return fmt.Sprintf("%s-%s-metrics", glance.ServiceName, instance.APIName())
+}
@@ -0,0 +1,153 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package glanceapi
+
+import (
+	"encoding/json"
+	"strconv"
+
+	glancev1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/glance-operator/internal/glance"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/pod"
+	"github.com/openstack-k8s-operators/lib-common/modules/users"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// ImageMigrationCommand - copies the images between the stores, see
+	// templates/common/bin/glance-migrate
+	ImageMigrationCommand = "/usr/local/bin/container-scripts/glance-migrate"
+)
+
+// ImageMigrationSummary - progress written by the image migration Job in
+// its termination message
+type ImageMigrationSummary struct {
+	Total        int      `json:"total"`
+	Copied       int      `json:"copied"`
+	Failed       int      `json:"failed"`
+	Remaining    int      `json:"remaining"`
+	FailedImages []string `json:"failedImages"`
+}
+
+// ImageMigrationJobName - name of the Job that migrates the images
+func ImageMigrationJobName(migration *glancev1.GlanceImageMigration) string {
+	return migration.Name + "-migrate"
+}
+
+// ImageMigrationJob - returns the Job that copies the images of a store to
+// another one through the given GlanceAPI. The Job authenticates with the
+// keystone_authtoken credentials of the GlanceAPI config
+func ImageMigrationJob(
+	instance *glancev1.GlanceAPI,
+	migration *glancev1.GlanceImageMigration,
+	labels map[string]string,
+) (*batchv1.Job, error) {
+	filter, err := json.Marshal(migration.Spec.ImageFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	jobVolumes, jobMounts := apiClientVolumes(instance)
+
+	envVars := map[string]env.Setter{}
+	envVars["SOURCE_STORE"] = env.SetValue(migration.Spec.SourceStore)
+	envVars["TARGET_STORE"] = env.SetValue(migration.Spec.TargetStore)
+	envVars["IMAGE_FILTER"] = env.SetValue(string(filter))
+	envVars["REMOVE_SOURCE"] = env.SetValue(strconv.FormatBool(migration.Spec.RemoveSourceLocation))
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ImageMigrationJobName(migration),
+			Namespace: migration.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			// The images already copied are skipped, a new attempt only
+			// processes the remaining ones
+			BackoffLimit: ptr.To(int32(2)),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:                corev1.RestartPolicyNever,
+					ServiceAccountName:           instance.Spec.ServiceAccount,
+					AutomountServiceAccountToken: ptr.To(false),
+					SecurityContext:              pod.RestrictivePodSecurityContext(users.GlanceUID, users.GlanceGID),
+					Containers: []corev1.Container{
+						{
+							Name:                     glance.ServiceName + "-migrate",
+							Command:                  []string{ImageMigrationCommand},
+							Image:                    instance.Spec.ContainerImage,
+							SecurityContext:          pod.RestrictiveSecurityContext(users.GlanceUID, users.GlanceGID),
+							Env:                      env.MergeEnvs([]corev1.EnvVar{}, envVars),
+							VolumeMounts:             jobMounts,
+							TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+						},
+					},
+					Volumes: jobVolumes,
+				},
+			},
+		},
+	}
+	if instance.Spec.NodeSelector != nil {
+		job.Spec.Template.Spec.NodeSelector = *instance.Spec.NodeSelector
+	}
+	return job, nil
+}
+
+// apiClientVolumes - returns the volumes of a Job that talks to the image API
+// with the keystone_authtoken credentials of the GlanceAPI config
+func apiClientVolumes(
+	instance *glancev1.GlanceAPI,
+) ([]corev1.Volume, []corev1.VolumeMount) {
+	volumes := []corev1.Volume{
+		{
+			Name: "config-data",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					DefaultMode: &configMode,
+					SecretName:  instance.Name + "-config-data",
+					Items: []corev1.KeyToPath{
+						{
+							Key:  glance.DefaultsConfigFileName,
+							Path: glance.DefaultsConfigFileName,
+						},
+					},
+				},
+			},
+		},
+	}
+	volumes = append(volumes, glance.GetScriptVolume()...)
+	mounts := []corev1.VolumeMount{
+		{
+			Name:      "config-data",
+			MountPath: "/etc/glance/glance.conf.d",
+			ReadOnly:  true,
+		},
+	}
+	mounts = append(mounts, glance.GetScriptVolumeMount()...)
+
+	if instance.Spec.TLS.CaBundleSecretName != "" {
+		volumes = append(volumes, instance.Spec.TLS.CreateVolume())
+		mounts = append(mounts, instance.Spec.TLS.CreateVolumeMounts(nil)...)
+	}
+	return volumes, mounts
+}
@@ -17,6 +17,8 @@ package glanceapi
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	glancev1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
 	glance "github.com/openstack-k8s-operators/glance-operator/internal/glance"
@@ -42,6 +44,9 @@ import (
 	"k8s.io/utils/ptr"
 )
 
+// RevisionLabel - label identifying the Pods of a StatefulSet revision
+const RevisionLabel = "glance.openstack.org/revision"
+
 // workerSelfReferenceScript replicates kolla_extend_start's behavior: when
 // GLANCE_DOMAIN is set (distributed image import), each replica must write
 // its own runtime-only worker_self_reference_url into glance.conf.d -- the
@@ -54,6 +59,142 @@ EOF
 fi
 `
 
+// StatefulSetName - returns the name of the StatefulSet deployed for a given
+// revision. Revision 0 keeps the historical name, which is also the name of
+// the headless Service
+func StatefulSetName(instance *glancev1.GlanceAPI, revision int32) string {
+	stsName := instance.Name
+	if instance.Spec.APIType != glancev1.APISingle {
+		stsName = fmt.Sprintf("%s-api", instance.Name)
+	}
+	if revision > 0 {
+		stsName = fmt.Sprintf("%s-r%d", stsName, revision)
+	}
+	return stsName
+}
+
+// RevisionLabels - returns labels with the RevisionLabel of the given
+// StatefulSet revision
+func RevisionLabels(labels map[string]string, revision int32) map[string]string {
+	l := maps.Clone(labels)
+	l[RevisionLabel] = strconv.Itoa(int(revision))
+	return l
+}
+
+// ServiceSelector - returns the selector of the API Services. Until a new
+// StatefulSet revision is rolled out there is nothing to tell apart, and the
+// Pods of revision 0 are selected by the plain service labels; afterwards
+// only the Pods of the serving revision are selected
+func ServiceSelector(instance *glancev1.GlanceAPI, labels map[string]string) map[string]string {
+	if instance.Status.StatefulSetRevision == 0 {
+		return labels
+	}
+	return RevisionLabels(labels, instance.Status.ServingRevision)
+}
+
+// LocalFileStore - returns true if the images are stored in the per-replica
+// glance PVCs, i.e. a file backend is enabled and the storage is not
+// external. Those PVCs are bound to the StatefulSet name, so they can't be
+// carried over to a new StatefulSet revision
+func LocalFileStore(instance *glancev1.GlanceAPI) bool {
+	if instance.Spec.Storage.External {
+		return false
+	}
+	backends := glancev1.GetEnabledBackends(instance.Spec.CustomServiceConfig)
+	if len(backends) == 0 {
+		return true
+	}
+	for _, b := range backends {
+		backendToken := strings.SplitN(b, ":", 2)
+		if len(backendToken) == 2 && backendToken[1] == "file" {
+			return true
+		}
+	}
+	return false
+}
+
+// PVCOrdinal - returns the ordinal of the replica a PVC belongs to, if the
+// PVC has been created by the given StatefulSet (<template>-<sts>-<ordinal>)
+func PVCOrdinal(pvcName string, stsName string) (int, bool) {
+	idx := strings.LastIndex(pvcName, "-")
+	if idx < 0 || !strings.HasSuffix(pvcName[:idx], "-"+stsName) {
+		return 0, false
+	}
+	ordinal, err := strconv.Atoi(pvcName[idx+1:])
+	if err != nil {
+		return 0, false
+	}
+	return ordinal, true
+}
+
+// ImageConversionPVC - returns true if the image conversion work dir is
+// realized through a per-replica PVC rather than an ephemeral volume
+func ImageConversionPVC(instance *glancev1.GlanceAPI, imageConv bool) bool {
+	return imageConv && instance.Spec.ImageConversion != nil &&
+		!instance.Spec.ImageConversion.Ephemeral
+}
+
+// ImageCachePVC - returns true if the image cache is realized through a
+// per-replica PVC, that can be shared with the cleaner and pruner CronJobs
+func ImageCachePVC(instance *glancev1.GlanceAPI) bool {
+	return len(instance.Spec.ImageCache.Size) > 0 &&
+		instance.Spec.ImageCache.VolumeType.IsPVC()
+}
+
+// StagingPVC - returns true if the staging area is realized through a
+// per-replica PVC
+func StagingPVC(instance *glancev1.GlanceAPI) bool {
+	return instance.Spec.Staging != nil && !instance.Spec.Staging.Shared &&
+		instance.Spec.Staging.VolumeType.IsPVC()
+}
+
+// SharedStaging - returns true if a single ReadWriteMany PVC is used as
+// staging area by all the replicas
+func SharedStaging(instance *glancev1.GlanceAPI) bool {
+	return instance.Spec.Staging != nil && instance.Spec.Staging.Shared
+}
+
+// SharedStagingPVCName - returns the name of the shared staging PVC
+func SharedStagingPVCName(instance *glancev1.GlanceAPI) string {
+	return fmt.Sprintf("%s-%s", instance.Name, glance.StagingVolume)
+}
+
+// PVCRetentionPolicy - returns the persistentVolumeClaimRetentionPolicy of
+// the StatefulSet. The policy applies to all the VolumeClaimTemplates, so a
+// field is set to Delete only when every PVC kind requests it: PVCs with a
+// more aggressive policy are removed by the controller
+func PVCRetentionPolicy(instance *glancev1.GlanceAPI) *appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy {
+	policies := []glancev1.PVCRetentionPolicy{}
+	if !instance.Spec.Storage.External {
+		policies = append(policies, instance.Spec.Storage.GetRetentionPolicy())
+	}
+	if ImageCachePVC(instance) {
+		policies = append(policies, instance.Spec.ImageCache.GetRetentionPolicy())
+	}
+	if c := instance.Spec.ImageConversion; c != nil && !c.Ephemeral {
+		policies = append(policies, c.GetRetentionPolicy())
+	}
+	if StagingPVC(instance) {
+		policies = append(policies, instance.Spec.Staging.GetRetentionPolicy())
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+	policy := &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+		WhenScaled:  appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+		WhenDeleted: appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+	}
+	for _, p := range policies {
+		if p.WhenScaled == appsv1.RetainPersistentVolumeClaimRetentionPolicyType {
+			policy.WhenScaled = appsv1.RetainPersistentVolumeClaimRetentionPolicyType
+		}
+		if p.WhenDeleted == appsv1.RetainPersistentVolumeClaimRetentionPolicyType {
+			policy.WhenDeleted = appsv1.RetainPersistentVolumeClaimRetentionPolicyType
+		}
+	}
+	return policy
+}
+
 // privilegedAwareSecurityContext returns the SecurityContext for the httpd
 // and glance-api containers. When Cinder is configured as a backend, host
 // device access via nsenter'd multipath/iscsi tooling requires Privileged,
@@ -76,6 +217,8 @@ func StatefulSet(
 	topology *topologyv1.Topology,
 	wsgi bool,
 	memcached *memcachedv1.Memcached,
+	proxyEnvVars map[string]env.Setter,
+	imageConv bool,
 ) (*appsv1.StatefulSet, error) {
 	//
 	// https://kubernetes.io/docs/tasks/configure-pod-container/configure-liveness-readiness-startup-probes/
@@ -109,9 +252,24 @@ func StatefulSet(
 	// envVars
 	envVars := map[string]env.Setter{}
 	envVars["CONFIG_HASH"] = env.SetValue(configHash)
-	envVars["GLANCE_DOMAIN"] = env.SetValue(instance.Status.Domain)
+	// With a shared staging area any replica can complete an import staged
+	// by another one, so worker_self_reference_url is not rendered
+	glanceDomain := instance.Status.Domain
+	if SharedStaging(instance) {
+		glanceDomain = ""
+	}
+	envVars["GLANCE_DOMAIN"] = env.SetValue(glanceDomain)
 	envVars["URISCHEME"] = env.SetValue(string(glanceURIScheme))
 	envVars["GLANCE_PORT"] = env.SetValue(fmt.Sprintf("%d", port))
+	// The outbound proxy (if any) is only relevant for the httpd and api
+	// containers
+	apiEnvVars := maps.Clone(envVars)
+	maps.Copy(apiEnvVars, proxyEnvVars)
+	// The local KeyManager and the signature filter are loaded from the
+	// scripts volume
+	if instance.Spec.KeyManager.IsLocal() || instance.Spec.KeyManager.SignatureRequired() {
+		apiEnvVars["PYTHONPATH"] = env.SetValue(glance.ScriptsDir)
+	}
 
 	// basic volume/volumeMounts
 	apiVolumes := glance.GetAPIVolumes()
@@ -163,23 +321,134 @@ func StatefulSet(
 		}
 	}
 
-	stsName := instance.Name
-	// The StatefulSet name **must** match with the headless service
+	// The certificates served by the local KeyManager
+	if instance.Spec.KeyManager.IsLocal() {
+		apiVolumes = append(apiVolumes, glance.GetSigningCertificatesVolume(instance.Spec.KeyManager.LocalKeySecret)...)
+		apiVolumeMounts = append(apiVolumeMounts, glance.GetSigningCertificatesVolumeMount()...)
+	}
+
+	// The image conversion work dir is only mounted when an rbd backend
+	// enables the image_conversion import plugin
+	if imageConv && instance.Spec.ImageConversion != nil {
+		apiVolumeMounts = append(apiVolumeMounts, glance.GetImageConversionVolumeMount()...)
+		if !ImageConversionPVC(instance, imageConv) {
+			convVolume, err := glance.GetImageConversionVolume(instance)
+			if err != nil {
+				return nil, err
+			}
+			apiVolumes = append(apiVolumes, convVolume...)
+		}
+	}
+
+	// A shared staging area is a single PVC managed by the controller, while
+	// a per-replica one is either added to the VolumeClaimTemplates or lives
+	// and dies with the Pod
+	if instance.Spec.Staging != nil {
+		apiVolumeMounts = append(apiVolumeMounts, glance.GetStagingVolumeMount()...)
+		switch {
+		case SharedStaging(instance):
+			apiVolumes = append(apiVolumes, glance.GetStagingVolume(SharedStagingPVCName(instance))...)
+		case !StagingPVC(instance):
+			stagingVolume, err := glance.GetEphemeralVolume(instance, glance.StagingVolume,
+				glance.PvcStaging, instance.Spec.Staging.VolumeType)
+			if err != nil {
+				return nil, err
+			}
+			apiVolumes = append(apiVolumes, stagingVolume...)
+		}
+	}
+
+	// The image cache is mounted by GetAPIVolumeMount: when it is not a
+	// per-replica PVC the Volume is part of the Pod
+	if len(instance.Spec.ImageCache.Size) > 0 && !ImageCachePVC(instance) {
+		cacheVolume, err := glance.GetEphemeralVolume(instance, glance.CacheVolume,
+			glance.PvcCache, instance.Spec.ImageCache.VolumeType)
+		if err != nil {
+			return nil, err
+		}
+		apiVolumes = append(apiVolumes, cacheVolume...)
+	}
+
+	// The StatefulSet serviceName **must** match with the headless service
 	// endpoint Name (see GetHeadlessService() function under controllers/
-	// glance_common)
-	if instance.Spec.APIType != glancev1.APISingle {
-		stsName = fmt.Sprintf("%s-api", instance.Name)
+	// glance_common), which is shared by all the StatefulSet revisions so
+	// that Status.Domain stays valid during a backend change
+	headlessName := StatefulSetName(instance, 0)
+	stsName := StatefulSetName(instance, instance.Status.StatefulSetRevision)
+	// The Pods of every revision carry the RevisionLabel. The selector and
+	// the VolumeClaimTemplates of revision 0 are immutable and predate the
+	// label, so they keep the plain service labels
+	podLabels := RevisionLabels(labels, instance.Status.StatefulSetRevision)
+	if instance.Status.StatefulSetRevision > 0 {
+		labels = podLabels
 	}
 
-	LogFile := string(glance.GlanceLogPath + instance.Name + ".log")
+	// The glance-log sidecar only streams the log file: it is not deployed
+	// when the GlanceAPI logs directly to the container output
+	containers := []corev1.Container{}
+	if instance.Spec.LogToFile() {
+		LogFile := string(glance.GlanceLogPath + instance.Name + ".log")
+		containers = append(containers, corev1.Container{
+			Name: glance.ServiceName + "-log",
+			Command: []string{
+				"/usr/bin/dumb-init",
+			},
+			Args: []string{
+				"--single-child",
+				"--",
+				"/bin/sh",
+				"-c",
+				"/usr/bin/tail -n+1 -F " + LogFile + " 2>/dev/null",
+			},
+			Image:           instance.Spec.ContainerImage,
+			SecurityContext: pod.RestrictiveSecurityContext(users.GlanceUID, users.GlanceGID),
+			Env:             env.MergeEnvs([]corev1.EnvVar{}, envVars),
+			VolumeMounts:    []corev1.VolumeMount{volume.WritableDirVolumeMount(glance.LogVolume, "/var/log/glance")},
+			Resources:       instance.Spec.GetLogResources(),
+		})
+	}
+	containers = append(containers, corev1.Container{
+		Name: glance.ServiceName + "-httpd",
+		Command: []string{
+			"/usr/bin/dumb-init",
+		},
+		Args: []string{
+			"--single-child",
+			"--",
+			"/bin/bash",
+			"-c",
+			workerSelfReferenceScript + "exec /usr/sbin/httpd -DFOREGROUND",
+		},
+		Image:           instance.Spec.ContainerImage,
+		SecurityContext: privilegedAwareSecurityContext(privileged),
+		Env:             env.MergeEnvs([]corev1.EnvVar{}, apiEnvVars),
+		VolumeMounts: append(glance.GetVolumeMounts(
+			instance.Spec.CustomServiceConfigSecrets,
+			privileged,
+			instance.Spec.Storage.External,
+			instance.Spec.ExtraMounts,
+			extraVolPropagation,
+			"httpd",
+			wsgi,
+			instance.Spec.PropertyProtections,
+			instance.Spec.Audit != nil,
+			instance.Spec.KeyManager.SignatureRequired(),
+		),
+			apiVolumeMounts...,
+		),
+		Resources:      instance.Spec.GetHTTPDResources(),
+		ReadinessProbe: probes.Readiness,
+		LivenessProbe:  probes.Liveness,
+	})
+
 	statefulset := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      stsName,
 			Namespace: instance.Namespace,
-			Labels:    labels,
+			Labels:    podLabels,
 		},
 		Spec: appsv1.StatefulSetSpec{
-			ServiceName: stsName,
+			ServiceName: headlessName,
 			Selector: &metav1.LabelSelector{
 				MatchLabels: labels,
 			},
@@ -188,7 +457,7 @@ func StatefulSet(
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Annotations: annotations,
-					Labels:      labels,
+					Labels:      podLabels,
 				},
 				Spec: corev1.PodSpec{
 					SecurityContext:              pod.RestrictivePodSecurityContext(users.GlanceUID, users.GlanceGID),
@@ -198,57 +467,8 @@ func StatefulSet(
 					// commands need to be run on the host using nsenter (eg:
 					// iscsi commands) so we need to share the PID namespace
 					// with the host.
-					HostPID: privileged,
-					Containers: []corev1.Container{
-						{
-							Name: glance.ServiceName + "-log",
-							Command: []string{
-								"/usr/bin/dumb-init",
-							},
-							Args: []string{
-								"--single-child",
-								"--",
-								"/bin/sh",
-								"-c",
-								"/usr/bin/tail -n+1 -F " + LogFile + " 2>/dev/null",
-							},
-							Image:           instance.Spec.ContainerImage,
-							SecurityContext: pod.RestrictiveSecurityContext(users.GlanceUID, users.GlanceGID),
-							Env:             env.MergeEnvs([]corev1.EnvVar{}, envVars),
-							VolumeMounts:    []corev1.VolumeMount{volume.WritableDirVolumeMount(glance.LogVolume, "/var/log/glance")},
-							Resources:       instance.Spec.Resources,
-						},
-						{
-							Name: glance.ServiceName + "-httpd",
-							Command: []string{
-								"/usr/bin/dumb-init",
-							},
-							Args: []string{
-								"--single-child",
-								"--",
-								"/bin/bash",
-								"-c",
-								workerSelfReferenceScript + "exec /usr/sbin/httpd -DFOREGROUND",
-							},
-							Image:           instance.Spec.ContainerImage,
-							SecurityContext: privilegedAwareSecurityContext(privileged),
-							Env:             env.MergeEnvs([]corev1.EnvVar{}, envVars),
-							VolumeMounts: append(glance.GetVolumeMounts(
-								instance.Spec.CustomServiceConfigSecrets,
-								privileged,
-								instance.Spec.Storage.External,
-								instance.Spec.ExtraMounts,
-								extraVolPropagation,
-								"httpd",
-								wsgi,
-							),
-								apiVolumeMounts...,
-							),
-							Resources:      instance.Spec.Resources,
-							ReadinessProbe: probes.Readiness,
-							LivenessProbe:  probes.Liveness,
-						},
-					},
+					HostPID:    privileged,
+					Containers: containers,
 				},
 			},
 		},
@@ -271,7 +491,7 @@ func StatefulSet(
 				},
 				Image:           instance.Spec.ContainerImage,
 				SecurityContext: privilegedAwareSecurityContext(privileged),
-				Env:             env.MergeEnvs([]corev1.EnvVar{}, envVars),
+				Env:             env.MergeEnvs([]corev1.EnvVar{}, apiEnvVars),
 				VolumeMounts: append(glance.GetVolumeMounts(
 					instance.Spec.CustomServiceConfigSecrets,
 					privileged,
@@ -280,16 +500,27 @@ func StatefulSet(
 					extraVolPropagation,
 					"api",
 					wsgi,
+					instance.Spec.PropertyProtections,
+					instance.Spec.Audit != nil,
+					instance.Spec.KeyManager.SignatureRequired(),
 				),
 					apiVolumeMounts...,
 				),
-				Resources:      instance.Spec.Resources,
+				Resources:      instance.Spec.GetAPIResources(),
 				ReadinessProbe: probes.Readiness,
 				LivenessProbe:  probes.Liveness,
 			},
 		}
 		statefulset.Spec.Template.Spec.Containers = append(statefulset.Spec.Template.Spec.Containers, apiContainer...)
 	}
+	// The metrics sidecars (if any) are appended last so the index of the
+	// glance containers is preserved
+	statefulset.Spec.Template.Spec.Containers = append(statefulset.Spec.Template.Spec.Containers,
+		MetricsContainers(instance)...)
+	// The cache cleaner sidecar (if any) runs the cache maintenance in-pod
+	// when the cache can't be shared with the CronJobs
+	statefulset.Spec.Template.Spec.Containers = append(statefulset.Spec.Template.Spec.Containers,
+		ImageCacheCleanerContainer(instance)...)
 
 	if !instance.Spec.Storage.External {
 		localPvc, err := glance.GetPvc(instance, labels, glance.PvcLocal)
@@ -300,20 +531,36 @@ func StatefulSet(
 	}
 	// Staging and Cache are realized through separate interfaces
 	// (TODO) Allow to externally manage image-cache
-	if len(instance.Spec.ImageCache.Size) > 0 {
+	if ImageCachePVC(instance) {
 		cachePvc, err := glance.GetPvc(instance, labels, glance.PvcCache)
 		if err != nil {
 			return statefulset, err
 		}
 		statefulset.Spec.VolumeClaimTemplates = append(statefulset.Spec.VolumeClaimTemplates, cachePvc)
 	}
+	if ImageConversionPVC(instance, imageConv) {
+		convPvc, err := glance.GetPvc(instance, labels, glance.PvcImageConv)
+		if err != nil {
+			return statefulset, err
+		}
+		statefulset.Spec.VolumeClaimTemplates = append(statefulset.Spec.VolumeClaimTemplates, convPvc)
+	}
+	if StagingPVC(instance) {
+		stagingPvc, err := glance.GetPvc(instance, labels, glance.PvcStaging)
+		if err != nil {
+			return statefulset, err
+		}
+		statefulset.Spec.VolumeClaimTemplates = append(statefulset.Spec.VolumeClaimTemplates, stagingPvc)
+	}
+	statefulset.Spec.PersistentVolumeClaimRetentionPolicy = PVCRetentionPolicy(instance)
 
 	statefulset.Spec.Template.Spec.Volumes = append(glance.GetVolumes(
 		instance.Name,
 		privileged,
 		instance.Spec.CustomServiceConfigSecrets,
 		instance.Spec.ExtraMounts,
-		extraVolPropagation),
+		extraVolPropagation,
+		instance.Spec.PropertyProtections),
 		apiVolumes...)
 
 	if instance.Spec.NodeSelector != nil {
@@ -0,0 +1,52 @@
+#
+# Licensed under the Apache License, Version 2.0 (the "License"); you may
+# not use this file except in compliance with the License. You may obtain
+# a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+# WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+# License for the specific language governing permissions and limitations
+# under the License.
+#
+# Helpers shared by the scripts run in the Jobs and CronJobs of the operator
+# (glance-migrate, glance-upload, glance-image-cleanup and
+# glance-consistency-check). They are imported from the scripts volume, the
+# directory of the running script.
+import configparser
+import json
+import os
+
+CONFIG = os.environ.get("GLANCE_CONFIG", "/etc/glance/glance.conf.d/00-config.conf")
+TERMINATION_LOG = os.environ.get("TERMINATION_LOG", "/dev/termination-log")
+
+
+def get_session():
+    """Return a keystone session built from the [keystone_authtoken] section
+    of the GlanceAPI config"""
+    # imported lazily: not every script talks to the Glance API
+    from keystoneauth1 import loading
+    from keystoneauth1 import session
+
+    cfg = configparser.ConfigParser(interpolation=None)
+    cfg.read(CONFIG)
+    auth_cfg = dict(cfg.items("keystone_authtoken"))
+    loader = loading.get_plugin_loader(auth_cfg.get("auth_type", "password"))
+    opts = dict()
+    for opt in loader.get_options():
+        if opt.dest in auth_cfg:
+            opts[opt.dest] = auth_cfg[opt.dest]
+    return session.Session(auth=loader.load_from_options(**opts))
+
+
+def write_summary(summary):
+    """Print the summary and write it to the termination log, where the
+    operator reads it"""
+    print(json.dumps(summary))
+    try:
+        with open(TERMINATION_LOG, "w") as f:
+            json.dump(summary, f)
+    except OSError as e:
+        print("Unable to write the termination log: %s" % e)
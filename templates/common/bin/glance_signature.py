@@ -0,0 +1,123 @@
+#
+# Licensed under the Apache License, Version 2.0 (the "License"); you may
+# not use this file except in compliance with the License. You may obtain
+# a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+# WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+# License for the specific language governing permissions and limitations
+# under the License.
+#
+# Image signature extensions loaded by the GlanceAPI from the scripts volume:
+# - LocalCertificateManager is the castellan key manager of the "local"
+#   KeyManager backend. It serves the signing certificates of the
+#   localKeySecret, mounted in CERTIFICATES_DIR, one file per
+#   img_signature_certificate_uuid
+# - SignatureRequiredFilter is the paste filter enabled by
+#   requireSignatureVerification. It rejects the image data (upload, stage
+#   and import) of the images that don't carry the signature properties, so
+#   that Glance verifies every image before it becomes active
+import os
+import re
+
+from castellan.common import exception
+from castellan.common.objects import x_509
+from castellan.key_manager import not_implemented_key_manager
+from cryptography import x509
+from cryptography.hazmat.primitives import serialization
+import webob
+import webob.exc
+
+CERTIFICATES_DIR = "/etc/glance/signing-certificates"
+SIGNATURE_PROPERTIES = (
+    "img_signature",
+    "img_signature_certificate_uuid",
+    "img_signature_hash_method",
+    "img_signature_key_type",
+)
+IMAGE_DATA_PATH = re.compile(
+    r"^/v2(?:\.\d+)?/images/(?P<image_id>[^/]+)/(?P<action>file|stage|import)/?$")
+# import methods that don't bring new image data
+DATA_LESS_IMPORT_METHODS = ("copy-image",)
+
+
+class LocalCertificateManager(not_implemented_key_manager.NotImplementedKeyManager):
+    """Read-only key manager serving the certificates of CERTIFICATES_DIR"""
+
+    def __init__(self, configuration=None):
+        super().__init__(configuration)
+
+    def get(self, context, managed_object_id, **kwargs):
+        # the certificate id comes from the image properties
+        name = os.path.basename(managed_object_id)
+        try:
+            with open(os.path.join(CERTIFICATES_DIR, name), "rb") as f:
+                data = f.read()
+        except OSError:
+            raise exception.ManagedObjectNotFoundError(uuid=managed_object_id)
+        # castellan X509 objects hold DER encoded certificates
+        if b"-----BEGIN" in data:
+            data = x509.load_pem_x509_certificate(data).public_bytes(
+                serialization.Encoding.DER)
+        return x_509.X509(data, id=managed_object_id)
+
+    def list(self, context, object_type=None, metadata_only=False):
+        return [self.get(context, name) for name in sorted(os.listdir(CERTIFICATES_DIR))
+                if not name.startswith(".")]
+
+
+class SignatureRequiredFilter(object):
+    """Reject the image data of the images without signature properties"""
+
+    def __init__(self, app):
+        self.app = app
+
+    @classmethod
+    def factory(cls, global_conf, **local_conf):
+        def _filter(app):
+            return cls(app)
+        return _filter
+
+    def __call__(self, environ, start_response):
+        req = webob.Request(environ)
+        match = IMAGE_DATA_PATH.match(req.path_info)
+        if match is None or req.method not in ("PUT", "POST"):
+            return self.app(environ, start_response)
+        if match.group("action") == "import" and self._import_method(req) in DATA_LESS_IMPORT_METHODS:
+            return self.app(environ, start_response)
+        # the request context is set by the glance context filter
+        context = getattr(req, "context", None)
+        if context is None:
+            return self.app(environ, start_response)
+        properties = self._image_properties(context, match.group("image_id"))
+        # a missing or forbidden image is reported by the Image API
+        if properties is None:
+            return self.app(environ, start_response)
+        missing = [p for p in SIGNATURE_PROPERTIES if not properties.get(p)]
+        if missing:
+            return webob.exc.HTTPBadRequest(
+                explanation="Image signature verification is required: the "
+                            "image is missing the %s properties" % ", ".join(missing)
+            )(environ, start_response)
+        return self.app(environ, start_response)
+
+    @staticmethod
+    def _import_method(req):
+        try:
+            return req.json_body.get("method", {}).get("name")
+        except (ValueError, AttributeError):
+            return None
+
+    @staticmethod
+    def _image_properties(context, image_id):
+        # imported lazily: the module is loaded before the glance config
+        from glance.common import exception as glance_exception
+        from glance import gateway
+        try:
+            image = gateway.Gateway().get_repo(context).get(image_id)
+        except (glance_exception.NotFound, glance_exception.Forbidden):
+            return None
+        return image.extra_properties
@@ -68,6 +68,14 @@ func GlanceAPIConditionGetter(name types.NamespacedName) condition.Conditions {
 	return instance.Status.Conditions
 }
 
+func GlanceImageMigrationConditionGetter(name types.NamespacedName) condition.Conditions {
+	instance := &glancev1.GlanceImageMigration{}
+	Eventually(func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, name, instance)).Should(Succeed())
+	}, timeout, interval).Should(Succeed())
+	return instance.Status.Conditions
+}
+
 func CreateDefaultGlance(name types.NamespacedName) client.Object {
 	raw := map[string]any{
 		"apiVersion": "glance.openstack.org/v1beta1",
@@ -0,0 +1,185 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package functional
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/gophercloud/gophercloud/v2"
+	glancev1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// FakeImageImport - an import request received by the FakeImageAPI
+type FakeImageImport struct {
+	Method string
+	URI    string
+	Stores []string
+}
+
+// FakeImageAPI - minimal image API v2 serving the GlanceImage controller in
+// the functional tests. An import completes immediately
+type FakeImageAPI struct {
+	mu      sync.Mutex
+	server  *httptest.Server
+	images  map[string]map[string]any
+	imports map[string]FakeImageImport
+}
+
+// NewFakeImageAPI - starts a FakeImageAPI
+func NewFakeImageAPI() *FakeImageAPI {
+	f := &FakeImageAPI{
+		images:  map[string]map[string]any{},
+		imports: map[string]FakeImageImport{},
+	}
+	f.server = httptest.NewServer(f)
+	return f
+}
+
+// Close - stops the server
+func (f *FakeImageAPI) Close() {
+	f.server.Close()
+}
+
+// ImageClient - a controller.ImageClientFunc returning a client of the fake
+// API
+func (f *FakeImageAPI) ImageClient(
+	_ context.Context,
+	_ *helper.Helper,
+	_ *glancev1.GlanceAPI,
+) (*gophercloud.ServiceClient, ctrl.Result, error) {
+	return &gophercloud.ServiceClient{
+		ProviderClient: &gophercloud.ProviderClient{},
+		Endpoint:       f.server.URL + "/",
+		ResourceBase:   f.server.URL + "/v2/",
+	}, ctrl.Result{}, nil
+}
+
+// AddImage - adds an existing image to the API, returning its ID
+func (f *FakeImageAPI) AddImage(image map[string]any) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := uuid.NewString()
+	image["id"] = id
+	f.images[id] = image
+	return id
+}
+
+// GetImage - returns a copy of an image, nil if it does not exist
+func (f *FakeImageAPI) GetImage(id string) map[string]any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	image, ok := f.images[id]
+	if !ok {
+		return nil
+	}
+	out := map[string]any{}
+	for k, v := range image {
+		out[k] = v
+	}
+	return out
+}
+
+// GetImport - returns the import request received for an image
+func (f *FakeImageAPI) GetImport(id string) (FakeImageImport, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	i, ok := f.imports[id]
+	return i, ok
+}
+
+func (f *FakeImageAPI) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(req.URL.Path, "/v2/images"), "/"), "/")
+	id := parts[0]
+	switch {
+	case id == "" && req.Method == http.MethodGet:
+		tag := req.URL.Query().Get("tag")
+		found := []map[string]any{}
+		for _, image := range f.images {
+			if tags, _ := image["tags"].([]any); tag == "" || slices.Contains(tags, any(tag)) {
+				found = append(found, image)
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"images": found})
+	case id == "" && req.Method == http.MethodPost:
+		image := map[string]any{}
+		if err := json.NewDecoder(req.Body).Decode(&image); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		image["id"] = uuid.NewString()
+		image["status"] = "queued"
+		f.images[image["id"].(string)] = image
+		writeJSON(w, http.StatusCreated, image)
+	case f.images[id] == nil:
+		w.WriteHeader(http.StatusNotFound)
+	case len(parts) == 2 && parts[1] == "import" && req.Method == http.MethodPost:
+		body := struct {
+			Method struct {
+				Name string `json:"name"`
+				URI  string `json:"uri"`
+			} `json:"method"`
+			Stores []string `json:"stores"`
+		}{}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.imports[id] = FakeImageImport{Method: body.Method.Name, URI: body.Method.URI, Stores: body.Stores}
+		stores := body.Stores
+		if len(stores) == 0 {
+			stores = []string{"default_backend"}
+		}
+		f.images[id]["status"] = "active"
+		f.images[id]["stores"] = strings.Join(stores, ",")
+		w.WriteHeader(http.StatusAccepted)
+	case len(parts) == 1 && req.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, f.images[id])
+	case len(parts) == 1 && req.Method == http.MethodPatch:
+		ops := []map[string]any{}
+		if err := json.NewDecoder(req.Body).Decode(&ops); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for _, op := range ops {
+			f.images[id][strings.TrimPrefix(op["path"].(string), "/")] = op["value"]
+		}
+		writeJSON(w, http.StatusOK, f.images[id])
+	case len(parts) == 1 && req.Method == http.MethodDelete:
+		delete(f.images, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
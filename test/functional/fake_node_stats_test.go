@@ -0,0 +1,105 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package functional
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type fakeVolumeStats struct {
+	Name           string `json:"name"`
+	CapacityBytes  uint64 `json:"capacityBytes"`
+	UsedBytes      uint64 `json:"usedBytes"`
+	AvailableBytes uint64 `json:"availableBytes"`
+}
+
+type fakePodStats struct {
+	PodRef struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"podRef"`
+	Volumes []fakeVolumeStats `json:"volume"`
+}
+
+// FakeNodeStats - serves the kubelet stats summary of the nodes to the
+// GlanceAPI controller in the functional tests, envtest has no kubelet
+type FakeNodeStats struct {
+	mu sync.Mutex
+	// node name -> Pod -> volume name -> stats
+	volumes map[string]map[types.NamespacedName]map[string]fakeVolumeStats
+}
+
+// NewFakeNodeStats - returns an empty FakeNodeStats
+func NewFakeNodeStats() *FakeNodeStats {
+	return &FakeNodeStats{
+		volumes: map[string]map[types.NamespacedName]map[string]fakeVolumeStats{},
+	}
+}
+
+// SetVolumeUsage - sets the usage of a volume of the Pod running on node
+func (f *FakeNodeStats) SetVolumeUsage(
+	node string,
+	pod types.NamespacedName,
+	volume string,
+	capacity uint64,
+	used uint64,
+) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.volumes[node] == nil {
+		f.volumes[node] = map[types.NamespacedName]map[string]fakeVolumeStats{}
+	}
+	if f.volumes[node][pod] == nil {
+		f.volumes[node][pod] = map[string]fakeVolumeStats{}
+	}
+	f.volumes[node][pod][volume] = fakeVolumeStats{
+		Name:           volume,
+		CapacityBytes:  capacity,
+		UsedBytes:      used,
+		AvailableBytes: capacity - used,
+	}
+}
+
+// Reset - removes the usage of all the nodes
+func (f *FakeNodeStats) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.volumes = map[string]map[types.NamespacedName]map[string]fakeVolumeStats{}
+}
+
+// NodeStats - a controller.NodeStatsFunc returning the summary of node
+func (f *FakeNodeStats) NodeStats(_ context.Context, node string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	summary := struct {
+		Pods []fakePodStats `json:"pods"`
+	}{Pods: []fakePodStats{}}
+	for pod, volumes := range f.volumes[node] {
+		stats := fakePodStats{}
+		stats.PodRef.Name = pod.Name
+		stats.PodRef.Namespace = pod.Namespace
+		for _, v := range volumes {
+			stats.Volumes = append(stats.Volumes, v)
+		}
+		summary.Pods = append(summary.Pods, stats)
+	}
+	return json.Marshal(summary)
+}
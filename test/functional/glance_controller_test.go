@@ -34,6 +34,7 @@ import (
 	util "github.com/openstack-k8s-operators/lib-common/modules/common/util"
 	mariadb_test "github.com/openstack-k8s-operators/mariadb-operator/api/test/helpers"
 	mariadbv1 "github.com/openstack-k8s-operators/mariadb-operator/api/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -245,6 +246,419 @@ var _ = Describe("Glance controller", func() {
 			)
 		})
 	})
+	When("Glance is created with custom metadefs", func() {
+		var metadefsName types.NamespacedName
+		BeforeEach(func() {
+			metadefsName = types.NamespacedName{Namespace: namespace, Name: "glance-metadefs-custom"}
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      metadefsName.Name,
+					Namespace: metadefsName.Namespace,
+				},
+				Data: map[string]string{
+					"custom-hints.json": `{"namespace": "CUSTOM::Hints", "display_name": "Custom Hints"}`,
+				},
+			}
+			Expect(k8sClient.Create(ctx, cm)).Should(Succeed())
+			DeferCleanup(k8sClient.Delete, ctx, cm)
+
+			DeferCleanup(k8sClient.Delete, ctx, CreateGlanceMessageBusSecret(glanceTest.Instance.Namespace, glanceTest.RabbitmqSecretName))
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			spec := GetGlanceDefaultSpec()
+			spec["metadefs"] = []string{metadefsName.Name}
+			DeferCleanup(th.DeleteInstance, CreateGlance(glanceTest.Instance, spec, annotations))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			infra.SimulateTransportURLReady(glanceTest.GlanceTransportURL)
+			mariadb.SimulateMariaDBDatabaseCompleted(glanceTest.GlanceDatabaseName)
+			mariadb.SimulateMariaDBAccountCompleted(glanceTest.GlanceDatabaseAccount)
+			th.SimulateJobSuccess(glanceTest.GlanceDBSync)
+			keystoneAPI := keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace)
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystoneAPI)
+			keystone.SimulateKeystoneServiceReady(glanceTest.KeystoneService)
+		})
+		It("loads the metadefs after the db-sync", func() {
+			th.SimulateJobSuccess(glanceTest.GlanceMetadefs)
+			th.ExpectCondition(
+				glanceTest.Instance,
+				ConditionGetterFunc(GlanceConditionGetter),
+				glancev1.MetadefsReadyCondition,
+				corev1.ConditionTrue,
+			)
+			Expect(GetGlance(glanceTest.Instance).Status.Hash).To(HaveKey(glancev1.MetadefsHash))
+
+			j := th.GetJob(glanceTest.GlanceMetadefs)
+			Expect(j.Spec.Template.Spec.Containers[0].Args[1]).To(ContainSubstring("load_metadefs"))
+			Expect(j.Spec.Template.Spec.Volumes).To(ContainElement(HaveField("Name", "metadefs")))
+		})
+		It("loads the metadefs again when the ConfigMap changes", func() {
+			th.SimulateJobSuccess(glanceTest.GlanceMetadefs)
+			th.ExpectCondition(
+				glanceTest.Instance,
+				ConditionGetterFunc(GlanceConditionGetter),
+				glancev1.MetadefsReadyCondition,
+				corev1.ConditionTrue,
+			)
+			loadedHash := GetGlance(glanceTest.Instance).Status.Hash[glancev1.MetadefsHash]
+
+			Eventually(func(g Gomega) {
+				cm := &corev1.ConfigMap{}
+				g.Expect(k8sClient.Get(ctx, metadefsName, cm)).Should(Succeed())
+				cm.Data["custom-hints.json"] = `{"namespace": "CUSTOM::Hints", "display_name": "Updated Hints"}`
+				g.Expect(k8sClient.Update(ctx, cm)).Should(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			th.SimulateJobSuccess(glanceTest.GlanceMetadefs)
+			Eventually(func(g Gomega) {
+				g.Expect(GetGlance(glanceTest.Instance).Status.Hash[glancev1.MetadefsHash]).ToNot(Equal(loadedHash))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("A GlanceBackup and a GlanceRestore are created for a Glance", func() {
+		var backupName types.NamespacedName
+		var restoreName types.NamespacedName
+		BeforeEach(func() {
+			backupName = types.NamespacedName{Namespace: namespace, Name: "glance-backup"}
+			restoreName = types.NamespacedName{Namespace: namespace, Name: "glance-restore"}
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceName))
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, th.CreateUnstructured(map[string]any{
+				"apiVersion": "glance.openstack.org/v1beta1",
+				"kind":       "GlanceBackup",
+				"metadata": map[string]any{
+					"name":      backupName.Name,
+					"namespace": backupName.Namespace,
+				},
+				"spec": map[string]any{
+					"glanceName": glanceName.Name,
+				},
+			}))
+		})
+		It("dumps the database and completes the backup", func() {
+			dbPVC := types.NamespacedName{Namespace: namespace, Name: backupName.Name + "-db"}
+			AssertPVCExist(dbPVC)
+			dbJob := types.NamespacedName{Namespace: namespace, Name: backupName.Name + "-db-backup"}
+			j := th.GetJob(dbJob)
+			Expect(j.Spec.Template.Spec.Containers[0].Args[1]).To(Equal(glance.DatabaseBackupCommand))
+			Expect(j.Spec.Template.Spec.Volumes).To(ContainElement(HaveField("Name", "backup")))
+
+			th.SimulateJobSuccess(dbJob)
+			Eventually(func(g Gomega) {
+				backup := &glancev1.GlanceBackup{}
+				g.Expect(k8sClient.Get(ctx, backupName, backup)).Should(Succeed())
+				g.Expect(backup.IsReady()).To(BeTrue())
+				g.Expect(backup.Status.CompletionTime).ToNot(BeNil())
+				g.Expect(backup.Status.DatabaseBackupPVC).To(Equal(dbPVC.Name))
+				// envtest does not run the StatefulSets, there are no image
+				// PVCs to snapshot
+				g.Expect(backup.Status.Volumes).To(BeEmpty())
+			}, timeout, interval).Should(Succeed())
+		})
+		It("restores a completed backup", func() {
+			th.SimulateJobSuccess(types.NamespacedName{Namespace: namespace, Name: backupName.Name + "-db-backup"})
+			DeferCleanup(th.DeleteInstance, th.CreateUnstructured(map[string]any{
+				"apiVersion": "glance.openstack.org/v1beta1",
+				"kind":       "GlanceRestore",
+				"metadata": map[string]any{
+					"name":      restoreName.Name,
+					"namespace": restoreName.Namespace,
+				},
+				"spec": map[string]any{
+					"backupName":      backupName.Name,
+					"restoreDatabase": false,
+				},
+			}))
+			Eventually(func(g Gomega) {
+				restore := &glancev1.GlanceRestore{}
+				g.Expect(k8sClient.Get(ctx, restoreName, restore)).Should(Succeed())
+				g.Expect(restore.IsReady()).To(BeTrue())
+				g.Expect(restore.Status.GlanceName).To(Equal(glanceName.Name))
+				g.Expect(restore.Status.Conditions.Has(glancev1.DatabaseRestoreReadyCondition)).To(BeFalse())
+			}, timeout, interval).Should(Succeed())
+		})
+		It("holds the GlanceAPIs until the database is restored", func() {
+			th.SimulateJobSuccess(types.NamespacedName{Namespace: namespace, Name: backupName.Name + "-db-backup"})
+			DeferCleanup(th.DeleteInstance, th.CreateUnstructured(map[string]any{
+				"apiVersion": "glance.openstack.org/v1beta1",
+				"kind":       "GlanceRestore",
+				"metadata": map[string]any{
+					"name":      restoreName.Name,
+					"namespace": restoreName.Namespace,
+				},
+				"spec": map[string]any{
+					"backupName": backupName.Name,
+				},
+			}))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceName).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.SimulateMariaDBDatabaseCompleted(glanceTest.GlanceDatabaseName)
+			mariadb.SimulateMariaDBAccountCompleted(glanceTest.GlanceDatabaseAccount)
+			th.SimulateJobSuccess(glanceTest.GlanceDBSync)
+			keystoneAPI := keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace)
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystoneAPI)
+			keystone.SimulateKeystoneServiceReady(glanceTest.KeystoneService)
+
+			// the dump is loaded once db-sync has run, before the GlanceAPIs
+			// are deployed
+			restoreJob := types.NamespacedName{Namespace: namespace, Name: restoreName.Name + "-db-restore"}
+			th.GetJob(restoreJob)
+			th.ExpectConditionWithDetails(
+				glanceTest.GlanceSingle,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				condition.DeploymentReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				fmt.Sprintf(glancev1.GlanceAPIRestoreWaitingMessage, restoreName.Name),
+			)
+
+			th.SimulateJobSuccess(restoreJob)
+			Eventually(func(g Gomega) {
+				restore := &glancev1.GlanceRestore{}
+				g.Expect(k8sClient.Get(ctx, restoreName, restore)).Should(Succeed())
+				g.Expect(restore.IsReady()).To(BeTrue())
+				g.Expect(restore.Status.Conditions.IsTrue(glancev1.DatabaseRestoreReadyCondition)).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				c := GlanceAPIConditionGetter(glanceTest.GlanceSingle).Get(condition.DeploymentReadyCondition)
+				g.Expect(c).ToNot(BeNil())
+				g.Expect(c.Message).ToNot(Equal(fmt.Sprintf(glancev1.GlanceAPIRestoreWaitingMessage, restoreName.Name)))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("A GlanceImageMigration is created", func() {
+		var migrationName types.NamespacedName
+		createMigration := func(source string, target string) {
+			DeferCleanup(th.DeleteInstance, th.CreateUnstructured(map[string]any{
+				"apiVersion": "glance.openstack.org/v1beta1",
+				"kind":       "GlanceImageMigration",
+				"metadata": map[string]any{
+					"name":      migrationName.Name,
+					"namespace": migrationName.Namespace,
+				},
+				"spec": map[string]any{
+					"glanceName":  glanceName.Name,
+					"sourceStore": source,
+					"targetStore": target,
+				},
+			}))
+		}
+		BeforeEach(func() {
+			migrationName = types.NamespacedName{Namespace: namespace, Name: "glance-migration"}
+		})
+		It("refuses to migrate the images to the source store", func() {
+			createMigration("default_backend", "default_backend")
+			th.ExpectConditionWithDetails(
+				migrationName,
+				ConditionGetterFunc(GlanceImageMigrationConditionGetter),
+				condition.InputReadyCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				glancev1.ImageMigrationSameStoreErrorMessage,
+			)
+		})
+		It("waits for the Glance to be ready", func() {
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceName))
+			createMigration("default_backend", "ceph")
+			th.ExpectConditionWithDetails(
+				migrationName,
+				ConditionGetterFunc(GlanceImageMigrationConditionGetter),
+				condition.InputReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				fmt.Sprintf(glancev1.ImageMigrationWaitingMessage, glanceName.Name),
+			)
+			Expect(th.K8sClient.Get(ctx, types.NamespacedName{
+				Namespace: namespace,
+				Name:      migrationName.Name + "-migrate",
+			}, &batchv1.Job{})).ShouldNot(Succeed())
+		})
+	})
+	When("A GlanceImageMigration runs for a ready Glance", func() {
+		var migrationName types.NamespacedName
+		BeforeEach(func() {
+			migrationName = types.NamespacedName{Namespace: namespace, Name: "glance-migration"}
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+
+			spec := GetGlanceDefaultSpec()
+			spec["customServiceConfig"] = "[DEFAULT]\nenabled_backends=backend1:s3,backend2:s3"
+			delete(spec, "notificationBusInstance")
+			DeferCleanup(th.DeleteInstance, CreateGlance(glanceTest.Instance, spec, annotations))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceTest.Instance.Namespace,
+					GetGlance(glanceName).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.SimulateMariaDBDatabaseCompleted(glanceTest.GlanceDatabaseName)
+			mariadb.SimulateMariaDBAccountCompleted(glanceTest.GlanceDatabaseAccount)
+			th.SimulateJobSuccess(glanceTest.GlanceDBSync)
+			keystoneAPI := keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace)
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystoneAPI)
+			keystone.SimulateKeystoneServiceReady(glanceTest.KeystoneService)
+			keystone.SimulateKeystoneEndpointReady(glanceTest.GlanceSingle)
+			Eventually(func(_ Gomega) {
+				GlanceAPIExists(glanceTest.GlanceSingle)
+			}, timeout, interval).Should(Succeed())
+			th.SimulateStatefulSetReplicaReady(glanceTest.GlanceSingle)
+			th.ExpectCondition(
+				glanceTest.GlanceSingle,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			Eventually(func(g Gomega) {
+				g.Expect(GetGlance(glanceName).IsReady()).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+
+			for _, stores := range []string{"backend1", "backend1,backend2", "backend1"} {
+				imageAPI.AddImage(map[string]any{
+					"status": "active",
+					"stores": stores,
+					"tags":   []any{"migrate"},
+				})
+			}
+			DeferCleanup(th.DeleteInstance, th.CreateUnstructured(map[string]any{
+				"apiVersion": "glance.openstack.org/v1beta1",
+				"kind":       "GlanceImageMigration",
+				"metadata": map[string]any{
+					"name":      migrationName.Name,
+					"namespace": migrationName.Namespace,
+				},
+				"spec": map[string]any{
+					"glanceName":  glanceName.Name,
+					"sourceStore": "backend1",
+					"targetStore": "backend2",
+					"imageFilter": map[string]any{
+						"tag": "migrate",
+					},
+				},
+			}))
+		})
+		It("reports the progress while the Job is running", func() {
+			th.GetJob(types.NamespacedName{Namespace: namespace, Name: migrationName.Name + "-migrate"})
+			Eventually(func(g Gomega) {
+				migration := &glancev1.GlanceImageMigration{}
+				g.Expect(k8sClient.Get(ctx, migrationName, migration)).Should(Succeed())
+				g.Expect(migration.Status.Total).To(Equal(3))
+				g.Expect(migration.Status.Copied).To(Equal(1))
+				g.Expect(migration.Status.Remaining).To(Equal(2))
+				g.Expect(migration.Status.CompletionTime).To(BeNil())
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("A GlanceImage is created for a Glance", func() {
+		var imageName types.NamespacedName
+		BeforeEach(func() {
+			imageName = types.NamespacedName{Namespace: namespace, Name: "cirros"}
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+
+			spec := GetGlanceDefaultSpec()
+			spec["customServiceConfig"] = GlanceS3Backend
+			delete(spec, "notificationBusInstance")
+			DeferCleanup(th.DeleteInstance, CreateGlance(glanceTest.Instance, spec, annotations))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceTest.Instance.Namespace,
+					GetGlance(glanceName).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.SimulateMariaDBDatabaseCompleted(glanceTest.GlanceDatabaseName)
+			mariadb.SimulateMariaDBAccountCompleted(glanceTest.GlanceDatabaseAccount)
+			th.SimulateJobSuccess(glanceTest.GlanceDBSync)
+			keystoneAPI := keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace)
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystoneAPI)
+			keystone.SimulateKeystoneServiceReady(glanceTest.KeystoneService)
+			keystone.SimulateKeystoneEndpointReady(glanceTest.GlanceSingle)
+			Eventually(func(_ Gomega) {
+				GlanceAPIExists(glanceTest.GlanceSingle)
+			}, timeout, interval).Should(Succeed())
+			th.SimulateStatefulSetReplicaReady(glanceTest.GlanceSingle)
+			th.ExpectCondition(
+				glanceTest.GlanceSingle,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+
+			DeferCleanup(th.DeleteInstance, th.CreateUnstructured(map[string]any{
+				"apiVersion": "glance.openstack.org/v1beta1",
+				"kind":       "GlanceImage",
+				"metadata": map[string]any{
+					"name":      imageName.Name,
+					"namespace": imageName.Namespace,
+				},
+				"spec": map[string]any{
+					"glanceName": glanceName.Name,
+					"source": map[string]any{
+						"url": "http://example.com/cirros.img",
+					},
+					"properties": map[string]any{
+						"os_distro": "cirros",
+					},
+					"stores": []string{"backend1"},
+				},
+			}))
+		})
+		It("imports the image with web-download", func() {
+			image := &glancev1.GlanceImage{}
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, imageName, image)).Should(Succeed())
+				g.Expect(image.IsReady()).To(BeTrue())
+				g.Expect(image.Status.ImageID).ToNot(BeEmpty())
+				g.Expect(image.Status.ImageStatus).To(Equal("active"))
+				g.Expect(image.Status.Stores).To(Equal([]string{"backend1"}))
+			}, timeout, interval).Should(Succeed())
+
+			created := imageAPI.GetImage(image.Status.ImageID)
+			Expect(created).To(HaveKeyWithValue("name", imageName.Name))
+			Expect(created).To(HaveKeyWithValue("disk_format", "qcow2"))
+			Expect(created).To(HaveKeyWithValue("visibility", "public"))
+			Expect(created).To(HaveKeyWithValue("os_distro", "cirros"))
+			imageImport, ok := imageAPI.GetImport(image.Status.ImageID)
+			Expect(ok).To(BeTrue())
+			Expect(imageImport.Method).To(Equal("web-download"))
+			Expect(imageImport.URI).To(Equal("http://example.com/cirros.img"))
+			Expect(imageImport.Stores).To(Equal([]string{"backend1"}))
+		})
+		It("deletes the image with the GlanceImage", func() {
+			image := &glancev1.GlanceImage{}
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, imageName, image)).Should(Succeed())
+				g.Expect(image.IsReady()).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+
+			th.DeleteInstance(image)
+			Expect(imageAPI.GetImage(image.Status.ImageID)).To(BeNil())
+		})
+	})
 	When("Glance CR is created without container images defined", func() {
 		BeforeEach(func() {
 			// GlanceEmptySpec is used to provide a standard Glance CR where no
@@ -339,6 +753,57 @@ var _ = Describe("Glance controller", func() {
 				g.Expect(cron.Spec.Schedule).To(Equal(glance.Spec.DBPurge.Schedule))
 			}, timeout, interval).Should(Succeed())
 		})
+		It("does not create the image cleanup job by default", func() {
+			AssertCronJobDoesNotExist(glanceTest.ImageCleanupCronJob)
+		})
+		It("creates and removes the image cleanup job", func() {
+			Eventually(func(g Gomega) {
+				glance := GetGlance(glanceTest.Instance)
+				glance.Spec.ImageCleanup = &glancev1.ImageCleanup{
+					MaxAge: glancev1.ImageCleanupMaxAge{Queued: 12},
+					DryRun: true,
+				}
+				g.Expect(k8sClient.Update(ctx, glance)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				cron := GetCronJob(glanceTest.ImageCleanupCronJob)
+				g.Expect(cron.Spec.Schedule).To(Equal(glancev1.ImageCleanupDefaultSchedule))
+				container := cron.Spec.JobTemplate.Spec.Template.Spec.Containers[0]
+				g.Expect(container.Env).To(ContainElements(
+					corev1.EnvVar{Name: "DRY_RUN", Value: "true"},
+					corev1.EnvVar{Name: "MAX_AGE_QUEUED", Value: "12"},
+				))
+				g.Expect(cron.Spec.JobTemplate.Spec.Template.Labels).To(
+					HaveKeyWithValue("glance.openstack.org/cronjob", glanceTest.ImageCleanupCronJob.Name))
+			}, timeout, interval).Should(Succeed())
+
+			// the default staging area lives in the glance PVC of each
+			// replica, and it is cleaned up on the node of the replica
+			Eventually(func(g Gomega) {
+				cron := GetCronJob(glanceTest.StagingCleanupCronJob)
+				g.Expect(cron.Spec.Schedule).To(Equal(glancev1.ImageCleanupDefaultSchedule))
+				podSpec := cron.Spec.JobTemplate.Spec.Template.Spec
+				g.Expect(podSpec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].
+					LabelSelector.MatchExpressions[0].Values).To(ConsistOf(glanceTest.GlanceSingle.Name + "-0"))
+				g.Expect(podSpec.Volumes).To(ContainElement(HaveField("VolumeSource.PersistentVolumeClaim.ClaimName",
+					"glance-"+glanceTest.GlanceSingle.Name+"-0")))
+				g.Expect(podSpec.Containers[0].Env).To(ContainElements(
+					corev1.EnvVar{Name: "DRY_RUN", Value: "true"},
+					corev1.EnvVar{Name: "STAGING_ONLY", Value: "true"},
+					corev1.EnvVar{Name: "STAGING_ROOT", Value: glance.DefaultStagingDir},
+				))
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				glance := GetGlance(glanceTest.Instance)
+				glance.Spec.ImageCleanup = nil
+				g.Expect(k8sClient.Update(ctx, glance)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			AssertCronJobDoesNotExist(glanceTest.ImageCleanupCronJob)
+			AssertCronJobDoesNotExist(glanceTest.StagingCleanupCronJob)
+		})
 	})
 	When("GlanceCR is created with nodeSelector", func() {
 		BeforeEach(func() {
@@ -90,6 +90,7 @@ type GlanceTestData struct {
 	GlanceRoleBinding           types.NamespacedName
 	GlanceSA                    types.NamespacedName
 	GlanceDBSync                types.NamespacedName
+	GlanceMetadefs              types.NamespacedName
 	GlancePublicSvc             types.NamespacedName
 	GlanceInternalSvc           types.NamespacedName
 	GlanceInternalKeystoneEP    types.NamespacedName
@@ -108,6 +109,8 @@ type GlanceTestData struct {
 	GlanceMemcached             types.NamespacedName
 	KeystoneService             types.NamespacedName
 	DBPurgeCronJob              types.NamespacedName
+	ImageCleanupCronJob         types.NamespacedName
+	StagingCleanupCronJob       types.NamespacedName
 	GlanceAPITopologies         []types.NamespacedName
 	RabbitmqSecretName          string
 	NotificationsBusInstance    string
@@ -130,6 +133,10 @@ func GetGlanceTestData(glanceName types.NamespacedName) GlanceTestData {
 			Namespace: glanceName.Namespace,
 			Name:      "glance-db-sync",
 		},
+		GlanceMetadefs: types.NamespacedName{
+			Namespace: glanceName.Namespace,
+			Name:      "glance-metadefs",
+		},
 		GlanceSingle: types.NamespacedName{
 			Namespace: glanceName.Namespace,
 			Name:      fmt.Sprintf("%s-default-single", glanceName.Name),
@@ -260,6 +267,14 @@ func GetGlanceTestData(glanceName types.NamespacedName) GlanceTestData {
 			Namespace: glanceName.Namespace,
 			Name:      fmt.Sprintf("%s-db-purge", glanceName.Name),
 		},
+		ImageCleanupCronJob: types.NamespacedName{
+			Namespace: glanceName.Namespace,
+			Name:      fmt.Sprintf("%s-image-cleanup", glanceName.Name),
+		},
+		StagingCleanupCronJob: types.NamespacedName{
+			Namespace: glanceName.Namespace,
+			Name:      fmt.Sprintf("%s-default-single-0-staging-cleanup", glanceName.Name),
+		},
 		GlanceAPITopologies: []types.NamespacedName{
 			{
 				Namespace: glanceName.Namespace,
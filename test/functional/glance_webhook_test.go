@@ -80,4 +80,129 @@ var _ = Describe("Glance webhook", func() {
 				ContainSubstring("field \"spec.notificationBusInstance\" is deprecated, use \"spec.notificationsBus.cluster\" instead"))
 		}, timeout, interval).Should(Succeed())
 	})
+
+	It("rejects a PVC shrink", func() {
+		spec := GetDefaultGlanceSpec()
+		glanceAPIs := spec["glanceAPIs"].(map[string]any)
+		defaultAPI := glanceAPIs["default"].(map[string]any)
+		defaultAPI["replicas"] = 0
+
+		glanceName := types.NamespacedName{
+			Namespace: namespace,
+			Name:      "glance-webhook-shrink",
+		}
+
+		raw := map[string]any{
+			"apiVersion": "glance.openstack.org/v1beta1",
+			"kind":       "Glance",
+			"metadata": map[string]any{
+				"name":      glanceName.Name,
+				"namespace": glanceName.Namespace,
+			},
+			"spec": spec,
+		}
+
+		unstructuredObj := &unstructured.Unstructured{Object: raw}
+		_, err := controllerutil.CreateOrPatch(
+			ctx, k8sClient, unstructuredObj, func() error { return nil })
+		Expect(err).ShouldNot(HaveOccurred())
+
+		DeferCleanup(func() {
+			_ = k8sClient.Delete(ctx, unstructuredObj)
+		})
+
+		// The default API inherits the top-level storageRequest (10G)
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, glanceName, unstructuredObj)).Should(Succeed())
+			specMap := unstructuredObj.Object["spec"].(map[string]any)
+			specMap["storage"] = map[string]any{
+				"storageRequest": "1G",
+			}
+			err := k8sClient.Update(ctx, unstructuredObj)
+			g.Expect(err).Should(HaveOccurred())
+
+			var statusError *k8s_errors.StatusError
+			g.Expect(errors.As(err, &statusError)).To(BeTrue())
+			g.Expect(statusError.ErrStatus.Details.Kind).To(Equal("Glance"))
+			g.Expect(statusError.ErrStatus.Message).To(
+				ContainSubstring("spec.glanceAPIs[default].storage.storageRequest"))
+		}, timeout, interval).Should(Succeed())
+
+		// Expanding the PVCs is allowed
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, glanceName, unstructuredObj)).Should(Succeed())
+			specMap := unstructuredObj.Object["spec"].(map[string]any)
+			specMap["storage"] = map[string]any{
+				"storageRequest": "20G",
+			}
+			g.Expect(k8sClient.Update(ctx, unstructuredObj)).Should(Succeed())
+		}, timeout, interval).Should(Succeed())
+	})
+
+	It("rejects an autogrow maximumSize smaller than the storageRequest", func() {
+		spec := GetDefaultGlanceSpec()
+		glanceAPIs := spec["glanceAPIs"].(map[string]any)
+		defaultAPI := glanceAPIs["default"].(map[string]any)
+		defaultAPI["replicas"] = 0
+		spec["storage"] = map[string]any{
+			"storageRequest": "10G",
+			"autogrow": map[string]any{
+				"increment":   "5G",
+				"maximumSize": "1G",
+			},
+		}
+
+		raw := map[string]any{
+			"apiVersion": "glance.openstack.org/v1beta1",
+			"kind":       "Glance",
+			"metadata": map[string]any{
+				"name":      "glance-webhook-autogrow",
+				"namespace": namespace,
+			},
+			"spec": spec,
+		}
+
+		unstructuredObj := &unstructured.Unstructured{Object: raw}
+		_, err := controllerutil.CreateOrPatch(
+			ctx, k8sClient, unstructuredObj, func() error { return nil })
+		Expect(err).Should(HaveOccurred())
+
+		var statusError *k8s_errors.StatusError
+		Expect(errors.As(err, &statusError)).To(BeTrue())
+		Expect(statusError.ErrStatus.Details.Kind).To(Equal("Glance"))
+		Expect(statusError.ErrStatus.Message).To(
+			ContainSubstring("spec.storage.autogrow.maximumSize"))
+	})
+
+	It("rejects a shared staging area that is not a PVC", func() {
+		spec := GetDefaultGlanceSpec()
+		glanceAPIs := spec["glanceAPIs"].(map[string]any)
+		defaultAPI := glanceAPIs["default"].(map[string]any)
+		defaultAPI["staging"] = map[string]any{
+			"size":       "10G",
+			"shared":     true,
+			"volumeType": "emptyDir",
+		}
+
+		raw := map[string]any{
+			"apiVersion": "glance.openstack.org/v1beta1",
+			"kind":       "Glance",
+			"metadata": map[string]any{
+				"name":      "glance-webhook-staging",
+				"namespace": namespace,
+			},
+			"spec": spec,
+		}
+
+		unstructuredObj := &unstructured.Unstructured{Object: raw}
+		_, err := controllerutil.CreateOrPatch(
+			ctx, k8sClient, unstructuredObj, func() error { return nil })
+		Expect(err).Should(HaveOccurred())
+
+		var statusError *k8s_errors.StatusError
+		Expect(errors.As(err, &statusError)).To(BeTrue())
+		Expect(statusError.ErrStatus.Details.Kind).To(Equal("Glance"))
+		Expect(statusError.ErrStatus.Message).To(
+			ContainSubstring("spec.glanceAPIs[default].staging.volumeType"))
+	})
 })
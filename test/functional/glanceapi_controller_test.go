@@ -19,6 +19,7 @@ package functional
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/types"
 
@@ -26,18 +27,27 @@ import (
 	. "github.com/onsi/gomega"    //revive:disable:dot-imports
 	glancev1 "github.com/openstack-k8s-operators/glance-operator/api/v1beta1"
 	"github.com/openstack-k8s-operators/glance-operator/internal/glance"
+	"github.com/openstack-k8s-operators/glance-operator/internal/glanceapi"
 	memcachedv1 "github.com/openstack-k8s-operators/infra-operator/apis/memcached/v1beta1"
 	topologyv1 "github.com/openstack-k8s-operators/infra-operator/apis/topology/v1beta1"
 	keystonev1 "github.com/openstack-k8s-operators/keystone-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	util "github.com/openstack-k8s-operators/lib-common/modules/common/util"
 	"gopkg.in/ini.v1"
 	client "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	//revive:disable-next-line:dot-imports
 	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
 	mariadbv1 "github.com/openstack-k8s-operators/mariadb-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 )
 
 var _ = Describe("Glanceapi controller", func() {
@@ -224,6 +234,69 @@ var _ = Describe("Glanceapi controller", func() {
 			Expect(section).ShouldNot(BeNil(), "Should find [barbican] section")
 			Expect(section.Key("barbican_region_name").String()).Should(Equal(testRegion))
 		})
+		It("denies cluster-internal hosts to web-download by default", func() {
+			th.ExpectCondition(
+				glanceTest.GlanceSingle,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				condition.ServiceConfigReadyCondition,
+				corev1.ConditionTrue,
+			)
+			secretDataMap := th.GetSecret(glanceTest.GlanceSingleConfigMapData)
+			Expect(secretDataMap).ShouldNot(BeNil())
+			cfg, err := ini.Load(secretDataMap.Data["00-config.conf"])
+			Expect(err).ShouldNot(HaveOccurred(), "Should be able to parse config as INI")
+			section := cfg.Section("import_filtering_opts")
+			Expect(section.Key("allowed_schemes").String()).Should(Equal("http,https"))
+			Expect(section.Key("allowed_ports").String()).Should(Equal("80,443"))
+			Expect(section.Key("disallowed_hosts").String()).Should(ContainSubstring("169.254.169.254"))
+			Expect(section.HasKey("allowed_hosts")).Should(BeFalse())
+		})
+	})
+	When("the GlanceAPI is created with importFiltering", func() {
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+
+			spec := GetDefaultGlanceAPISpec(GlanceAPITypeSingle)
+			spec["importFiltering"] = map[string]any{
+				"allowedHosts":    []string{"images.example.com"},
+				"disallowedPorts": []int{8080},
+			}
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceSingle, spec))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+		})
+		It("renders the provided filtering in the config", func() {
+			th.ExpectCondition(
+				glanceTest.GlanceSingle,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				condition.ServiceConfigReadyCondition,
+				corev1.ConditionTrue,
+			)
+			secretDataMap := th.GetSecret(glanceTest.GlanceSingleConfigMapData)
+			Expect(secretDataMap).ShouldNot(BeNil())
+			cfg, err := ini.Load(secretDataMap.Data["00-config.conf"])
+			Expect(err).ShouldNot(HaveOccurred(), "Should be able to parse config as INI")
+			section := cfg.Section("import_filtering_opts")
+			Expect(section.Key("allowed_hosts").String()).Should(Equal("images.example.com"))
+			Expect(section.HasKey("disallowed_hosts")).Should(BeFalse())
+			// allowed_ports is reset, otherwise Glance ignores the deny list
+			Expect(section.HasKey("allowed_ports")).Should(BeTrue())
+			Expect(section.Key("allowed_ports").String()).Should(BeEmpty())
+			Expect(section.Key("disallowed_ports").String()).Should(Equal("8080"))
+		})
 	})
 	When("the Secret is created with quorum queues enabled", func() {
 		BeforeEach(func() {
@@ -721,6 +794,1667 @@ var _ = Describe("Glanceapi controller", func() {
 			Expect(headlessSvc.Name).To(Equal(glanceTest.GlanceSingle.Name))
 		})
 	})
+	When("GlanceAPI is created with an outbound proxy", func() {
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+
+			spec := CreateGlanceAPISpec(GlanceAPITypeSingle)
+			spec["proxy"] = map[string]any{
+				"httpsProxy": "http://proxy.example.com:3128",
+				"noProxy":    []string{"images.internal.example.com"},
+			}
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceSingle, spec))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+			th.ExpectCondition(
+				glanceTest.GlanceSingle,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				condition.ServiceConfigReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+		It("injects the proxy in the httpd container only", func() {
+			ss := th.GetStatefulSet(glanceTest.GlanceSingle)
+			Expect(ss.Spec.Template.Spec.Containers).To(HaveLen(2))
+
+			httpdEnv := ss.Spec.Template.Spec.Containers[1].Env
+			Expect(httpdEnv).To(ContainElement(
+				corev1.EnvVar{Name: "HTTPS_PROXY", Value: "http://proxy.example.com:3128"}))
+			Expect(httpdEnv).ToNot(ContainElement(HaveField("Name", "HTTP_PROXY")))
+			noProxy := ""
+			for _, e := range httpdEnv {
+				if e.Name == "NO_PROXY" {
+					noProxy = e.Value
+				}
+			}
+			Expect(strings.Split(noProxy, ",")).To(ContainElements(
+				"images.internal.example.com", "openstack", ".svc", "localhost"))
+
+			// the log sidecar does not reach any external resource
+			logEnv := ss.Spec.Template.Spec.Containers[0].Env
+			Expect(logEnv).ToNot(ContainElement(HaveField("Name", "HTTPS_PROXY")))
+		})
+	})
+	When("GlanceAPI is created with a keyManager", func() {
+		var keyManager map[string]any
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+		})
+		JustBeforeEach(func() {
+			spec := CreateGlanceAPISpec(GlanceAPITypeSingle)
+			spec["keyManager"] = keyManager
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceSingle, spec))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+		})
+		When("barbican is requested but not deployed", func() {
+			BeforeEach(func() {
+				keyManager = map[string]any{
+					"backend": "barbican",
+				}
+			})
+			It("renders a disabled key manager", func() {
+				th.ExpectCondition(
+					glanceTest.GlanceSingle,
+					ConditionGetterFunc(GlanceAPIConditionGetter),
+					glancev1.KeyManagerReadyCondition,
+					corev1.ConditionFalse,
+				)
+				th.ExpectCondition(
+					glanceTest.GlanceSingle,
+					ConditionGetterFunc(GlanceAPIConditionGetter),
+					condition.ServiceConfigReadyCondition,
+					corev1.ConditionTrue,
+				)
+				secretDataMap := th.GetSecret(glanceTest.GlanceSingleConfigMapData)
+				Expect(secretDataMap).ShouldNot(BeNil())
+				cfg, err := ini.Load(secretDataMap.Data["00-config.conf"])
+				Expect(err).ShouldNot(HaveOccurred(), "Should be able to parse config as INI")
+				Expect(cfg.Section("key_manager").Key("backend").String()).Should(
+					Equal(glance.KeyManagerDisabledBackend))
+				Expect(cfg.HasSection("barbican")).Should(BeFalse())
+			})
+		})
+		When("signature verification is required and barbican is not deployed", func() {
+			BeforeEach(func() {
+				keyManager = map[string]any{
+					"backend":                      "barbican",
+					"requireSignatureVerification": true,
+				}
+			})
+			It("does not render the service config", func() {
+				th.ExpectCondition(
+					glanceTest.GlanceSingle,
+					ConditionGetterFunc(GlanceAPIConditionGetter),
+					glancev1.KeyManagerReadyCondition,
+					corev1.ConditionFalse,
+				)
+				th.ExpectCondition(
+					glanceTest.GlanceSingle,
+					ConditionGetterFunc(GlanceAPIConditionGetter),
+					condition.ServiceConfigReadyCondition,
+					corev1.ConditionUnknown,
+				)
+			})
+		})
+		When("a local key is provided and signature verification is required", func() {
+			BeforeEach(func() {
+				keyName := types.NamespacedName{Namespace: namespace, Name: "glance-signing-certificates"}
+				DeferCleanup(k8sClient.Delete, ctx, th.CreateSecret(
+					keyName,
+					map[string][]byte{
+						"cd7cc675-e573-419c-8fff-33a72734a243": []byte("-----BEGIN CERTIFICATE-----"),
+					},
+				))
+				keyManager = map[string]any{
+					"backend":                      "local",
+					"localKeySecret":               keyName.Name,
+					"requireSignatureVerification": true,
+				}
+			})
+			It("renders the local key manager and the signature filter", func() {
+				th.ExpectCondition(
+					glanceTest.GlanceSingle,
+					ConditionGetterFunc(GlanceAPIConditionGetter),
+					glancev1.KeyManagerReadyCondition,
+					corev1.ConditionTrue,
+				)
+				secretDataMap := th.GetSecret(glanceTest.GlanceSingleConfigMapData)
+				Expect(secretDataMap).ShouldNot(BeNil())
+				cfg, err := ini.Load(secretDataMap.Data["00-config.conf"])
+				Expect(err).ShouldNot(HaveOccurred(), "Should be able to parse config as INI")
+				Expect(cfg.Section("key_manager").Key("backend").String()).Should(
+					Equal(glance.KeyManagerLocalBackend))
+				Expect(cfg.Section("paste_deploy").Key("config_file").String()).Should(
+					Equal("/etc/glance/paste/glance-api-paste.ini"))
+
+				paste, err := ini.Load(secretDataMap.Data["glance-api-paste.ini"])
+				Expect(err).ShouldNot(HaveOccurred(), "Should be able to parse paste config as INI")
+				Expect(paste.Section("pipeline:glance-api-keystone").Key("pipeline").String()).Should(
+					ContainSubstring("authtoken context signature rootapp"))
+				Expect(string(secretDataMap.Data["10-glance-wsgi.conf"])).Should(
+					ContainSubstring("python-path=" + glance.ScriptsDir))
+			})
+			It("mounts the signing certificates", func() {
+				ss := th.GetStatefulSet(glanceTest.GlanceSingle)
+				Expect(ss.Spec.Template.Spec.Volumes).To(ContainElement(
+					HaveField("Name", glance.SigningCertificatesVolume)))
+				container := ss.Spec.Template.Spec.Containers[1]
+				Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+					Name:      glance.SigningCertificatesVolume,
+					MountPath: glance.SigningCertificatesDir,
+					ReadOnly:  true,
+				}))
+				Expect(container.Env).To(ContainElement(corev1.EnvVar{
+					Name:  "PYTHONPATH",
+					Value: glance.ScriptsDir,
+				}))
+			})
+		})
+	})
+	When("GlanceAPI is created with propertyProtections", func() {
+		var ppName types.NamespacedName
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+
+			ppName = types.NamespacedName{Namespace: namespace, Name: "glance-property-protections"}
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ppName.Name,
+					Namespace: ppName.Namespace,
+				},
+				Data: map[string]string{
+					glance.PropertyProtectionsFileName: "[^x_owner_.*]\ncreate = admin\nread = admin,member\nupdate = admin\ndelete = admin\n",
+				},
+			}
+			Expect(k8sClient.Create(ctx, cm)).Should(Succeed())
+			DeferCleanup(k8sClient.Delete, ctx, cm)
+
+			spec := CreateGlanceAPISpec(GlanceAPITypeSingle)
+			spec["propertyProtections"] = map[string]any{
+				"configMap":  ppName.Name,
+				"ruleFormat": "roles",
+			}
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceSingle, spec))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+			th.ExpectCondition(
+				glanceTest.GlanceSingle,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				condition.ServiceConfigReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+		It("renders the property protection options", func() {
+			secretDataMap := th.GetSecret(glanceTest.GlanceSingleConfigMapData)
+			Expect(secretDataMap).ShouldNot(BeNil())
+			cfg, err := ini.Load(secretDataMap.Data["00-config.conf"])
+			Expect(err).ShouldNot(HaveOccurred(), "Should be able to parse config as INI")
+			section := cfg.Section("DEFAULT")
+			Expect(section.Key("property_protection_file").String()).Should(Equal(glance.PropertyProtectionsPath))
+			Expect(section.Key("property_protection_rule_format").String()).Should(Equal("roles"))
+		})
+		It("mounts the rules file in the httpd container", func() {
+			ss := th.GetStatefulSet(glanceTest.GlanceSingle)
+			Expect(ss.Spec.Template.Spec.Volumes).To(ContainElement(
+				HaveField("Name", glance.PropertyProtectionsVolume)))
+			Expect(ss.Spec.Template.Spec.Containers[1].VolumeMounts).To(ContainElement(
+				corev1.VolumeMount{
+					Name:      glance.PropertyProtectionsVolume,
+					MountPath: glance.PropertyProtectionsPath,
+					SubPath:   glance.PropertyProtectionsFileName,
+					ReadOnly:  true,
+				}))
+		})
+		It("rolls out the Pods when the rules are updated", func() {
+			// Grab the current config hash
+			apiOriginalHash := GetEnvVarValue(
+				th.GetStatefulSet(glanceTest.GlanceSingle).Spec.Template.Spec.Containers[1].Env, "CONFIG_HASH", "")
+			Expect(apiOriginalHash).NotTo(BeEmpty())
+
+			// Change the content of the rules
+			Eventually(func(g Gomega) {
+				cm := &corev1.ConfigMap{}
+				g.Expect(k8sClient.Get(ctx, ppName, cm)).Should(Succeed())
+				cm.Data[glance.PropertyProtectionsFileName] = "[.*]\ncreate = admin\nread = admin\nupdate = admin\ndelete = admin\n"
+				g.Expect(k8sClient.Update(ctx, cm)).Should(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			// Assert that the deployment is updated
+			Eventually(func(g Gomega) {
+				newHash := GetEnvVarValue(
+					th.GetStatefulSet(glanceTest.GlanceSingle).Spec.Template.Spec.Containers[1].Env, "CONFIG_HASH", "")
+				g.Expect(newHash).NotTo(BeEmpty())
+				g.Expect(newHash).NotTo(Equal(apiOriginalHash))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("GlanceAPI is created with CPU and memory resources", func() {
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+
+			spec := CreateGlanceAPISpec(GlanceAPITypeSingle)
+			spec["resources"] = map[string]any{
+				"requests": map[string]any{
+					"cpu": "8",
+				},
+				"limits": map[string]any{
+					"memory": "1Gi",
+				},
+			}
+			spec["threads"] = 5
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceSingle, spec))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+			th.ExpectCondition(
+				glanceTest.GlanceSingle,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				condition.ServiceConfigReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+		It("derives the workers from the resources", func() {
+			// 8 CPUs are requested, but the memory limit fits 4 processes
+			glanceAPI := GetGlanceAPI(glanceTest.GlanceSingle)
+			Expect(glanceAPI.Status.Workers).To(Equal(int32(4)))
+			Expect(glanceAPI.Status.Processes).To(Equal(int32(4)))
+			Expect(glanceAPI.Status.Threads).To(Equal(int32(5)))
+
+			secretDataMap := th.GetSecret(glanceTest.GlanceSingleConfigMapData)
+			Expect(secretDataMap).ShouldNot(BeNil())
+			cfg, err := ini.Load(secretDataMap.Data["00-config.conf"])
+			Expect(err).ShouldNot(HaveOccurred(), "Should be able to parse config as INI")
+			Expect(cfg.Section("DEFAULT").Key("workers").String()).Should(Equal("4"))
+			Expect(string(secretDataMap.Data["10-glance-wsgi.conf"])).Should(
+				ContainSubstring("processes=4 threads=5"))
+		})
+	})
+	When("GlanceAPI is created with per-container resources", func() {
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+
+			spec := CreateGlanceAPISpec(GlanceAPITypeSingle)
+			spec["resources"] = map[string]any{
+				"requests": map[string]any{
+					"cpu":    "2",
+					"memory": "2Gi",
+				},
+			}
+			spec["containerResources"] = map[string]any{
+				"httpd": map[string]any{
+					"requests": map[string]any{
+						"cpu": "6",
+					},
+				},
+			}
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceSingle, spec))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+			th.ExpectCondition(
+				glanceTest.GlanceSingle,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				condition.ServiceConfigReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+		It("applies the resources to each container", func() {
+			ss := th.GetStatefulSet(glanceTest.GlanceSingle)
+			Expect(ss.Spec.Template.Spec.Containers).To(HaveLen(2))
+			// the log sidecar gets a small default instead of Resources
+			logMemory := ss.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceMemory]
+			Expect(logMemory.String()).To(Equal("32Mi"))
+			httpdCPU := ss.Spec.Template.Spec.Containers[1].Resources.Requests[corev1.ResourceCPU]
+			Expect(httpdCPU.String()).To(Equal("6"))
+			// the WSGI processes are derived from the httpd container
+			Expect(GetGlanceAPI(glanceTest.GlanceSingle).Status.Processes).To(Equal(int32(6)))
+		})
+	})
+	When("GlanceAPI is created with logging options", func() {
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+
+			spec := CreateGlanceAPISpec(GlanceAPITypeSingle)
+			spec["logging"] = map[string]any{
+				"debug":       true,
+				"json":        true,
+				"destination": "stdout",
+			}
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceSingle, spec))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+			th.ExpectCondition(
+				glanceTest.GlanceSingle,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				condition.ServiceConfigReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+		It("renders the logging options", func() {
+			secretDataMap := th.GetSecret(glanceTest.GlanceSingleConfigMapData)
+			Expect(secretDataMap).ShouldNot(BeNil())
+			cfg, err := ini.Load(secretDataMap.Data["00-config.conf"])
+			Expect(err).ShouldNot(HaveOccurred(), "Should be able to parse config as INI")
+			section := cfg.Section("DEFAULT")
+			Expect(section.Key("debug").String()).Should(Equal("True"))
+			Expect(section.Key("use_json").String()).Should(Equal("True"))
+			Expect(section.Key("use_stderr").String()).Should(Equal("True"))
+			Expect(section.HasKey("log_file")).Should(BeFalse())
+		})
+		It("does not deploy the glance-log sidecar", func() {
+			ss := th.GetStatefulSet(glanceTest.GlanceSingle)
+			Expect(ss.Spec.Template.Spec.Containers).To(HaveLen(1))
+			Expect(ss.Spec.Template.Spec.Containers[0].Name).To(Equal("glance-httpd"))
+		})
+	})
+	When("GlanceAPI is created with audit enabled", func() {
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+
+			spec := CreateGlanceAPISpec(GlanceAPITypeSingle)
+			// no notifications bus is configured: events fall back to the log
+			spec["audit"] = map[string]any{
+				"driver": "notifications",
+			}
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceSingle, spec))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+			th.ExpectCondition(
+				glanceTest.GlanceSingle,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				condition.ServiceConfigReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+		It("renders the audit pipeline and map", func() {
+			secretDataMap := th.GetSecret(glanceTest.GlanceSingleConfigMapData)
+			Expect(secretDataMap).ShouldNot(BeNil())
+			cfg, err := ini.Load(secretDataMap.Data["00-config.conf"])
+			Expect(err).ShouldNot(HaveOccurred(), "Should be able to parse config as INI")
+			Expect(cfg.Section("paste_deploy").Key("config_file").String()).Should(
+				Equal("/etc/glance/paste/glance-api-paste.ini"))
+			Expect(cfg.Section("audit_middleware_notifications").Key("driver").String()).Should(Equal("log"))
+
+			paste, err := ini.Load(secretDataMap.Data["glance-api-paste.ini"])
+			Expect(err).ShouldNot(HaveOccurred(), "Should be able to parse paste config as INI")
+			Expect(paste.Section("pipeline:glance-api-keystone").Key("pipeline").String()).Should(
+				ContainSubstring("authtoken audit context"))
+			Expect(paste.Section("filter:audit").Key("audit_map_file").String()).Should(
+				Equal("/etc/glance/audit/api_audit_map.conf"))
+			Expect(secretDataMap.Data).Should(HaveKey("api_audit_map.conf"))
+		})
+		It("mounts the audit files", func() {
+			ss := th.GetStatefulSet(glanceTest.GlanceSingle)
+			mounts := []string{}
+			for _, vm := range ss.Spec.Template.Spec.Containers[1].VolumeMounts {
+				mounts = append(mounts, vm.MountPath)
+			}
+			Expect(mounts).To(ContainElements(
+				"/etc/glance/paste/glance-api-paste.ini",
+				"/etc/glance/audit/api_audit_map.conf",
+			))
+		})
+	})
+	When("GlanceAPI is created with metrics sidecars", func() {
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+
+			spec := CreateGlanceAPISpec(GlanceAPITypeSingle)
+			spec["metrics"] = map[string]any{
+				"logExporterImage": "quay.io/example/mtail:latest",
+			}
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceSingle, spec))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+			th.ExpectCondition(
+				glanceTest.GlanceSingle,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				condition.ServiceConfigReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+		It("renders mod_status and the access log", func() {
+			secretDataMap := th.GetSecret(glanceTest.GlanceSingleConfigMapData)
+			Expect(secretDataMap).ShouldNot(BeNil())
+			httpdConf := string(secretDataMap.Data["httpd.conf"])
+			Expect(httpdConf).Should(ContainSubstring("Listen 127.0.0.1:9280"))
+			Expect(httpdConf).Should(ContainSubstring("SetHandler server-status"))
+			Expect(string(secretDataMap.Data["10-glance-wsgi.conf"])).Should(
+				ContainSubstring("/var/log/glance/access.log 10M\" glance_metrics"))
+			Expect(secretDataMap.Data).Should(HaveKey("glance.mtail"))
+		})
+		It("deploys the exporters sidecars", func() {
+			ss := th.GetStatefulSet(glanceTest.GlanceSingle)
+			names := []string{}
+			for _, c := range ss.Spec.Template.Spec.Containers {
+				names = append(names, c.Name)
+			}
+			Expect(names).To(Equal([]string{
+				"glance-log",
+				"glance-httpd",
+				"glance-httpd-exporter",
+				"glance-request-exporter",
+			}))
+		})
+		It("does not create the metrics Service without the monitoring CRDs", func() {
+			Consistently(func(g Gomega) {
+				svc := &corev1.Service{}
+				err := k8sClient.Get(ctx, types.NamespacedName{
+					Namespace: glanceTest.GlanceSingle.Namespace,
+					Name:      glanceapi.MetricsServiceName(GetGlanceAPI(glanceTest.GlanceSingle)),
+				}, svc)
+				g.Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+			}, timeout/4, interval).Should(Succeed())
+		})
+	})
+	When("the GlanceAPI backend configuration changes", func() {
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+			spec := CreateGlanceAPISpec(GlanceAPITypeSingle)
+			spec["customServiceConfig"] = GetDummyBackend()
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceSingle, spec))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+			th.GetStatefulSet(glanceTest.GlanceSingle)
+		})
+		It("rolls out a new StatefulSet revision and emits an Event", func() {
+			// Revision 0 Pods are labelled, while the immutable selector
+			// keeps the plain service labels
+			ss := th.GetStatefulSet(glanceTest.GlanceSingle)
+			Expect(ss.Spec.Template.Labels).To(HaveKeyWithValue(glanceapi.RevisionLabel, "0"))
+			Expect(ss.Spec.Selector.MatchLabels).ToNot(HaveKey(glanceapi.RevisionLabel))
+
+			// envtest has no StatefulSet controller: create the PVCs of the
+			// first replica of revision 0
+			pvcs := map[string]map[string]string{
+				fmt.Sprintf("%s-%s-0", glance.ServiceName, glanceTest.GlanceSingle.Name):       nil,
+				fmt.Sprintf("%s-cache-%s-0", glance.ServiceName, glanceTest.GlanceSingle.Name): {"image-cache": ""},
+			}
+			for name, pvcAnnotations := range pvcs {
+				pvc := &corev1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        name,
+						Namespace:   glanceTest.GlanceSingle.Namespace,
+						Annotations: pvcAnnotations,
+						Labels: map[string]string{
+							common.OwnerSelector:     glanceTest.GlanceSingle.Name,
+							common.ComponentSelector: glance.Component,
+						},
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						Resources: corev1.VolumeResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(glanceTest.GlancePVCSize)},
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+				// the cache PVC is deleted by the controller
+				if pvcAnnotations == nil {
+					DeferCleanup(k8sClient.Delete, ctx, pvc)
+				}
+			}
+
+			Eventually(func(g Gomega) {
+				glanceAPI := GetGlanceAPI(glanceTest.GlanceSingle)
+				glanceAPI.Spec.ImageCache.Size = "1G"
+				g.Expect(k8sClient.Update(ctx, glanceAPI)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			newStatefulSet := types.NamespacedName{
+				Namespace: glanceTest.GlanceSingle.Namespace,
+				Name:      glanceTest.GlanceSingle.Name + "-r1",
+			}
+			// The current StatefulSet keeps serving until the new one is Ready
+			Eventually(func(g Gomega) {
+				glanceAPI := GetGlanceAPI(glanceTest.GlanceSingle)
+				g.Expect(glanceAPI.Status.StatefulSetRevision).To(Equal(int32(1)))
+				g.Expect(glanceAPI.Status.ServingRevision).To(Equal(int32(0)))
+			}, timeout, interval).Should(Succeed())
+			ss = th.GetStatefulSet(newStatefulSet)
+			Expect(ss.Spec.ServiceName).To(Equal(glanceTest.GlanceSingle.Name))
+			Expect(ss.Spec.Template.Labels).To(HaveKeyWithValue(glanceapi.RevisionLabel, "1"))
+			th.GetStatefulSet(glanceTest.GlanceSingle)
+
+			th.SimulateStatefulSetReplicaReady(newStatefulSet)
+			Eventually(func(g Gomega) {
+				g.Expect(GetGlanceAPI(glanceTest.GlanceSingle).Status.ServingRevision).To(Equal(int32(1)))
+			}, timeout, interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				err := k8sClient.Get(ctx, glanceTest.GlanceSingle, &appsv1.StatefulSet{})
+				g.Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+
+			// The cache PVC of the previous revision is deleted, while the
+			// glance PVC is retained by the default whenDeleted policy
+			Eventually(func(g Gomega) {
+				pvc := &corev1.PersistentVolumeClaim{}
+				err := k8sClient.Get(ctx, types.NamespacedName{
+					Namespace: glanceTest.GlanceSingle.Namespace,
+					Name:      fmt.Sprintf("%s-cache-%s-0", glance.ServiceName, glanceTest.GlanceSingle.Name),
+				}, pvc)
+				g.Expect(k8s_errors.IsNotFound(err) || !pvc.DeletionTimestamp.IsZero()).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+			pvc := &corev1.PersistentVolumeClaim{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Namespace: glanceTest.GlanceSingle.Namespace,
+				Name:      fmt.Sprintf("%s-%s-0", glance.ServiceName, glanceTest.GlanceSingle.Name),
+			}, pvc)).To(Succeed())
+			Expect(pvc.DeletionTimestamp.IsZero()).To(BeTrue())
+
+			Eventually(func(g Gomega) {
+				events := &corev1.EventList{}
+				g.Expect(k8sClient.List(ctx, events, client.InNamespace(glanceTest.GlanceSingle.Namespace))).To(Succeed())
+				reasons := []string{}
+				for _, e := range events.Items {
+					if e.InvolvedObject.Kind == "GlanceAPI" && e.InvolvedObject.Name == glanceTest.GlanceSingle.Name {
+						reasons = append(reasons, e.Reason)
+					}
+				}
+				g.Expect(reasons).To(ContainElement("StatefulSetRecreated"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("the backend configuration of a GlanceAPI with a local file store changes", func() {
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceSingle, CreateGlanceAPISpec(GlanceAPITypeSingle)))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+			th.GetStatefulSet(glanceTest.GlanceSingle)
+		})
+		It("recreates the StatefulSet with the same name to keep the images PVCs", func() {
+			Eventually(func(g Gomega) {
+				glanceAPI := GetGlanceAPI(glanceTest.GlanceSingle)
+				glanceAPI.Spec.ImageCache.Size = "1G"
+				g.Expect(k8sClient.Update(ctx, glanceAPI)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				events := &corev1.EventList{}
+				g.Expect(k8sClient.List(ctx, events, client.InNamespace(glanceTest.GlanceSingle.Namespace))).To(Succeed())
+				reasons := []string{}
+				for _, e := range events.Items {
+					if e.InvolvedObject.Kind == "GlanceAPI" && e.InvolvedObject.Name == glanceTest.GlanceSingle.Name {
+						reasons = append(reasons, e.Reason)
+					}
+				}
+				g.Expect(reasons).To(ContainElement("StatefulSetRecreated"))
+			}, timeout, interval).Should(Succeed())
+			Expect(GetGlanceAPI(glanceTest.GlanceSingle).Status.StatefulSetRevision).To(Equal(int32(0)))
+			th.GetStatefulSet(glanceTest.GlanceSingle)
+			err := k8sClient.Get(ctx, types.NamespacedName{
+				Namespace: glanceTest.GlanceSingle.Namespace,
+				Name:      glanceTest.GlanceSingle.Name + "-r1",
+			}, &appsv1.StatefulSet{})
+			Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+	When("the GlanceAPI storageRequest is increased", func() {
+		var pvcName types.NamespacedName
+		BeforeEach(func() {
+			storageClass := &storagev1.StorageClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "glance-expandable",
+				},
+				Provisioner:          "kubernetes.io/no-provisioner",
+				AllowVolumeExpansion: ptr.To(true),
+			}
+			Expect(k8sClient.Create(ctx, storageClass)).To(Succeed())
+			DeferCleanup(k8sClient.Delete, ctx, storageClass)
+
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+			spec := CreateGlanceAPISpec(GlanceAPITypeSingle)
+			spec["storage"] = map[string]any{
+				"storageRequest": glanceTest.GlancePVCSize,
+				"storageClass":   storageClass.Name,
+			}
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceSingle, spec))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+			th.GetStatefulSet(glanceTest.GlanceSingle)
+
+			// envtest has no StatefulSet controller: create the PVC of the
+			// first replica as a bound volume
+			pvcName = types.NamespacedName{
+				Namespace: glanceTest.GlanceSingle.Namespace,
+				Name:      fmt.Sprintf("%s-%s-0", glance.ServiceName, glanceTest.GlanceSingle.Name),
+			}
+			size := resource.MustParse(glanceTest.GlancePVCSize)
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      pvcName.Name,
+					Namespace: pvcName.Namespace,
+					Labels: map[string]string{
+						common.OwnerSelector:     glanceTest.GlanceSingle.Name,
+						common.ComponentSelector: glance.Component,
+					},
+				},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					StorageClassName: &storageClass.Name,
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+			DeferCleanup(k8sClient.Delete, ctx, pvc)
+			pvc.Status.Phase = corev1.ClaimBound
+			pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: size}
+			Expect(k8sClient.Status().Update(ctx, pvc)).To(Succeed())
+		})
+		It("expands the existing PVCs in place", func() {
+			Eventually(func(g Gomega) {
+				glanceAPI := GetGlanceAPI(glanceTest.GlanceSingle)
+				glanceAPI.Spec.Storage.StorageRequest = "20G"
+				g.Expect(k8sClient.Update(ctx, glanceAPI)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				pvc := &corev1.PersistentVolumeClaim{}
+				g.Expect(k8sClient.Get(ctx, pvcName, pvc)).To(Succeed())
+				request := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+				g.Expect(request.String()).To(Equal("20G"))
+			}, timeout, interval).Should(Succeed())
+			th.ExpectCondition(
+				glanceTest.GlanceSingle,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				glancev1.StorageResizeReadyCondition,
+				corev1.ConditionFalse,
+			)
+			// A size change is not a backend change
+			Expect(GetGlanceAPI(glanceTest.GlanceSingle).Status.StatefulSetRevision).To(Equal(int32(0)))
+
+			// Simulate the volume expansion
+			Eventually(func(g Gomega) {
+				pvc := &corev1.PersistentVolumeClaim{}
+				g.Expect(k8sClient.Get(ctx, pvcName, pvc)).To(Succeed())
+				pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("20G")}
+				g.Expect(k8sClient.Status().Update(ctx, pvc)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			th.ExpectCondition(
+				glanceTest.GlanceSingle,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				glancev1.StorageResizeReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+		It("keeps the GlanceAPI Ready when the StorageClass does not allow the expansion", func() {
+			Eventually(func(g Gomega) {
+				storageClass := &storagev1.StorageClass{}
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "glance-expandable"}, storageClass)).To(Succeed())
+				storageClass.AllowVolumeExpansion = ptr.To(false)
+				g.Expect(k8sClient.Update(ctx, storageClass)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			keystone.CreateKeystoneEndpoint(glanceTest.GlanceSingle)
+			keystone.SimulateKeystoneEndpointReady(glanceTest.GlanceSingle)
+			th.SimulateStatefulSetReplicaReady(glanceTest.GlanceSingle)
+			th.ExpectCondition(
+				glanceTest.GlanceSingle,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+
+			Eventually(func(g Gomega) {
+				glanceAPI := GetGlanceAPI(glanceTest.GlanceSingle)
+				glanceAPI.Spec.Storage.StorageRequest = "20G"
+				g.Expect(k8sClient.Update(ctx, glanceAPI)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			th.ExpectConditionWithDetails(
+				glanceTest.GlanceSingle,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				glancev1.StorageResizeReadyCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				fmt.Sprintf(glancev1.StorageResizeNotSupportedMessage, "glance-expandable"),
+			)
+			th.ExpectCondition(
+				glanceTest.GlanceSingle,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			pvc := &corev1.PersistentVolumeClaim{}
+			Expect(k8sClient.Get(ctx, pvcName, pvc)).To(Succeed())
+			request := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			Expect(request.String()).To(Equal(glanceTest.GlancePVCSize))
+		})
+	})
+	When("a GlanceAPI with image cache is scaled down", func() {
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+			spec := CreateGlanceAPISpec(GlanceAPITypeSingle)
+			spec["imageCache"] = map[string]any{
+				"size": "1G",
+			}
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceSingle, spec))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+			th.GetStatefulSet(glanceTest.GlanceSingle)
+		})
+		It("retains the image data and deletes the cache PVCs", func() {
+			// Image data are retained, so the StatefulSet can't delete any PVC
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(glanceTest.GlanceSingle)
+				g.Expect(ss.Spec.PersistentVolumeClaimRetentionPolicy).To(Equal(
+					&appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+						WhenScaled:  appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+						WhenDeleted: appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+					}))
+			}, timeout, interval).Should(Succeed())
+
+			// Simulate the PVCs of a replica removed by the scale down
+			pvcs := map[string]map[string]string{
+				glance.ServiceName:            {},
+				glance.ServiceName + "-cache": {"image-cache": "true"},
+			}
+			for template, pvcAnnotations := range pvcs {
+				pvc := &corev1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      fmt.Sprintf("%s-%s-1", template, glanceTest.GlanceSingle.Name),
+						Namespace: glanceTest.GlanceSingle.Namespace,
+						Labels: map[string]string{
+							common.OwnerSelector:     glanceTest.GlanceSingle.Name,
+							common.ComponentSelector: glance.Component,
+						},
+						Annotations: pvcAnnotations,
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						Resources: corev1.VolumeResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1G")},
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+				DeferCleanup(k8sClient.Delete, ctx, pvc)
+			}
+			Eventually(func(g Gomega) {
+				glanceAPI := GetGlanceAPI(glanceTest.GlanceSingle)
+				glanceAPI.Spec.Replicas = ptr.To[int32](0)
+				g.Expect(k8sClient.Update(ctx, glanceAPI)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			// the cache PVC is gone (or waiting for the pvc-protection finalizer)
+			Eventually(func(g Gomega) {
+				pvc := &corev1.PersistentVolumeClaim{}
+				err := k8sClient.Get(ctx, types.NamespacedName{
+					Namespace: glanceTest.GlanceSingle.Namespace,
+					Name:      fmt.Sprintf("%s-cache-%s-1", glance.ServiceName, glanceTest.GlanceSingle.Name),
+				}, pvc)
+				g.Expect(k8s_errors.IsNotFound(err) || !pvc.DeletionTimestamp.IsZero()).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+			Consistently(func(g Gomega) {
+				pvc := &corev1.PersistentVolumeClaim{}
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{
+					Namespace: glanceTest.GlanceSingle.Namespace,
+					Name:      fmt.Sprintf("%s-%s-1", glance.ServiceName, glanceTest.GlanceSingle.Name),
+				}, pvc)).To(Succeed())
+				g.Expect(pvc.DeletionTimestamp.IsZero()).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("a GlanceAPI with an rbd backend requests an image conversion volume", func() {
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+			spec := CreateGlanceAPISpec(GlanceAPITypeInternal)
+			spec["customServiceConfig"] = GetDummyBackend()
+			spec["imageConversion"] = map[string]any{
+				"size":         "20G",
+				"storageClass": "fast",
+			}
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceInternal, spec))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+		})
+		It("mounts a per-replica PVC as conversion work dir", func() {
+			ss := th.GetStatefulSet(glanceTest.GlanceInternalStatefulSet)
+			var convPvc *corev1.PersistentVolumeClaim
+			for i := range ss.Spec.VolumeClaimTemplates {
+				if ss.Spec.VolumeClaimTemplates[i].Name == glance.ImageConversionVolume {
+					convPvc = &ss.Spec.VolumeClaimTemplates[i]
+				}
+			}
+			Expect(convPvc).ToNot(BeNil())
+			Expect(*convPvc.Spec.StorageClassName).To(Equal("fast"))
+			request := convPvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			Expect(request.String()).To(Equal("20G"))
+
+			container := ss.Spec.Template.Spec.Containers[1]
+			Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+				Name:      glance.ImageConversionVolume,
+				MountPath: glance.ImageConversionDir,
+			}))
+		})
+		It("uses a generic ephemeral volume when requested", func() {
+			Eventually(func(g Gomega) {
+				glanceAPI := GetGlanceAPI(glanceTest.GlanceInternal)
+				glanceAPI.Spec.ImageConversion.Ephemeral = true
+				glanceAPI.Spec.ImageConversion.StorageClass = ""
+				g.Expect(k8sClient.Update(ctx, glanceAPI)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			// Dropping the VolumeClaimTemplate requires a new StatefulSet revision
+			newStatefulSet := types.NamespacedName{
+				Namespace: glanceTest.GlanceInternalStatefulSet.Namespace,
+				Name:      glanceTest.GlanceInternalStatefulSet.Name + "-r1",
+			}
+			Eventually(func(g Gomega) {
+				ss := th.GetStatefulSet(newStatefulSet)
+				g.Expect(ss.Spec.VolumeClaimTemplates).To(HaveLen(1))
+				found := false
+				for _, v := range ss.Spec.Template.Spec.Volumes {
+					if v.Name == glance.ImageConversionVolume {
+						g.Expect(v.Ephemeral).ToNot(BeNil())
+						// no StorageClass: the default one of the cluster is used
+						g.Expect(v.Ephemeral.VolumeClaimTemplate.Spec.StorageClassName).To(BeNil())
+						found = true
+					}
+				}
+				g.Expect(found).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("a GlanceAPI requests an emptyDir image cache", func() {
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+			spec := CreateGlanceAPISpec(GlanceAPITypeSingle)
+			spec["imageCache"] = map[string]any{
+				"size":       "1G",
+				"volumeType": "emptyDir",
+			}
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceSingle, spec))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+		})
+		It("mounts a size limited emptyDir and cleans the cache in-pod", func() {
+			ss := th.GetStatefulSet(glanceTest.GlanceSingle)
+			for _, vct := range ss.Spec.VolumeClaimTemplates {
+				Expect(vct.Name).ToNot(Equal(glance.CacheVolume))
+			}
+			var cacheVolume *corev1.Volume
+			for i := range ss.Spec.Template.Spec.Volumes {
+				if ss.Spec.Template.Spec.Volumes[i].Name == glance.CacheVolume {
+					cacheVolume = &ss.Spec.Template.Spec.Volumes[i]
+				}
+			}
+			Expect(cacheVolume).ToNot(BeNil())
+			Expect(cacheVolume.EmptyDir).ToNot(BeNil())
+			// the sizeLimit leaves room for the cache to grow until the next prune
+			Expect(cacheVolume.EmptyDir.SizeLimit.String()).To(Equal("1250M"))
+
+			containerNames := []string{}
+			for _, c := range ss.Spec.Template.Spec.Containers {
+				containerNames = append(containerNames, c.Name)
+			}
+			Expect(containerNames).To(ContainElement(glance.ServiceName + "-cache-cleaner"))
+		})
+		It("does not create the cache CronJobs", func() {
+			for _, cj := range []glance.CronJobType{glance.CacheCleaner, glance.CachePruner} {
+				AssertCronJobDoesNotExist(types.NamespacedName{
+					Namespace: glanceTest.GlanceSingle.Namespace,
+					Name:      fmt.Sprintf("%s-0-%s", glanceTest.GlanceSingle.Name, cj),
+				})
+			}
+		})
+	})
+	When("a GlanceAPI enables the consistency check", func() {
+		var cronJobName types.NamespacedName
+
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+			spec := CreateGlanceAPISpec(GlanceAPITypeInternal)
+			spec["consistencyCheck"] = map[string]any{
+				"schedule": "0 3 * * *",
+			}
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceInternal, spec))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+			keystone.CreateKeystoneEndpoint(glanceTest.GlanceInternal)
+			keystone.SimulateKeystoneEndpointReady(glanceTest.GlanceInternal)
+			th.SimulateStatefulSetReplicaReady(glanceTest.GlanceInternalStatefulSet)
+
+			cronJobName = types.NamespacedName{
+				Namespace: glanceTest.GlanceInternal.Namespace,
+				Name:      fmt.Sprintf("%s-0-%s", glanceTest.GlanceInternalStatefulSet.Name, glance.ConsistencyCheck),
+			}
+		})
+		It("creates a CronJob mounting the glance PVC of each replica", func() {
+			Eventually(func(g Gomega) {
+				cron := GetCronJob(cronJobName)
+				g.Expect(cron.Spec.Schedule).To(Equal("0 3 * * *"))
+				podSpec := cron.Spec.JobTemplate.Spec.Template.Spec
+				g.Expect(podSpec.Volumes).To(ContainElement(HaveField(
+					"PersistentVolumeClaim.ClaimName",
+					fmt.Sprintf("%s-%s-0", glance.ServiceName, glanceTest.GlanceInternalStatefulSet.Name))))
+				g.Expect(podSpec.Containers[0].Env).To(ContainElement(
+					corev1.EnvVar{Name: "REPAIR", Value: "false"}))
+				g.Expect(podSpec.Affinity).ToNot(BeNil())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				cm := &corev1.ConfigMap{}
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{
+					Namespace: glanceTest.GlanceInternal.Namespace,
+					Name:      glanceTest.GlanceInternal.Name + "-consistency-report",
+				}, cm)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+		})
+		It("deletes the CronJobs when the check is disabled", func() {
+			GetCronJob(cronJobName)
+
+			Eventually(func(g Gomega) {
+				glanceAPI := GetGlanceAPI(glanceTest.GlanceInternal)
+				glanceAPI.Spec.ConsistencyCheck = nil
+				g.Expect(k8sClient.Update(ctx, glanceAPI)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			AssertCronJobDoesNotExist(cronJobName)
+		})
+	})
+	When("a GlanceAPI enables the storage monitoring", func() {
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+			spec := CreateGlanceAPISpec(GlanceAPITypeInternal)
+			spec["storage"] = map[string]any{
+				"storageRequest": glanceTest.GlancePVCSize,
+				"monitoring":     map[string]any{},
+			}
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceInternal, spec))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+			keystone.CreateKeystoneEndpoint(glanceTest.GlanceInternal)
+			keystone.SimulateKeystoneEndpointReady(glanceTest.GlanceInternal)
+			th.SimulateStatefulSetReplicaReady(glanceTest.GlanceInternalStatefulSet)
+		})
+		It("defaults the thresholds", func() {
+			monitoring := GetGlanceAPI(glanceTest.GlanceInternal).Spec.Storage.Monitoring
+			Expect(monitoring).ToNot(BeNil())
+			Expect(monitoring.WarningPercent).To(Equal(80))
+			Expect(monitoring.CriticalPercent).To(Equal(95))
+		})
+		It("reports the StorageCapacity condition without affecting Ready", func() {
+			th.ExpectCondition(
+				glanceTest.GlanceInternal,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			// no replica is running in envtest, hence no volume to measure
+			th.ExpectCondition(
+				glanceTest.GlanceInternal,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				glancev1.StorageCapacityCondition,
+				corev1.ConditionTrue,
+			)
+		})
+	})
+	When("the operator is upgraded on a running GlanceAPI", func() {
+		var stsUID types.UID
+		var hash string
+
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceInternal, CreateGlanceAPISpec(GlanceAPITypeInternal)))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+			keystone.CreateKeystoneEndpoint(glanceTest.GlanceInternal)
+			keystone.SimulateKeystoneEndpointReady(glanceTest.GlanceInternal)
+			th.SimulateStatefulSetReplicaReady(glanceTest.GlanceInternalStatefulSet)
+			th.ExpectCondition(
+				glanceTest.GlanceInternal,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			stsUID = th.GetStatefulSet(glanceTest.GlanceInternalStatefulSet).UID
+			hash = GetGlanceAPI(glanceTest.GlanceInternal).Status.Hash["backendHash"]
+		})
+		It("rewrites the backendHash of the previous release without replacing the StatefulSet", func() {
+			// the backendHash as computed by the previous release, where the
+			// whole Storage and the image cache size were hashed
+			glanceAPI := GetGlanceAPI(glanceTest.GlanceInternal)
+			backendHash, err := util.ObjectHash(glancev1.GetEnabledBackends(glanceAPI.Spec.CustomServiceConfig))
+			Expect(err).ToNot(HaveOccurred())
+			storageHash, err := util.ObjectHash(glanceAPI.Spec.Storage)
+			Expect(err).ToNot(HaveOccurred())
+			cacheHash, err := util.ObjectHash(glanceAPI.Spec.ImageCache.Size)
+			Expect(err).ToNot(HaveOccurred())
+			legacyHash, err := util.ObjectHash(backendHash + storageHash + cacheHash)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(legacyHash).ToNot(Equal(hash))
+
+			Eventually(func(g Gomega) {
+				glanceAPI := GetGlanceAPI(glanceTest.GlanceInternal)
+				glanceAPI.Status.Hash["backendHash"] = legacyHash
+				g.Expect(k8sClient.Status().Update(ctx, glanceAPI)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				g.Expect(GetGlanceAPI(glanceTest.GlanceInternal).Status.Hash["backendHash"]).To(Equal(hash))
+			}, timeout, interval).Should(Succeed())
+			Consistently(func(g Gomega) {
+				g.Expect(th.GetStatefulSet(glanceTest.GlanceInternalStatefulSet).UID).To(Equal(stsUID))
+				g.Expect(GetGlanceAPI(glanceTest.GlanceInternal).Status.StatefulSetRevision).To(Equal(int32(0)))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("a running GlanceAPI changes its storage policies", func() {
+		var stsUID types.UID
+
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceInternal, CreateGlanceAPISpec(GlanceAPITypeInternal)))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+			keystone.CreateKeystoneEndpoint(glanceTest.GlanceInternal)
+			keystone.SimulateKeystoneEndpointReady(glanceTest.GlanceInternal)
+			th.SimulateStatefulSetReplicaReady(glanceTest.GlanceInternalStatefulSet)
+			th.ExpectCondition(
+				glanceTest.GlanceInternal,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			stsUID = th.GetStatefulSet(glanceTest.GlanceInternalStatefulSet).UID
+		})
+		It("enables the monitoring without replacing the StatefulSet", func() {
+			Eventually(func(g Gomega) {
+				glanceAPI := GetGlanceAPI(glanceTest.GlanceInternal)
+				glanceAPI.Spec.Storage.Monitoring = &glancev1.StorageMonitoring{
+					WarningPercent:  70,
+					CriticalPercent: 90,
+				}
+				g.Expect(k8sClient.Update(ctx, glanceAPI)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			th.ExpectCondition(
+				glanceTest.GlanceInternal,
+				ConditionGetterFunc(GlanceAPIConditionGetter),
+				glancev1.StorageCapacityCondition,
+				corev1.ConditionTrue,
+			)
+			Consistently(func(g Gomega) {
+				g.Expect(th.GetStatefulSet(glanceTest.GlanceInternalStatefulSet).UID).To(Equal(stsUID))
+				g.Expect(GetGlanceAPI(glanceTest.GlanceInternal).Status.StatefulSetRevision).To(Equal(int32(0)))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("enables the autogrow without replacing the StatefulSet", func() {
+			hash := GetGlanceAPI(glanceTest.GlanceInternal).Status.Hash["backendHash"]
+			Eventually(func(g Gomega) {
+				glanceAPI := GetGlanceAPI(glanceTest.GlanceInternal)
+				glanceAPI.Spec.Storage.Autogrow = &glancev1.StorageAutogrow{
+					ThresholdPercent: 85,
+					Increment:        "5G",
+					MaximumSize:      "30G",
+				}
+				g.Expect(k8sClient.Update(ctx, glanceAPI)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			Consistently(func(g Gomega) {
+				glanceAPI := GetGlanceAPI(glanceTest.GlanceInternal)
+				g.Expect(glanceAPI.Status.Hash["backendHash"]).To(Equal(hash))
+				g.Expect(glanceAPI.Status.StatefulSetRevision).To(Equal(int32(0)))
+				g.Expect(th.GetStatefulSet(glanceTest.GlanceInternalStatefulSet).UID).To(Equal(stsUID))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("a GlanceAPI with an autogrow policy runs out of space", func() {
+		var pvcName types.NamespacedName
+		var podName types.NamespacedName
+
+		BeforeEach(func() {
+			storageClass := &storagev1.StorageClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "glance-autogrow",
+				},
+				Provisioner:          "kubernetes.io/no-provisioner",
+				AllowVolumeExpansion: ptr.To(true),
+			}
+			Expect(k8sClient.Create(ctx, storageClass)).To(Succeed())
+			DeferCleanup(k8sClient.Delete, ctx, storageClass)
+
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+			spec := CreateGlanceAPISpec(GlanceAPITypeInternal)
+			spec["storage"] = map[string]any{
+				"storageRequest": glanceTest.GlancePVCSize,
+				"storageClass":   storageClass.Name,
+				"autogrow": map[string]any{
+					"thresholdPercent": 80,
+					"increment":        "5G",
+					"maximumSize":      "30G",
+				},
+			}
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceInternal, spec))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+			keystone.CreateKeystoneEndpoint(glanceTest.GlanceInternal)
+			sts := th.GetStatefulSet(glanceTest.GlanceInternalStatefulSet)
+
+			// envtest has no StatefulSet controller nor kubelet: create the
+			// PVC and the Pod of the first replica, and report the usage of
+			// its image volume
+			podName = types.NamespacedName{
+				Namespace: sts.Namespace,
+				Name:      sts.Name + "-0",
+			}
+			pvcName = types.NamespacedName{
+				Namespace: sts.Namespace,
+				Name:      fmt.Sprintf("%s-%s", glance.ServiceName, podName.Name),
+			}
+			size := resource.MustParse(glanceTest.GlancePVCSize)
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      pvcName.Name,
+					Namespace: pvcName.Namespace,
+				},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					StorageClassName: &storageClass.Name,
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+			DeferCleanup(k8sClient.Delete, ctx, pvc)
+			pvc.Status.Phase = corev1.ClaimBound
+			pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: size}
+			Expect(k8sClient.Status().Update(ctx, pvc)).To(Succeed())
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      podName.Name,
+					Namespace: podName.Namespace,
+					Labels:    sts.Spec.Template.Labels,
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: "apps/v1",
+						Kind:       "StatefulSet",
+						Name:       sts.Name,
+						UID:        sts.UID,
+						Controller: ptr.To(true),
+					}},
+				},
+				Spec: corev1.PodSpec{
+					NodeName: "worker-0",
+					Containers: []corev1.Container{{
+						Name:  "glance-api",
+						Image: "glance-api",
+					}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			DeferCleanup(k8sClient.Delete, ctx, pod, client.GracePeriodSeconds(0))
+			nodeStats.SetVolumeUsage("worker-0", podName, glance.ServiceName, 10000000000, 9000000000)
+			DeferCleanup(nodeStats.Reset)
+		})
+		It("expands the PVC by the increment", func() {
+			keystone.SimulateKeystoneEndpointReady(glanceTest.GlanceInternal)
+			th.SimulateStatefulSetReplicaReady(glanceTest.GlanceInternalStatefulSet)
+
+			Eventually(func(g Gomega) {
+				pvc := &corev1.PersistentVolumeClaim{}
+				g.Expect(k8sClient.Get(ctx, pvcName, pvc)).To(Succeed())
+				request := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+				g.Expect(request.String()).To(Equal("15G"))
+			}, timeout, interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				status := GetGlanceAPI(glanceTest.GlanceInternal).Status.StorageAutogrow
+				g.Expect(status).To(HaveKey(pvcName.Name))
+				g.Expect(status[pvcName.Name].Size).To(Equal("15G"))
+				g.Expect(status[pvcName.Name].Expansions).To(Equal(1))
+				g.Expect(status[pvcName.Name].FilesystemCapacity.Value()).To(Equal(int64(10000000000)))
+			}, timeout, interval).Should(Succeed())
+		})
+		It("does not expand a PVC whose file system is being resized", func() {
+			Eventually(func(g Gomega) {
+				pvc := &corev1.PersistentVolumeClaim{}
+				g.Expect(k8sClient.Get(ctx, pvcName, pvc)).To(Succeed())
+				pvc.Status.Conditions = []corev1.PersistentVolumeClaimCondition{{
+					Type:   corev1.PersistentVolumeClaimFileSystemResizePending,
+					Status: corev1.ConditionTrue,
+				}}
+				g.Expect(k8sClient.Status().Update(ctx, pvc)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			keystone.SimulateKeystoneEndpointReady(glanceTest.GlanceInternal)
+			th.SimulateStatefulSetReplicaReady(glanceTest.GlanceInternalStatefulSet)
+
+			Eventually(func(g Gomega) {
+				g.Expect(GetGlanceAPI(glanceTest.GlanceInternal).Status.StorageCapacity).To(HaveKey(podName.Name))
+			}, timeout, interval).Should(Succeed())
+			Consistently(func(g Gomega) {
+				pvc := &corev1.PersistentVolumeClaim{}
+				g.Expect(k8sClient.Get(ctx, pvcName, pvc)).To(Succeed())
+				request := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+				g.Expect(request.String()).To(Equal(glanceTest.GlancePVCSize))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("a PVC named like the shared staging area is not owned by the GlanceAPI", func() {
+		var pvcName types.NamespacedName
+		BeforeEach(func() {
+			pvcName = types.NamespacedName{
+				Namespace: glanceTest.GlanceInternal.Namespace,
+				Name:      glanceTest.GlanceInternal.Name + "-" + glance.StagingVolume,
+			}
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      pvcName.Name,
+					Namespace: pvcName.Namespace,
+				},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceStorage: resource.MustParse("1G"),
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+			DeferCleanup(k8sClient.Delete, ctx, pvc)
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceInternal, CreateGlanceAPISpec(GlanceAPITypeInternal)))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+		})
+		It("does not delete the PVC", func() {
+			th.GetStatefulSet(glanceTest.GlanceInternalStatefulSet)
+			Consistently(func(g Gomega) {
+				pvc := &corev1.PersistentVolumeClaim{}
+				g.Expect(k8sClient.Get(ctx, pvcName, pvc)).To(Succeed())
+				g.Expect(pvc.DeletionTimestamp).To(BeNil())
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+	When("a GlanceAPI requests a shared staging area", func() {
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+			spec := CreateGlanceAPISpec(GlanceAPITypeInternal)
+			spec["staging"] = map[string]any{
+				"size":         "100G",
+				"storageClass": "nfs",
+				"shared":       true,
+			}
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceInternal, spec))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+		})
+		It("creates a ReadWriteMany PVC mounted by all the replicas", func() {
+			glanceAPI := GetGlanceAPI(glanceTest.GlanceInternal)
+			pvc := &corev1.PersistentVolumeClaim{}
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{
+					Namespace: glanceAPI.Namespace,
+					Name:      glanceapi.SharedStagingPVCName(glanceAPI),
+				}, pvc)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			Expect(pvc.Spec.AccessModes).To(ConsistOf(corev1.ReadWriteMany))
+			Expect(*pvc.Spec.StorageClassName).To(Equal("nfs"))
+
+			ss := th.GetStatefulSet(glanceTest.GlanceInternalStatefulSet)
+			for _, vct := range ss.Spec.VolumeClaimTemplates {
+				Expect(vct.Name).ToNot(Equal(glance.StagingVolume))
+			}
+			Expect(ss.Spec.Template.Spec.Volumes).To(ContainElement(
+				HaveField("PersistentVolumeClaim.ClaimName", pvc.Name)))
+			container := ss.Spec.Template.Spec.Containers[1]
+			Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+				Name:      glance.StagingVolume,
+				MountPath: glance.StagingDir,
+			}))
+			// worker_self_reference_url is not required anymore
+			Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "GLANCE_DOMAIN", Value: ""}))
+		})
+		It("points os_glance_staging_store to the staging volume", func() {
+			secretDataMap := th.GetSecret(glanceTest.GlanceInternalConfigMapData)
+			Expect(secretDataMap).ShouldNot(BeNil())
+			configData := string(secretDataMap.Data["00-config.conf"])
+			Expect(configData).Should(ContainSubstring(
+				fmt.Sprintf("[os_glance_staging_store]\nfilesystem_store_datadir = %s/", glance.StagingDir)))
+			// the tasks store stays per-replica
+			Expect(configData).Should(ContainSubstring(
+				fmt.Sprintf("[os_glance_tasks_store]\nfilesystem_store_datadir = %s/", glance.ImageConversionDir)))
+		})
+	})
+	When("a GlanceAPI with an rbd backend and External storage omits the image conversion volume", func() {
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					glanceName.Namespace,
+					GetGlance(glanceTest.Instance).Spec.DatabaseInstance,
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			mariadb.CreateMariaDBDatabase(glanceTest.GlanceDatabaseName.Namespace, glanceTest.GlanceDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(glanceTest.GlanceDatabaseName))
+			spec := CreateGlanceAPISpec(GlanceAPITypeInternal)
+			spec["customServiceConfig"] = GetDummyBackend()
+			spec["storage"] = map[string]any{
+				"storageRequest": glanceTest.GlancePVCSize,
+				"external":       true,
+			}
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceInternal, spec))
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(glanceTest.Instance.Namespace))
+		})
+		It("does not mount an image conversion work dir", func() {
+			ss := th.GetStatefulSet(glanceTest.GlanceInternalStatefulSet)
+			Expect(ss.Spec.VolumeClaimTemplates).To(BeEmpty())
+			for _, v := range ss.Spec.Template.Spec.Volumes {
+				Expect(v.Name).ToNot(Equal(glance.ImageConversionVolume))
+			}
+			for _, c := range ss.Spec.Template.Spec.Containers {
+				for _, m := range c.VolumeMounts {
+					Expect(m.Name).ToNot(Equal(glance.ImageConversionVolume))
+				}
+			}
+		})
+	})
+	When("GlanceAPI metrics are scraped", func() {
+		BeforeEach(func() {
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(glanceTest.GlanceMemcached)
+			DeferCleanup(th.DeleteInstance, CreateDefaultGlance(glanceTest.Instance))
+			DeferCleanup(th.DeleteInstance, CreateGlanceAPI(glanceTest.GlanceSingle, CreateGlanceAPISpec(GlanceAPITypeSingle)))
+		})
+		It("reports the desired and ready replicas", func() {
+			Eventually(func(g Gomega) {
+				families, err := metrics.Registry.Gather()
+				g.Expect(err).ShouldNot(HaveOccurred())
+				found := map[string]bool{}
+				for _, mf := range families {
+					for _, m := range mf.GetMetric() {
+						for _, l := range m.GetLabel() {
+							if l.GetName() == "glanceapi" && l.GetValue() == glanceTest.GlanceSingle.Name {
+								found[mf.GetName()] = true
+							}
+						}
+					}
+				}
+				g.Expect(found).To(HaveKey("glance_operator_glanceapi_replicas"))
+				g.Expect(found).To(HaveKey("glance_operator_glanceapi_ready_replicas"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
 	When("the StatefulSet has at least one Replica ready - External", func() {
 		BeforeEach(func() {
 			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(namespace, glanceTest.MemcachedInstance, memcachedSpec))
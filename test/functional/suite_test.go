@@ -87,6 +87,8 @@ var (
 	namespace  string
 	glanceName types.NamespacedName
 	glanceTest GlanceTestData
+	imageAPI   *FakeImageAPI
+	nodeStats  *FakeNodeStats
 	infra      *infra_test.TestHelper
 )
 
@@ -221,19 +223,60 @@ var _ = BeforeSuite(func() {
 	Expect(err).ToNot(HaveOccurred(), "failed to create kclient")
 
 	err = (&controller.GlanceReconciler{
-		Client:  k8sManager.GetClient(),
-		Scheme:  k8sManager.GetScheme(),
-		Kclient: kclient,
+		Client:   k8sManager.GetClient(),
+		Scheme:   k8sManager.GetScheme(),
+		Kclient:  kclient,
+		Recorder: k8sManager.GetEventRecorderFor("glance-controller"),
 	}).SetupWithManager(context.Background(), k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
+	err = (&controller.GlanceBackupReconciler{
+		Client:   k8sManager.GetClient(),
+		Scheme:   k8sManager.GetScheme(),
+		Kclient:  kclient,
+		Recorder: k8sManager.GetEventRecorderFor("glancebackup-controller"),
+	}).SetupWithManager(context.Background(), k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	err = (&controller.GlanceRestoreReconciler{
+		Client:   k8sManager.GetClient(),
+		Scheme:   k8sManager.GetScheme(),
+		Kclient:  kclient,
+		Recorder: k8sManager.GetEventRecorderFor("glancerestore-controller"),
+	}).SetupWithManager(context.Background(), k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	imageAPI = NewFakeImageAPI()
+	err = (&controller.GlanceImageMigrationReconciler{
+		Client:      k8sManager.GetClient(),
+		Scheme:      k8sManager.GetScheme(),
+		Kclient:     kclient,
+		Recorder:    k8sManager.GetEventRecorderFor("glanceimagemigration-controller"),
+		ImageClient: imageAPI.ImageClient,
+	}).SetupWithManager(context.Background(), k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	err = (&controller.GlanceImageReconciler{
+		Client:      k8sManager.GetClient(),
+		Scheme:      k8sManager.GetScheme(),
+		Kclient:     kclient,
+		Recorder:    k8sManager.GetEventRecorderFor("glanceimage-controller"),
+		ImageClient: imageAPI.ImageClient,
+	}).SetupWithManager(context.Background(), k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	nodeStats = NewFakeNodeStats()
 	err = (&controller.GlanceAPIReconciler{
-		Client:  k8sManager.GetClient(),
-		Scheme:  k8sManager.GetScheme(),
-		Kclient: kclient,
+		Client:    k8sManager.GetClient(),
+		Scheme:    k8sManager.GetScheme(),
+		Kclient:   kclient,
+		Recorder:  k8sManager.GetEventRecorderFor("glanceapi-controller"),
+		NodeStats: nodeStats.NodeStats,
 	}).SetupWithManager(context.Background(), k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
+	controller.RegisterMetrics(k8sManager.GetClient())
+
 	// Acquire environmental defaults and initialize operator defaults with them
 	glancev1.SetupDefaults()
 
@@ -264,6 +307,7 @@ var _ = BeforeSuite(func() {
 var _ = AfterSuite(func() {
 	By("tearing down the test environment")
 	cancel()
+	imageAPI.Close()
 	err := testEnv.Stop()
 	Expect(err).NotTo(HaveOccurred())
 })